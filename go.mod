@@ -3,15 +3,17 @@ module github.com/ibm-cloud/ibm-sap-hana-backint-cos
 go 1.24.1
 
 require (
+	github.com/IBM/go-sdk-core/v5 v5.21.2
 	github.com/IBM/ibm-cos-sdk-go v1.13.0
 	github.com/bigkevmcd/go-configparser v0.0.0-20251110123434-de62ed489b4f
+	github.com/klauspost/compress v1.18.0
+	github.com/pierrec/lz4/v4 v4.1.22
 	github.com/sirupsen/logrus v1.9.4
 	golang.org/x/net v0.49.0
 	golang.org/x/sys v0.40.0
 )
 
 require (
-	github.com/IBM/go-sdk-core/v5 v5.21.2 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.11 // indirect
 	github.com/go-openapi/errors v0.22.4 // indirect
 	github.com/go-openapi/strfmt v0.25.0 // indirect
@@ -62,7 +62,7 @@ func main() {
 	// The input file contains information of the objects to be
 	// backed up / restored.
 	// The format of the input file contents depends on the function to be executed.
-	global.InputFileContent = config.ReadInputFile(global.Args.InputFile)
+	global.InputFileContent = config.ReadInputFile(global.Args.InputFile, global.Args.Function)
 	if global.InputFileContent == nil {
 		fmt.Println("Error: the input file is empty or could not be read.")
 		os.Exit(global.WRONG_PARAMETER)
@@ -109,6 +109,17 @@ func main() {
 		os.Exit(global.FAILURE)
 	}
 
+	// Setting up the connection to the secondary storage
+	// Only a backup requiring both copies fails without secondary storage
+	if config.BackintConfig.IsSecondaryStorageEnabled() &&
+		!cos.InitializeSecondaryStorage() {
+		if global.Args.Function == global.BACKUP &&
+			config.BackintConfig.WriteQuorum() > 1 {
+			os.Exit(global.FAILURE)
+		}
+		global.Logger.Error("Continuing without secondary storage.")
+	}
+
 	// Executing the given function
 	success := true
 	switch global.Args.Function {
@@ -120,6 +131,8 @@ func main() {
 		success = backint.Inquire(s3Client)
 	case global.RESTORE:
 		success = backint.Restore(s3Client)
+	case global.VERIFY:
+		success = backint.Verify(s3Client)
 	}
 
 	// Dumping the backint result messages to the log file
@@ -17,6 +17,7 @@ package backint
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/cos"
 	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
@@ -34,25 +35,39 @@ func Backup(
 	s3Client *s3.S3,
 ) bool {
 	global.Logger.Debug("Function: backup")
-	sourcePaths := getSourcePathsForBackup()
-	if len(sourcePaths) == 0 {
+	sources, valid := getSourcesForBackup()
+	if len(sources) == 0 {
 		global.Logger.Info(
 			fmt.Sprintf("No source paths specified in %s", global.Args.InputFile),
 		)
-		return true
+		return valid
 	}
 
+	// Choosing the part size of the pipes from their expected size
+	setExpectedPipeSizes(s3Client, sources)
+
+	// Naming the manifest before the upload, the objects refer to it
+	created := time.Now().UTC()
+	cos.ManifestKey = ""
+	if isManifestWritten() {
+		cos.ManifestKey = generateManifestKeyname(global.Args.BackupId, created)
+	}
+
+	// Bounding the transfers of all pipes
+	cos.InitializeTransferScheduler(len(sources))
+	cos.InitializeBandwidthLimiters()
+
 	// Initializing asynchronous processing
 	var wgUpload sync.WaitGroup
-	chanUpload := make(chan cos.Result, len(sourcePaths))
+	chanUpload := make(chan cos.Result, len(sources))
 
 	// Running all uploads asynchronously
-	for x, sourcePath := range sourcePaths {
+	for x, source := range sources {
 		wgUpload.Add(1)
 		global.Logger.Info(fmt.Sprintf(
-			"Storing '%s' in process #%d.", sourcePath, x,
+			"Storing '%s' in process #%d.", source.Path, x,
 		))
-		go runUpload(s3Session, s3Client, &wgUpload, sourcePath, chanUpload)
+		go runUpload(s3Session, s3Client, &wgUpload, source, chanUpload)
 	}
 
 	// Waiting for all processes to finish
@@ -62,7 +77,14 @@ func Backup(
 	global.Logger.Debug("All processes done.")
 
 	// Checking the results
-	return backupResultHandler(chanUpload)
+	success, results := backupResultHandler(chanUpload)
+	success = success && valid
+
+	// Writing the manifest of the backup
+	if success && cos.ManifestKey != "" {
+		writeBackupManifest(s3Client, cos.ManifestKey, created, results)
+	}
+	return success
 }
 
 /*
@@ -72,21 +94,28 @@ func runUpload(
 	s3Session *session.Session,
 	s3Client *s3.S3,
 	wg *sync.WaitGroup,
-	pipe string,
+	source BackupSource,
 	chanUpload chan cos.Result,
 ) {
-	key := generateCosObjectKeyname(pipe)
+	key := generateCosObjectKeyname(source.Path)
 	defer wg.Done()
-	storeResult := cos.Upload(s3Session, s3Client, pipe, key)
+	var storeResult cos.Result
+	if source.IsFile {
+		storeResult = cos.UploadFile(s3Session, s3Client, source.Path, key)
+	} else {
+		storeResult = cos.Upload(s3Session, s3Client, source.Path, key, source.ExpectedSize)
+	}
 	chanUpload <- storeResult
 }
 
 /*
 Handling the results of uploading one object to COS
 */
-func backupResultHandler(chanUpload chan cos.Result) bool {
+func backupResultHandler(chanUpload chan cos.Result) (bool, []cos.Result) {
 	success := true
+	var results []cos.Result
 	for result := range chanUpload {
+		results = append(results, result)
 		if result.Err == nil {
 			logging.BackintResultMsgs.AddBackupSuccessMessage(
 				result.ETag,
@@ -106,5 +135,5 @@ func backupResultHandler(chanUpload chan cos.Result) bool {
 			success = false
 		}
 	}
-	return success
+	return success, results
 }
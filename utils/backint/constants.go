@@ -19,3 +19,9 @@ const MAX_NUMBER_OF_TAGS int = 10
 
 // Values for comparison with parameter file settings
 const OBJECTLOCKMODE string = "COMPLIANCE"
+
+// Backup level passed by SAP HANA for log backups
+const LOG_BACKUP_LEVEL string = "LOG"
+
+// Layout of the creation time naming the manifest of a backup without id
+const MANIFEST_TIME_LAYOUT string = "20060102T150405.000000000Z"
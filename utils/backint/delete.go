@@ -17,6 +17,7 @@ package backint
 import (
 	"fmt"
 
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
 	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/cos"
 	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
 	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/logging"
@@ -30,26 +31,46 @@ Deleting the cloud objects specified in the input file
 func DeleteCloudObjects(
 	s3Client *s3.S3,
 ) bool {
-	success := true
 	global.Logger.Debug("Function: delete")
 
-	cosObjects := getCosObjectsForDelete(s3Client)
+	cosObjects, success := getCosObjectsForDelete(s3Client)
+
+	// The objects name the manifest of their backup
+	var manifestKeys map[string]string
+	if config.BackintConfig.IsManifestEnabled() {
+		manifestKeys = getManifestKeys(s3Client, cosObjects)
+	}
 	deleteResults := cos.DeleteMultiple(s3Client, cosObjects)
 
+	var deletedManifests []string
 	for _, r := range deleteResults {
-		logging.BackintResultMsgs.AddKeyword(
-			r.Status,
-			[]string{r.ETag, r.Key},
-		)
 		if r.Status == "ERROR" {
 			global.Logger.Error(
-				fmt.Sprintf("Failed to delete object '%s' with ETag '%s'.",
+				fmt.Sprintf("Failed to delete object '%s' with ETag '%s'. Error: %s",
 					r.Key,
 					r.ETag,
+					r.Err,
 				),
 			)
+			logging.BackintResultMsgs.AddDeleteErrorMessage(
+				r.ETag,
+				r.Destination,
+			)
 			success = false
+			continue
 		}
+		logging.BackintResultMsgs.AddKeyword(
+			r.Status,
+			[]string{r.ETag, r.Destination},
+		)
+		if Key, found := manifestKeys[r.Key+"\x00"+r.VersionId]; found && r.Status == "DELETED" {
+			deletedManifests = append(deletedManifests, Key)
+		}
+	}
+
+	// The backups of the deleted objects are incomplete now
+	if len(deletedManifests) > 0 {
+		deleteBackupManifests(s3Client, deletedManifests)
 	}
 	return success
 }
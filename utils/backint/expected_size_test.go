@@ -0,0 +1,221 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package backint
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/credentials"
+	"github.com/IBM/ibm-cos-sdk-go/aws/session"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+)
+
+// One manifest stored in the stub bucket
+type previousManifest struct {
+	key          string
+	lastModified time.Time
+	manifest     BackupManifest
+}
+
+// Stub of a bucket holding the manifests of previous backups
+type previousManifestBucket struct {
+	mu        sync.Mutex
+	manifests []previousManifest
+	listed    []string
+	read      []string
+}
+
+// Listing returned by the stub bucket
+type previousManifestListing struct {
+	XMLName  xml.Name `xml:"ListBucketResult"`
+	Contents []struct {
+		Key          string
+		LastModified string
+		Size         int
+	}
+	IsTruncated bool
+}
+
+func (b *previousManifestBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+
+	// Path style requests: /<bucket> lists, /<bucket>/<key> reads
+	_, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if key == "" {
+		prefix := r.URL.Query().Get("prefix")
+		b.listed = append(b.listed, prefix)
+		var listing previousManifestListing
+		for _, m := range b.manifests {
+			if !strings.HasPrefix(m.key, prefix) {
+				continue
+			}
+			listing.Contents = append(listing.Contents, struct {
+				Key          string
+				LastModified string
+				Size         int
+			}{m.key, m.lastModified.Format(time.RFC3339), 1})
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		_ = xml.NewEncoder(w).Encode(listing)
+		return
+	}
+
+	b.read = append(b.read, key)
+	for _, m := range b.manifests {
+		if m.key == key {
+			_ = json.NewEncoder(w).Encode(m.manifest)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+/*
+Setting up the manifest configuration and a stub bucket for one test
+*/
+func setupPreviousManifests(t *testing.T, level string, expectedPipeSize int64, manifests []previousManifest) (*previousManifestBucket, *s3.S3) {
+	t.Helper()
+	if global.Logger == nil {
+		global.Logger = logrus.New()
+		global.Logger.SetOutput(io.Discard)
+	}
+	previousConfig := config.BackintConfig
+	previousArgs := global.Args
+	config.BackintConfig = config.BackintConfigT{
+		"bucket":              "backups",
+		"manifest_key_prefix": "manifests/",
+		"expected_pipe_size":  strconv.FormatInt(expectedPipeSize, 10),
+	}
+	global.Args.BackupLevel = level
+	t.Cleanup(func() {
+		config.BackintConfig = previousConfig
+		global.Args = previousArgs
+	})
+
+	bucket := &previousManifestBucket{manifests: manifests}
+	server := httptest.NewServer(bucket)
+	t.Cleanup(server.Close)
+	return bucket, s3.New(session.Must(session.NewSession(aws.NewConfig().
+		WithEndpoint(server.URL).
+		WithRegion("us-east-1").
+		WithCredentials(credentials.NewStaticCredentials("key-id", "secret", "")).
+		WithS3ForcePathStyle(true).
+		WithMaxRetries(0),
+	)))
+}
+
+func TestSetExpectedPipeSizes(t *testing.T) {
+	const (
+		pipe0 = "/usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_0_1"
+		pipe1 = "/usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_1_1"
+		pipe2 = "/usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_2_1"
+		file  = "/usr/sap/HDB/HDB00/backup/log/catalog_backup"
+	)
+	created := time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC)
+	manifests := []previousManifest{
+		{
+			key:          "manifests/COMPLETE_DATA_BACKUP/1_20260301T220000Z.json",
+			lastModified: created,
+			manifest: BackupManifest{BackupId: 1, Objects: []ManifestObject{
+				{SourcePath: pipe0, SourceSize: 100},
+				{SourcePath: pipe1, SourceSize: 500},
+			}},
+		},
+		{
+			key:          "manifests/COMPLETE_DATA_BACKUP/2_20260302T220000Z.json",
+			lastModified: created.Add(24 * time.Hour),
+			manifest: BackupManifest{BackupId: 2, Objects: []ManifestObject{
+				{SourcePath: pipe0, SourceSize: 300},
+				{SourcePath: pipe1, SourceSize: 200},
+			}},
+		},
+		{
+			key:          "manifests/COMPLETE_DATA_BACKUP/2_20260302T220000Z.txt",
+			lastModified: created.Add(48 * time.Hour),
+		},
+	}
+
+	tests := []struct {
+		name             string
+		level            string
+		expectedPipeSize int64
+		want             []int64
+		wantRead         []string
+	}{
+		{
+			name:     "sizes of the latest manifest",
+			level:    "COMPLETE_DATA_BACKUP",
+			want:     []int64{300, 200, 300, 0},
+			wantRead: []string{manifests[1].key},
+		},
+		{
+			name:             "expected_pipe_size larger than the previous size",
+			level:            "COMPLETE_DATA_BACKUP",
+			expectedPipeSize: 250,
+			want:             []int64{300, 250, 300, 0},
+			wantRead:         []string{manifests[1].key},
+		},
+		{
+			name:             "no manifest of the level",
+			level:            "DIFFERENTIAL_DATA_BACKUP",
+			expectedPipeSize: 50,
+			want:             []int64{50, 50, 50, 0},
+		},
+		{
+			name:  "log backup",
+			level: LOG_BACKUP_LEVEL,
+			want:  []int64{0, 0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket, s3Client := setupPreviousManifests(t, tt.level, tt.expectedPipeSize, manifests)
+			sources := []BackupSource{{Path: pipe0}, {Path: pipe1}, {Path: pipe2}, {Path: file, IsFile: true}}
+			setExpectedPipeSizes(s3Client, sources)
+
+			for i, source := range sources {
+				if source.ExpectedSize != tt.want[i] {
+					t.Errorf("expected size of '%s' = %d, want %d", source.Path, source.ExpectedSize, tt.want[i])
+				}
+			}
+			if strings.Join(bucket.read, ",") != strings.Join(tt.wantRead, ",") {
+				t.Errorf("manifests read %v, want %v", bucket.read, tt.wantRead)
+			}
+			if tt.level == LOG_BACKUP_LEVEL && len(bucket.listed) > 0 {
+				t.Errorf("manifests listed for a log backup: %v", bucket.listed)
+			}
+		})
+	}
+}
@@ -33,6 +33,7 @@ func Inquire(
 	s3Client *s3.S3,
 ) bool {
 	global.Logger.Debug("Function: inquire")
+	success := true
 
 	for _, i := range global.InputFileContent {
 		var splitted []string
@@ -42,22 +43,31 @@ func Inquire(
 		switch i.Keyword {
 		case "NULL":
 			Key := i.Parameter
-			cosObjectList := cos.ListObjectsOfBucket(s3Client)
+			prefix, matches := cosObjectKeyMatcher(Key)
+			cosObjectList, err := cos.ListObjectsWithPrefix(s3Client, prefix)
+			if err != nil {
+				global.Logger.Error(fmt.Sprintf(
+					"Failure during inquiry. Could not list objects with prefix '%s'. Error: %s",
+					prefix,
+					err,
+				))
+				return false
+			}
 			sort.Slice(cosObjectList, func(i, j int) bool {
 				return *cosObjectList[i].Key < *cosObjectList[j].Key
 			})
 			found := false
 			for _, element := range cosObjectList {
+				if !matches(*element.Key) {
+					continue
+				}
+				found = true
 				if Key != "" {
-					if *element.Key == Key {
-						found = true
-						logging.BackintResultMsgs.AddKeyword(
-							"BACKUP",
-							[]string{*element.ETag, *element.Key},
-						)
-					}
+					logging.BackintResultMsgs.AddKeyword(
+						"BACKUP",
+						[]string{*element.ETag, Key},
+					)
 				} else {
-					found = true
 					logging.BackintResultMsgs.AddKeyword(
 						"BACKUP",
 						[]string{*element.ETag},
@@ -84,7 +94,26 @@ func Inquire(
 			if len(splitted) == 2 {
 				ETag := splitted[0]
 				Key := splitted[1]
-				if cos.BackupExists(s3Client, ETag) {
+				cosKey, versionId, err := resolveCosObjectKeyForPipe(s3Client, Key, ETag)
+				// The version is already found if the Key was searched
+				exists := versionId != ""
+				if err == nil && !exists {
+					exists, err = cos.BackupExists(s3Client, cosKey, ETag)
+				}
+				if err != nil {
+					// The other entries are still inquired
+					global.Logger.Error(fmt.Sprintf(
+						"Failure during inquiry. Could not search '%s' with ETag '%s'. Error: %s",
+						Key,
+						ETag,
+						err,
+					))
+					logging.BackintResultMsgs.AddKeyword(
+						"ERROR",
+						[]string{ETag, Key},
+					)
+					success = false
+				} else if exists {
 					logging.BackintResultMsgs.AddKeyword(
 						"BACKUP",
 						[]string{ETag, Key},
@@ -106,5 +135,5 @@ func Inquire(
 			return false
 		}
 	}
-	return true
+	return success
 }
@@ -0,0 +1,203 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package backint
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/cos"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/logging"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/credentials"
+	"github.com/IBM/ibm-cos-sdk-go/aws/session"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	inquiredPipe   = "backint/DB_TEN/databackup_1"
+	inquiredPipe10 = "backint/DB_TEN/databackup_10"
+	failingPipe    = "backint/DB_TEN/databackup_2"
+)
+
+// One object version in the stub bucket
+type inquiredVersion struct {
+	Key       string
+	VersionId string
+	IsLatest  bool
+	ETag      string
+}
+
+// Stub of a bucket listing object versions, failing for some prefixes
+type inquiredBucket struct {
+	mu           sync.Mutex
+	versions     []inquiredVersion
+	failPrefixes []string
+	requests     []string
+}
+
+func (b *inquiredBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.requests = append(b.requests, r.Method+" "+r.URL.RawQuery)
+	prefix := r.URL.Query().Get("prefix")
+	if r.Method != http.MethodGet || !r.URL.Query().Has("versions") || slices.Contains(b.failPrefixes, prefix) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	listing := struct {
+		XMLName     xml.Name          `xml:"ListVersionsResult"`
+		IsTruncated bool              `xml:"IsTruncated"`
+		Versions    []inquiredVersion `xml:"Version"`
+	}{}
+	for _, version := range b.versions {
+		if strings.HasPrefix(version.Key, prefix) {
+			listing.Versions = append(listing.Versions, version)
+		}
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(listing)
+}
+
+/*
+Setting up the input file, the result messages and a stub bucket for one test
+*/
+func setupInquiry(t *testing.T, bucket *inquiredBucket, input ...global.InputFileContentT) *s3.S3 {
+	t.Helper()
+	if global.Logger == nil {
+		global.Logger = logrus.New()
+		global.Logger.SetOutput(io.Discard)
+	}
+	previousConfig := config.BackintConfig
+	previousInput := global.InputFileContent
+	previousMessages := logging.BackintResultMsgs
+	config.BackintConfig = config.BackintConfigT{"bucket": "backups"}
+	global.InputFileContent = input
+	logging.BackintResultMsgs = nil
+	t.Cleanup(func() {
+		config.BackintConfig = previousConfig
+		global.InputFileContent = previousInput
+		logging.BackintResultMsgs = previousMessages
+	})
+
+	server := httptest.NewServer(bucket)
+	t.Cleanup(server.Close)
+	return s3.New(session.Must(session.NewSession(aws.NewConfig().
+		WithEndpoint(server.URL).
+		WithRegion("us-east-1").
+		WithCredentials(credentials.NewStaticCredentials("key-id", "secret", "")).
+		WithS3ForcePathStyle(true).
+		WithMaxRetries(0),
+	)))
+}
+
+/*
+Creating a stub bucket with a version of a pipe and a pipe sharing its prefix
+*/
+func newInquiredBucket() *inquiredBucket {
+	return &inquiredBucket{
+		versions: []inquiredVersion{
+			{Key: inquiredPipe, VersionId: "v1", IsLatest: true, ETag: "\"etag-1\""},
+			{Key: inquiredPipe10, VersionId: "v10", IsLatest: true, ETag: "\"etag-10\""},
+		},
+		failPrefixes: []string{failingPipe},
+	}
+}
+
+func TestBackupExists(t *testing.T) {
+	bucket := newInquiredBucket()
+	s3Client := setupInquiry(t, bucket)
+
+	tests := []struct {
+		name    string
+		key     string
+		eTag    string
+		want    bool
+		wantErr bool
+	}{
+		{"version of the key", inquiredPipe, "etag-1", true, false},
+		{"version of a key sharing the prefix", inquiredPipe, "etag-10", false, false},
+		{"unknown ETag", inquiredPipe10, "etag-1", false, false},
+		{"no key", "", "etag-1", false, false},
+		{"listing failing", failingPipe, "etag-2", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cos.BackupExists(s3Client, tt.key, tt.eTag)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("BackupExists(%q, %q) = %v, %v, want %v, error %v", tt.key, tt.eTag, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInquireReportsSearchErrors(t *testing.T) {
+	s3Client := setupInquiry(t, newInquiredBucket(),
+		global.InputFileContentT{Keyword: "EBID", Parameter: "etag-1 " + inquiredPipe},
+		global.InputFileContentT{Keyword: "EBID", Parameter: "etag-2 " + failingPipe},
+		global.InputFileContentT{Keyword: "EBID", Parameter: "etag-10 " + inquiredPipe},
+		global.InputFileContentT{Keyword: "EBID", Parameter: "etag-10 " + inquiredPipe10},
+	)
+
+	if Inquire(s3Client) {
+		t.Error("Inquire() = true, want false if an entry could not be searched")
+	}
+	want := []string{
+		`#BACKUP "etag-1" "` + inquiredPipe + `" `,
+		`#ERROR "etag-2" "` + failingPipe + `" `,
+		`#NOTFOUND "etag-10" "` + inquiredPipe + `" `,
+		`#BACKUP "etag-10" "` + inquiredPipe10 + `" `,
+	}
+	if !slices.Equal(logging.BackintResultMsgs, want) {
+		t.Errorf("messages = %q, want %q", logging.BackintResultMsgs, want)
+	}
+}
+
+func TestDeleteReportsSearchErrors(t *testing.T) {
+	bucket := newInquiredBucket()
+	bucket.versions = bucket.versions[1:]
+	s3Client := setupInquiry(t, bucket,
+		global.InputFileContentT{Keyword: "EBID", Parameter: "etag-2 " + failingPipe},
+		global.InputFileContentT{Keyword: "EBID", Parameter: "etag-1 " + inquiredPipe},
+	)
+
+	if DeleteCloudObjects(s3Client) {
+		t.Error("DeleteCloudObjects() = true, want false if an entry could not be searched")
+	}
+	if len(logging.BackintResultMsgs) != 2 ||
+		!strings.HasPrefix(logging.BackintResultMsgs[0], `#ERROR "etag-2" "`+failingPipe+`"`) ||
+		logging.BackintResultMsgs[1] != `#NOTFOUND "etag-1" "`+inquiredPipe+`" ` {
+		t.Errorf("messages = %q, want an error for '%s' and %s not found", logging.BackintResultMsgs, failingPipe, inquiredPipe)
+	}
+
+	// Nothing is deleted without a version
+	for _, request := range bucket.requests {
+		if !strings.HasPrefix(request, http.MethodGet) {
+			t.Errorf("request %q, want only listings", request)
+		}
+	}
+}
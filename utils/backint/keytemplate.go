@@ -0,0 +1,270 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package backint
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/cos"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+)
+
+// Time used for the {DATE} placeholder, the same for all pipes of one run
+var keyTemplateTime = sync.OnceValue(time.Now)
+
+// Object versions listed for the key template during one run, by key prefix
+var keyTemplateVersions = make(map[string][]*s3.ObjectVersion)
+
+/*
+Generating the object Key name from the key template
+*/
+func expandKeyTemplate(pipeName string) string {
+	// The template is validated while reading the parameter file
+	elements, _ := config.ParseKeyTemplate(config.BackintConfig.KeyTemplate())
+
+	var Key strings.Builder
+	for _, element := range elements {
+		if element.Placeholder == "" {
+			Key.WriteString(element.Literal)
+			continue
+		}
+		Key.WriteString(getPlaceholderValue(element, pipeName))
+	}
+	return Key.String()
+}
+
+/*
+Getting the value of one placeholder of the key template
+*/
+func getPlaceholderValue(element config.KeyTemplateElement, pipeName string) string {
+	switch element.Placeholder {
+	case config.PLACEHOLDER_SID:
+		return config.SidFromPipeName(pipeName)
+	case config.PLACEHOLDER_TENANT:
+		return config.TenantFromPipeName(pipeName)
+	case config.PLACEHOLDER_LEVEL:
+		return global.Args.BackupLevel
+	case config.PLACEHOLDER_BACKUP_ID:
+		return strconv.Itoa(global.Args.BackupId)
+	case config.PLACEHOLDER_USER:
+		return global.Args.UserId
+	case config.PLACEHOLDER_DATE:
+		return keyTemplateTime().Format(element.Argument)
+	case config.PLACEHOLDER_HOST:
+		hostname, _ := os.Hostname()
+		return hostname
+	case config.PLACEHOLDER_PIPE_BASENAME:
+		return config.PipeBasename(pipeName)
+	}
+	return ""
+}
+
+/*
+Generating the pattern matching all object keys created from the
+key template for a given pipe name.
+Only the placeholders derived from the pipe name are known on
+restore and inquire, all other placeholders match any value.
+Returns the key prefix up to the first unknown placeholder as well.
+*/
+func keyTemplatePattern(pipeName string) (string, *regexp.Regexp) {
+	elements, _ := config.ParseKeyTemplate(config.BackintConfig.KeyTemplate())
+
+	var prefix strings.Builder
+	prefixComplete := false
+	pattern := "^"
+	for _, element := range elements {
+		value := element.Literal
+		switch element.Placeholder {
+		case "":
+		case config.PLACEHOLDER_SID,
+			config.PLACEHOLDER_TENANT,
+			config.PLACEHOLDER_PIPE_BASENAME:
+			value = getPlaceholderValue(element, pipeName)
+		default:
+			pattern += getPlaceholderPattern(element)
+			prefixComplete = true
+			continue
+		}
+
+		pattern += regexp.QuoteMeta(value)
+		if !prefixComplete {
+			prefix.WriteString(value)
+		}
+	}
+	pattern += "$"
+	return prefix.String(), regexp.MustCompile(pattern)
+}
+
+/*
+Getting the pattern matching any value of a placeholder
+which is not known on restore
+*/
+func getPlaceholderPattern(element config.KeyTemplateElement) string {
+	switch element.Placeholder {
+	case config.PLACEHOLDER_BACKUP_ID:
+		// Without backup id the placeholder expands to -1
+		return `-?\d*`
+	case config.PLACEHOLDER_DATE:
+		return getDatePattern(element.Argument)
+	}
+	return `[^/]*`
+}
+
+/*
+Generating the pattern matching dates formatted with a given layout.
+Runs of digits and letters of a formatted date match any number
+of digits and letters, all other characters must match exactly.
+*/
+func getDatePattern(layout string) string {
+	pattern := ""
+	lastClass := ""
+	for _, r := range keyTemplateTime().Format(layout) {
+		class := regexp.QuoteMeta(string(r))
+		if unicode.IsDigit(r) {
+			class = `\d+`
+		} else if unicode.IsLetter(r) {
+			class = `\pL+`
+		}
+		if class == lastClass && (class == `\d+` || class == `\pL+`) {
+			continue
+		}
+		pattern += class
+		lastClass = class
+	}
+	return pattern
+}
+
+/*
+Resolving the object Key for a given pipe name from the objects in the bucket.
+If an ETag is given, the object with this ETag is searched,
+otherwise the latest backup for the pipe name.
+Returns the Key and the version id of the object found,
+or empty strings if no object is found,
+and the error if the versions could not be listed.
+*/
+func resolveCosObjectKey(s3Client *s3.S3, pipeName string, ETag string) (string, string, error) {
+	prefix, pattern := keyTemplatePattern(pipeName)
+	global.Logger.Debug(fmt.Sprintf(
+		"Searching object for '%s' with prefix '%s' and pattern '%s'.",
+		pipeName,
+		prefix,
+		pattern,
+	))
+
+	versions, err := listKeyTemplateVersions(s3Client, prefix)
+	if err != nil {
+		return "", "", err
+	}
+
+	var latest *s3.ObjectVersion
+	for _, version := range versions {
+		if !pattern.MatchString(aws.StringValue(version.Key)) {
+			continue
+		}
+		if ETag != "" {
+			if strings.ReplaceAll(aws.StringValue(version.ETag), "\"", "") == ETag {
+				latest = version
+				break
+			}
+			continue
+		}
+		if aws.BoolValue(version.IsLatest) &&
+			(latest == nil || aws.TimeValue(version.LastModified).After(aws.TimeValue(latest.LastModified))) {
+			latest = version
+		}
+	}
+
+	if latest == nil {
+		global.Logger.Info(fmt.Sprintf("No object found for '%s'.", pipeName))
+		return "", "", nil
+	}
+
+	Key := aws.StringValue(latest.Key)
+	global.Logger.Info("'" + Key + "' -> '" + pipeName + "'.")
+	return Key, aws.StringValue(latest.VersionId), nil
+}
+
+/*
+Getting the object versions with a given key prefix.
+The versions are listed once per run, a prefix starting with
+a prefix listed before is served from that listing,
+as the keys are matched against the pattern of the pipe anyway.
+A listing which failed is not kept.
+*/
+func listKeyTemplateVersions(s3Client *s3.S3, prefix string) ([]*s3.ObjectVersion, error) {
+	for listed, versions := range keyTemplateVersions {
+		if strings.HasPrefix(prefix, listed) {
+			return versions, nil
+		}
+	}
+	versions, err := cos.ListObjectVersions(s3Client, prefix)
+	if err != nil {
+		return nil, err
+	}
+	keyTemplateVersions[prefix] = versions
+	return versions, nil
+}
+
+/*
+Getting the function checking if an object Key belongs to a given pipe name
+and the key prefix all matching objects start with.
+Without pipe name, all objects below the prefix of all backups match.
+*/
+func cosObjectKeyMatcher(pipeName string) (string, func(string) bool) {
+	matchAll := func(string) bool { return true }
+
+	if !config.BackintConfig.IsKeyTemplateEnabled() {
+		if pipeName == "" {
+			return config.BackintConfig.AdditionalKeyPrefix(), matchAll
+		}
+		Key := generateCosObjectKeyname(pipeName)
+		return Key, func(k string) bool {
+			return k == Key
+		}
+	}
+
+	if pipeName == "" {
+		return keyTemplateLiteralPrefix(), matchAll
+	}
+	prefix, pattern := keyTemplatePattern(pipeName)
+	return prefix, pattern.MatchString
+}
+
+/*
+Getting the literal text of the key template before the first placeholder
+*/
+func keyTemplateLiteralPrefix() string {
+	elements, _ := config.ParseKeyTemplate(config.BackintConfig.KeyTemplate())
+
+	var prefix strings.Builder
+	for _, element := range elements {
+		if element.Placeholder != "" {
+			break
+		}
+		prefix.WriteString(element.Literal)
+	}
+	return prefix.String()
+}
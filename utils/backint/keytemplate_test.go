@@ -0,0 +1,255 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package backint
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+)
+
+const (
+	tenantPipe   = "/usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_0_1"
+	systemDbPipe = "/usr/sap/HDB/SYS/global/hdb/backint/SYSTEMDB/databackup_0_1"
+)
+
+/*
+Setting the key template and the backup context for one test
+*/
+func setupKeyTemplate(t *testing.T, template string, backupId int, level string) {
+	t.Helper()
+	previousConfig := config.BackintConfig
+	previousArgs := global.Args
+	config.BackintConfig = config.BackintConfigT{"key_template": template}
+	global.Args.BackupId = backupId
+	global.Args.BackupLevel = level
+	global.Args.UserId = "SYSTEM"
+	t.Cleanup(func() {
+		config.BackintConfig = previousConfig
+		global.Args = previousArgs
+	})
+}
+
+func TestExpandKeyTemplate(t *testing.T) {
+	hostname, _ := os.Hostname()
+	date := keyTemplateTime().Format("2006/01/02")
+
+	tests := []struct {
+		name     string
+		template string
+		pipe     string
+		backupId int
+		want     string
+	}{
+		{
+			name:     "tenant database",
+			template: "{SID}/{TENANT}/{LEVEL}/{BACKUP_ID}/{PIPE_BASENAME}",
+			pipe:     tenantPipe,
+			backupId: 1700000000123,
+			want:     "HDB/TEN/full/1700000000123/databackup_0_1",
+		},
+		{
+			name:     "system database",
+			template: "{SID}/{TENANT}/{PIPE_BASENAME}",
+			pipe:     systemDbPipe,
+			backupId: 1,
+			want:     "HDB/SYSTEMDB/databackup_0_1",
+		},
+		{
+			name:     "without backup id",
+			template: "{TENANT}/{BACKUP_ID}/{PIPE_BASENAME}",
+			pipe:     tenantPipe,
+			backupId: -1,
+			want:     "TEN/-1/databackup_0_1",
+		},
+		{
+			name:     "date, host and user",
+			template: "backups/{DATE:2006/01/02}/{HOST}/{USER}/{TENANT}/{PIPE_BASENAME}",
+			pipe:     tenantPipe,
+			backupId: 1,
+			want:     "backups/" + date + "/" + hostname + "/SYSTEM/TEN/databackup_0_1",
+		},
+		{
+			name:     "pipe outside of the HANA directories",
+			template: "{SID}/{TENANT}/{PIPE_BASENAME}",
+			pipe:     "/tmp/pipe",
+			backupId: 1,
+			want:     "//pipe",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupKeyTemplate(t, tt.template, tt.backupId, "full")
+			if got := expandKeyTemplate(tt.pipe); got != tt.want {
+				t.Errorf("expandKeyTemplate(%q) = %q, want %q", tt.pipe, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyTemplatePattern(t *testing.T) {
+	tests := []struct {
+		name       string
+		template   string
+		pipe       string
+		wantPrefix string
+		matches    []string
+		mismatches []string
+	}{
+		{
+			name:       "known placeholders only",
+			template:   "{SID}/{TENANT}/{PIPE_BASENAME}",
+			pipe:       tenantPipe,
+			wantPrefix: "HDB/TEN/databackup_0_1",
+			matches:    []string{"HDB/TEN/databackup_0_1"},
+			mismatches: []string{
+				"HDB/TEN/databackup_0_10",
+				"HDB/SYSTEMDB/databackup_0_1",
+				"XYZ/TEN/databackup_0_1",
+				"prefix/HDB/TEN/databackup_0_1",
+			},
+		},
+		{
+			name:       "backup id",
+			template:   "{SID}/{TENANT}/{BACKUP_ID}/{PIPE_BASENAME}",
+			pipe:       tenantPipe,
+			wantPrefix: "HDB/TEN/",
+			matches: []string{
+				"HDB/TEN/1700000000123/databackup_0_1",
+				"HDB/TEN/-1/databackup_0_1",
+			},
+			mismatches: []string{
+				"HDB/TEN/abc/databackup_0_1",
+				"HDB/TEN/1/2/databackup_0_1",
+				"HDB/TEN/1/databackup_0_2",
+			},
+		},
+		{
+			name:       "level, user and host",
+			template:   "{SID}/{LEVEL}/{USER}/{HOST}/{TENANT}/{PIPE_BASENAME}",
+			pipe:       tenantPipe,
+			wantPrefix: "HDB/",
+			matches: []string{
+				"HDB/full/SYSTEM/host1/TEN/databackup_0_1",
+				"HDB/incremental/BACKUP_USER/host-2.example.com/TEN/databackup_0_1",
+			},
+			mismatches: []string{
+				"HDB/full/SYS/TEM/host1/TEN/databackup_0_1",
+				"HDB/full/SYSTEM/host1/OTHER/databackup_0_1",
+			},
+		},
+		{
+			name:       "date",
+			template:   "{TENANT}/{DATE:2006-01-02}/{PIPE_BASENAME}",
+			pipe:       tenantPipe,
+			wantPrefix: "TEN/",
+			matches: []string{
+				"TEN/2025-12-31/databackup_0_1",
+				"TEN/1999-01-01/databackup_0_1",
+			},
+			mismatches: []string{
+				"TEN/2025/12/31/databackup_0_1",
+				"TEN/latest/databackup_0_1",
+			},
+		},
+		{
+			name:       "month name",
+			template:   "{TENANT}/{DATE:Jan 2006}/{PIPE_BASENAME}",
+			pipe:       tenantPipe,
+			wantPrefix: "TEN/",
+			matches: []string{
+				"TEN/Dec 2025/databackup_0_1",
+				"TEN/May 2026/databackup_0_1",
+			},
+			mismatches: []string{
+				"TEN/12 2025/databackup_0_1",
+			},
+		},
+		{
+			name:       "literal with regular expression characters",
+			template:   "hana.backup+/{TENANT}/{PIPE_BASENAME}",
+			pipe:       tenantPipe,
+			wantPrefix: "hana.backup+/TEN/databackup_0_1",
+			matches:    []string{"hana.backup+/TEN/databackup_0_1"},
+			mismatches: []string{"hanaXbackup+/TEN/databackup_0_1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupKeyTemplate(t, tt.template, 1, "full")
+			prefix, pattern := keyTemplatePattern(tt.pipe)
+			if prefix != tt.wantPrefix {
+				t.Errorf("prefix = %q, want %q", prefix, tt.wantPrefix)
+			}
+			for _, Key := range tt.matches {
+				if !pattern.MatchString(Key) {
+					t.Errorf("pattern %q does not match %q", pattern, Key)
+				}
+			}
+			for _, Key := range tt.mismatches {
+				if pattern.MatchString(Key) {
+					t.Errorf("pattern %q matches %q", pattern, Key)
+				}
+			}
+		})
+	}
+}
+
+func TestKeyTemplateRoundTrip(t *testing.T) {
+	templates := []string{
+		"{SID}/{TENANT}/{LEVEL}/{BACKUP_ID}/{PIPE_BASENAME}",
+		"{DATE:2006/01/02T15:04:05}/{HOST}/{USER}/{TENANT}/{PIPE_BASENAME}",
+		"{SID}-{TENANT}-{DATE:Mon Jan _2 2006}-{PIPE_BASENAME}",
+	}
+	for _, template := range templates {
+		for _, backupId := range []int{1700000000123, -1} {
+			for _, pipe := range []string{tenantPipe, systemDbPipe} {
+				setupKeyTemplate(t, template, backupId, "log")
+				Key := expandKeyTemplate(pipe)
+				prefix, pattern := keyTemplatePattern(pipe)
+				if !pattern.MatchString(Key) {
+					t.Errorf("pattern %q does not match the expanded key %q", pattern, Key)
+				}
+				if len(Key) < len(prefix) || Key[:len(prefix)] != prefix {
+					t.Errorf("expanded key %q does not start with prefix %q", Key, prefix)
+				}
+			}
+		}
+	}
+}
+
+func TestCosObjectKeyMatcher(t *testing.T) {
+	setupKeyTemplate(t, "backups/{SID}/{TENANT}/{PIPE_BASENAME}", 1, "full")
+
+	prefix, match := cosObjectKeyMatcher("")
+	if prefix != "backups/" {
+		t.Errorf("prefix without pipe = %q, want %q", prefix, "backups/")
+	}
+	if !match("backups/anything") {
+		t.Error("matcher without pipe does not match all keys")
+	}
+
+	prefix, match = cosObjectKeyMatcher(tenantPipe)
+	if prefix != "backups/HDB/TEN/databackup_0_1" {
+		t.Errorf("prefix for pipe = %q, want %q", prefix, "backups/HDB/TEN/databackup_0_1")
+	}
+	if !match("backups/HDB/TEN/databackup_0_1") || match("backups/HDB/TEN/databackup_0_11") {
+		t.Error("matcher for pipe does not match exactly the key of the pipe")
+	}
+}
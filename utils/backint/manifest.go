@@ -0,0 +1,277 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package backint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/cos"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/version"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+)
+
+/*
+Returns true if a manifest is written for the current backup.
+Log backups are small, but very frequent, no manifest is written for them.
+*/
+func isManifestWritten() bool {
+	return config.BackintConfig.IsManifestEnabled() &&
+		global.Args.BackupLevel != LOG_BACKUP_LEVEL
+}
+
+/*
+Writing the manifest of a successful backup to IBM Cloud Object Storage.
+The manifest lists all objects belonging to the backup
+together with the context given by HANA.
+The backup data is complete even if writing the manifest fails.
+*/
+func writeBackupManifest(s3Client *s3.S3, Key string, created time.Time, results []cos.Result) {
+	manifest := generateBackupManifest(created, results)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err == nil {
+		global.Logger.Info(fmt.Sprintf("Writing backup manifest '%s'.", Key))
+		err = cos.UploadData(s3Client, Key, data)
+	}
+	if err != nil {
+		global.Logger.Warn(fmt.Sprintf(
+			"The backup is saved without the manifest '%s'. Error: %s", Key, err,
+		))
+	}
+}
+
+/*
+Generating the manifest from the results of the uploads
+*/
+func generateBackupManifest(created time.Time, results []cos.Result) BackupManifest {
+	hostname, _ := os.Hostname()
+
+	manifest := BackupManifest{
+		BackupId:        global.Args.BackupId,
+		BackupLevel:     global.Args.BackupLevel,
+		UserId:          global.Args.UserId,
+		NumberOfObjects: global.Args.NumberOfObjects,
+		Hostname:        hostname,
+		ToolVersion:     version.TOOL_VERSION,
+		Created:         created,
+	}
+
+	for _, result := range results {
+		manifest.Objects = append(manifest.Objects, ManifestObject{
+			Key:        result.Key,
+			ETag:       result.ETag,
+			VersionId:  result.VersionId,
+			SourcePath: result.SourcePath,
+			SourceSize: result.SourceSize,
+			TargetSize: result.TargetSize,
+			Duration:   result.Duration,
+			Checksum:   result.Checksum,
+		})
+	}
+	return manifest
+}
+
+/*
+Generating the object Key name of the manifest:
+<manifest_key_prefix><backup level>/<backup id>.json
+Without backup id, the creation time is used instead,
+so that the manifests of different backups are not overwritten.
+The name is known before the upload, so that every object
+of the backup refers to its manifest.
+*/
+func generateManifestKeyname(backupId int, created time.Time) string {
+	name := strconv.Itoa(backupId)
+	if backupId < 0 {
+		name = created.Format(MANIFEST_TIME_LAYOUT)
+	}
+	return fmt.Sprintf("%s%s.json",
+		getManifestKeyPrefixForLevel(),
+		name,
+	)
+}
+
+/*
+Getting the key prefix of all manifests of the current backup level
+*/
+func getManifestKeyPrefixForLevel() string {
+	return fmt.Sprintf("%s%s/",
+		config.BackintConfig.ManifestKeyPrefix(),
+		global.Args.BackupLevel,
+	)
+}
+
+/*
+Reading the manifest of the latest backup with the current backup level
+*/
+func readLatestBackupManifest(s3Client *s3.S3) (*BackupManifest, error) {
+	prefix := getManifestKeyPrefixForLevel()
+	objects, err := cos.ListObjectsWithPrefix(s3Client, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *s3.Object
+	for _, object := range objects {
+		if !strings.HasSuffix(aws.StringValue(object.Key), ".json") {
+			continue
+		}
+		if latest == nil ||
+			aws.TimeValue(object.LastModified).After(aws.TimeValue(latest.LastModified)) {
+			latest = object
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no manifest found with prefix '%s'", prefix)
+	}
+
+	data, err := cos.DownloadData(s3Client, aws.StringValue(latest.Key))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest BackupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	global.Logger.Info(fmt.Sprintf(
+		"Read manifest '%s' of backup %d.",
+		aws.StringValue(latest.Key),
+		manifest.BackupId,
+	))
+	return &manifest, nil
+}
+
+/*
+Setting the expected size of the pipes.
+The size is taken from expected_pipe_size or from the manifest of the
+previous backup with the same level, whichever is larger.
+If a pipe is not found in the manifest, the largest pipe is used,
+as the pipe names may contain a backup specific prefix.
+*/
+func setExpectedPipeSizes(s3Client *s3.S3, sources []BackupSource) {
+	previousSizes := make(map[string]int64)
+	largest := int64(0)
+
+	// Log backups are small, but very frequent
+	if config.BackintConfig.IsManifestEnabled() &&
+		global.Args.BackupLevel != "" &&
+		global.Args.BackupLevel != LOG_BACKUP_LEVEL {
+		manifest, err := readLatestBackupManifest(s3Client)
+		if err != nil {
+			global.Logger.Info(fmt.Sprintf(
+				"No previous manifest used for the part size: %s", err,
+			))
+		} else {
+			for _, object := range manifest.Objects {
+				previousSizes[object.SourcePath] = object.SourceSize
+				largest = max(largest, object.SourceSize)
+			}
+		}
+	}
+
+	for i := range sources {
+		if sources[i].IsFile {
+			continue
+		}
+		previousSize, found := previousSizes[sources[i].Path]
+		if !found {
+			previousSize = largest
+		}
+		sources[i].ExpectedSize = max(
+			config.BackintConfig.ExpectedPipeSize(),
+			previousSize,
+		)
+	}
+}
+
+/*
+Getting the manifest keys stored with the objects to be deleted.
+The keys are read before the objects are deleted,
+objects without manifest are skipped.
+*/
+func getManifestKeys(s3Client *s3.S3, cosObjects []cos.CosObject) map[string]string {
+	manifestKeys := make(map[string]string)
+	for _, element := range cosObjects {
+		if !element.Found {
+			continue
+		}
+		Key, err := cos.GetManifestKey(s3Client, element.Key, element.VersionId)
+		if err != nil {
+			global.Logger.Warn(fmt.Sprintf(
+				"Error reading the manifest of '%s'. Error: %s", element.Key, err,
+			))
+			continue
+		}
+		// Only manifests written by hdbbackint are deleted
+		if Key != "" && strings.HasPrefix(Key, config.BackintConfig.ManifestKeyPrefix()) {
+			manifestKeys[element.Key+"\x00"+element.VersionId] = Key
+		}
+	}
+	return manifestKeys
+}
+
+/*
+Deleting the manifests of the backups whose objects are deleted.
+A backup cannot be restored anymore as soon as one of its objects
+is deleted, so its manifest is deleted as well.
+The manifest is named in the metadata of the objects,
+only the versions of this key are listed.
+*/
+func deleteBackupManifests(s3Client *s3.S3, manifestKeys []string) {
+	slices.Sort(manifestKeys)
+	var manifests []cos.CosObject
+	for _, Key := range slices.Compact(manifestKeys) {
+		versions, err := cos.ListObjectVersions(s3Client, Key)
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf(
+				"Error listing the versions of the backup manifest '%s'. Error: %s",
+				Key,
+				err,
+			))
+			continue
+		}
+		for _, version := range versions {
+			if aws.StringValue(version.Key) == Key {
+				manifests = append(manifests, cos.CosObject{
+					Key:       Key,
+					VersionId: aws.StringValue(version.VersionId),
+					Found:     true,
+				})
+			}
+		}
+	}
+
+	for _, result := range cos.DeleteMultiple(s3Client, manifests) {
+		if result.Status == "ERROR" {
+			global.Logger.Error(fmt.Sprintf(
+				"Error deleting the backup manifest '%s'. Error: %s",
+				result.Key,
+				result.Err,
+			))
+			continue
+		}
+		global.Logger.Info(fmt.Sprintf("Backup manifest '%s' deleted.", result.Key))
+	}
+}
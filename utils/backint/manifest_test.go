@@ -0,0 +1,235 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package backint
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/cos"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/credentials"
+	"github.com/IBM/ibm-cos-sdk-go/aws/session"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+)
+
+// Stub of a bucket holding backup objects and their manifests
+type manifestBucket struct {
+	mu sync.Mutex
+	// Manifest key in the metadata of every object version
+	manifestKeys map[string]string
+	// Versions of all objects
+	versions map[string][]string
+	listed   []string
+	deleted  []string
+}
+
+type manifestDeleteRequest struct {
+	Objects []struct {
+		Key       string
+		VersionId string
+	} `xml:"Object"`
+}
+
+func (b *manifestBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	Key := strings.TrimPrefix(r.URL.Path, "/backups/")
+	query := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodHead:
+		Key, found := b.manifestKeys[Key+"@"+query.Get("versionId")]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if Key != "" {
+			w.Header().Set("X-Amz-Meta-Backint-Manifest-Key", Key)
+		}
+	case r.Method == http.MethodGet && query.Has("versions"):
+		prefix := query.Get("prefix")
+		b.listed = append(b.listed, prefix)
+		fmt.Fprint(w, `<ListVersionsResult><IsTruncated>false</IsTruncated>`)
+		for Key, versionIds := range b.versions {
+			if !strings.HasPrefix(Key, prefix) {
+				continue
+			}
+			for _, versionId := range versionIds {
+				fmt.Fprintf(w, `<Version><Key>%s</Key><VersionId>%s</VersionId></Version>`, Key, versionId)
+			}
+		}
+		fmt.Fprint(w, `</ListVersionsResult>`)
+	case r.Method == http.MethodPost && query.Has("delete"):
+		var request manifestDeleteRequest
+		if err := xml.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, object := range request.Objects {
+			b.deleted = append(b.deleted, object.Key+"@"+object.VersionId)
+		}
+		fmt.Fprint(w, `<DeleteResult></DeleteResult>`)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+/*
+Setting up the manifest configuration and the backup context for one test
+*/
+func setupManifest(t *testing.T, level string, backupId int) {
+	t.Helper()
+	if global.Logger == nil {
+		global.Logger = logrus.New()
+		global.Logger.SetOutput(io.Discard)
+	}
+	previousConfig := config.BackintConfig
+	previousArgs := global.Args
+	config.BackintConfig = config.BackintConfigT{
+		"bucket":              "backups",
+		"manifest_key_prefix": "manifests/",
+	}
+	global.Args.BackupLevel = level
+	global.Args.BackupId = backupId
+	t.Cleanup(func() {
+		config.BackintConfig = previousConfig
+		global.Args = previousArgs
+	})
+}
+
+/*
+Creating a client of the stub bucket
+*/
+func newManifestBucketClient(t *testing.T, bucket *manifestBucket) *s3.S3 {
+	t.Helper()
+	server := httptest.NewServer(bucket)
+	t.Cleanup(server.Close)
+	return s3.New(session.Must(session.NewSession(aws.NewConfig().
+		WithEndpoint(server.URL).
+		WithRegion("us-east-1").
+		WithCredentials(credentials.NewStaticCredentials("key-id", "secret", "")).
+		WithS3ForcePathStyle(true).
+		WithMaxRetries(0),
+	)))
+}
+
+func TestGenerateManifestKeyname(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		backupId int
+		want     string
+	}{
+		{"backup id", 1234, "manifests/COMPLETE_DATA_BACKUP/1234.json"},
+		{"without backup id", -1, "manifests/COMPLETE_DATA_BACKUP/20260301T123000.000000000Z.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupManifest(t, "COMPLETE_DATA_BACKUP", tt.backupId)
+			if got := generateManifestKeyname(tt.backupId, created); got != tt.want {
+				t.Errorf("generateManifestKeyname(%d) = %q, want %q", tt.backupId, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsManifestWritten(t *testing.T) {
+	tests := []struct {
+		name   string
+		level  string
+		prefix string
+		want   bool
+	}{
+		{"data backup", "COMPLETE_DATA_BACKUP", "manifests/", true},
+		{"log backup", LOG_BACKUP_LEVEL, "manifests/", false},
+		{"manifests disabled", "COMPLETE_DATA_BACKUP", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupManifest(t, tt.level, 1)
+			config.BackintConfig["manifest_key_prefix"] = tt.prefix
+			if got := isManifestWritten(); got != tt.want {
+				t.Errorf("isManifestWritten() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteBackupManifests(t *testing.T) {
+	setupManifest(t, "COMPLETE_DATA_BACKUP", 1)
+	const manifest = "manifests/COMPLETE_DATA_BACKUP/1.json"
+	bucket := &manifestBucket{
+		manifestKeys: map[string]string{
+			"data/part_0@v1":  manifest,
+			"data/part_1@v1":  manifest,
+			"log/segment@v1":  "",
+			"data/foreign@v1": "other/1.json",
+		},
+		versions: map[string][]string{
+			manifest:                                {"m1", "m2"},
+			manifest + ".bak":                       {"b1"},
+			"manifests/COMPLETE_DATA_BACKUP/2.json": {"n1"},
+			"other/1.json":                          {"o1"},
+		},
+	}
+	s3Client := newManifestBucketClient(t, bucket)
+
+	objects := []cos.CosObject{
+		{Key: "data/part_0", VersionId: "v1", Found: true},
+		{Key: "data/part_1", VersionId: "v1", Found: true},
+		{Key: "log/segment", VersionId: "v1", Found: true},
+		{Key: "data/foreign", VersionId: "v1", Found: true},
+		{Key: "data/missing", Found: false},
+	}
+	manifestKeys := getManifestKeys(s3Client, objects)
+	want := map[string]string{
+		"data/part_0\x00v1": manifest,
+		"data/part_1\x00v1": manifest,
+	}
+	if len(manifestKeys) != len(want) {
+		t.Fatalf("getManifestKeys() = %v, want %v", manifestKeys, want)
+	}
+	for id, Key := range want {
+		if manifestKeys[id] != Key {
+			t.Errorf("manifest of %q = %q, want %q", id, manifestKeys[id], Key)
+		}
+	}
+
+	deleteBackupManifests(s3Client, []string{manifest, manifest})
+
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+	// Only the manifest itself is listed, not the whole prefix
+	if !slices.Equal(bucket.listed, []string{manifest}) {
+		t.Errorf("listed prefixes %v, want only %q", bucket.listed, manifest)
+	}
+	slices.Sort(bucket.deleted)
+	if wantDeleted := []string{manifest + "@m1", manifest + "@m2"}; !slices.Equal(bucket.deleted, wantDeleted) {
+		t.Errorf("deleted %v, want %v", bucket.deleted, wantDeleted)
+	}
+}
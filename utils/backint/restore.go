@@ -32,31 +32,81 @@ func Restore(
 	s3Client *s3.S3,
 ) bool {
 	global.Logger.Debug("Function: restore")
-	cosObjects := getCosObjectsForRestore()
+	chanDownload, valid := downloadObjects(s3Client, false)
 
-	if cosObjects == nil {
+	// Checking the results of the single object downloads and return
+	return restoreResultHandler(chanDownload) && valid
+}
+
+/*
+Downloading the objects of the input file asynchronously.
+If verify is set, the data is discarded instead of written to the pipes.
+Returns the channel holding the results of all downloads.
+*/
+func downloadObjects(
+	s3Client *s3.S3,
+	verify bool,
+) (chan cos.Result, bool) {
+	cosObjects, valid := getCosObjectsForRestore(s3Client)
+
+	if !valid {
 		global.Logger.Error("Wrong keyword(s) in input file.")
-		return false
+	}
+	if len(cosObjects) == 0 {
+		chanDownload := make(chan cos.Result)
+		close(chanDownload)
+		return chanDownload, valid
 	}
 
+	// Bounding the transfers of all pipes
+	cos.InitializeTransferScheduler(len(cosObjects))
+	cos.InitializeBandwidthLimiters()
+
 	// Initializing asynchronous processing
 	var wgDownload sync.WaitGroup
 	chanDownload := make(chan cos.Result, len(cosObjects))
 
 	// Running all downloads asynchronously
 	for n, element := range cosObjects {
-		if element.ETag == "" {
-			etag := cos.GetETagOfLatestVersionForKey(s3Client, element.Key)
-			if etag == "" {
-				chanDownload <- setObjectNotFoundResult(element)
-				continue
+		// The object could not be searched
+		if element.Err != nil {
+			chanDownload <- cos.Result{
+				Err:        element.Err,
+				SourcePath: element.Destination,
+				Key:        element.Key,
+			}
+			continue
+		}
+		if element.Key == "" {
+			chanDownload <- setObjectNotFoundResult(element)
+			continue
+		}
+		// Pinning the version, the key may have been overwritten
+		// by a later backup. The version is taken from the secondary
+		// storage if the primary storage fails.
+		if err := cos.ResolveRestoreVersion(s3Client, &element); err != nil {
+			global.Logger.Error(fmt.Sprintf(
+				"Error resolving the version of '%s'. Error: %s",
+				element.Key,
+				err,
+			))
+			chanDownload <- cos.Result{
+				Err:        err,
+				SourcePath: element.Destination,
+				Key:        element.Key,
 			}
-			element.ETag = etag
-			wgDownload.Add(1)
+			continue
 		}
+		if element.VersionId == "" {
+			chanDownload <- setObjectNotFoundResult(element)
+			continue
+		}
+		element.Verify = verify
+		wgDownload.Add(1)
+
 		logMessage := fmt.Sprintf(
-			"Restoring backup '%s' with '%s' in process #%d",
-			element.Key, element.ETag, n,
+			"Restoring backup '%s' with '%s' (version '%s') in process #%d",
+			element.Key, element.ETag, element.VersionId, n,
 		)
 		global.Logger.Info(logMessage)
 
@@ -66,9 +116,7 @@ func Restore(
 	close(chanDownload)
 
 	global.Logger.Info("Restore: All processes finished.")
-
-	// Checking the results of the single object downloads and return
-	return restoreResultHandler(chanDownload)
+	return chanDownload, valid
 }
 
 /*
@@ -15,11 +15,13 @@
 package backint
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
 	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/cos"
 	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/logging"
 
 	"github.com/IBM/ibm-cos-sdk-go/service/s3"
 )
@@ -28,8 +30,13 @@ import (
 Generating the object Key name
 */
 func generateCosObjectKeyname(pipeName string) string {
-	Key, _ := strings.CutPrefix(pipeName, config.BackintConfig.RemoveKeyPrefix())
-	Key = config.BackintConfig.AdditionalKeyPrefix() + Key
+	var Key string
+	if config.BackintConfig.IsKeyTemplateEnabled() {
+		Key = expandKeyTemplate(pipeName)
+	} else {
+		Key, _ = strings.CutPrefix(pipeName, config.BackintConfig.RemoveKeyPrefix())
+		Key = config.BackintConfig.AdditionalKeyPrefix() + Key
+	}
 
 	if global.Args.Function == global.BACKUP {
 		global.Logger.Info("'" + pipeName + "' -> '" + Key + "'.")
@@ -40,58 +47,113 @@ func generateCosObjectKeyname(pipeName string) string {
 }
 
 /*
-Getting the source paths from the input file for function = BACKUP
+Getting the object Key for a given pipe name on restore, inquire and delete.
+Keys generated from the key template contain values
+which are only known at backup time and must be searched.
+The version id is returned as well if it is known from the search,
+otherwise it is empty.
+Returns the error if the objects could not be searched.
 */
-func getSourcePathsForBackup() []string {
-	var sourcePaths []string
+func resolveCosObjectKeyForPipe(s3Client *s3.S3, pipeName string, ETag string) (string, string, error) {
+	if config.BackintConfig.IsKeyTemplateEnabled() {
+		return resolveCosObjectKey(s3Client, pipeName, ETag)
+	}
+	return generateCosObjectKeyname(pipeName), "", nil
+}
+
+/*
+Getting the pipes and files from the input file for function = BACKUP
+Returns false if the input file contains unknown keywords
+*/
+func getSourcesForBackup() ([]BackupSource, bool) {
+	var sources []BackupSource
+	valid := true
 	for _, element := range global.InputFileContent {
-		if element.Keyword != "PIPE" {
-			continue
+		switch element.Keyword {
+		case "PIPE":
+			sources = append(sources, BackupSource{Path: element.Parameter})
+		case "FILE":
+			sources = append(sources, BackupSource{
+				Path:   element.Parameter,
+				IsFile: true,
+			})
+		default:
+			addUnknownKeywordError(element)
+			valid = false
 		}
-		sourcePaths = append(sourcePaths, element.Parameter)
 	}
-	return sourcePaths
+	return sources, valid
+}
+
+/*
+Reporting an input file entry with an unknown keyword as error
+*/
+func addUnknownKeywordError(element global.InputFileContentT) {
+	err := fmt.Errorf("unknown keyword '#%s'", element.Keyword)
+	global.Logger.Error(fmt.Sprintf(
+		"Wrong keyword '%s' specified in input file for '%s'.",
+		element.Keyword,
+		element.Parameter,
+	))
+	logging.BackintResultMsgs.AddErrorMessage(element.Parameter, err)
 }
 
 /*
 Getting the list of object names and the ETags for function = DELETE
+Returns false if the input file contains unknown keywords
 */
 func getCosObjectsForDelete(
 	s3Client *s3.S3,
-) []cos.CosObject {
+) ([]cos.CosObject, bool) {
 	var cosObjects []cos.CosObject
+	valid := true
 
 	for _, element := range global.InputFileContent {
 		if element.Keyword != "EBID" {
+			addUnknownKeywordError(element)
+			valid = false
 			continue
 		}
 
 		// Checking if object exists with specified EBID
 		splitted := strings.Split(element.Parameter, " ")
 		ETag := splitted[0]
-		Key := splitted[1]
+		sourcePath := splitted[1]
+		Key, versionId, err := resolveCosObjectKeyForPipe(s3Client, sourcePath, ETag)
+
 		cos_object := cos.CosObject{
-			ETag:  ETag,
-			Key:   Key,
-			Found: false,
+			ETag:        ETag,
+			Key:         Key,
+			Destination: sourcePath,
+			Found:       false,
 		}
-
-		for _, cos_element := range cos.ListObjectsOfBucket(s3Client) {
-			if cos_element.ETag == &ETag && cos_element.Key == &Key {
-				cos_object.Found = true
-				break
-			}
+		// The version is only known if the Key was searched
+		if err == nil && Key != "" && versionId == "" {
+			_, versionId, err = cos.GetObjectVersionForKey(s3Client, Key, ETag)
 		}
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf(
+				"Error searching the version of '%s' with ETag '%s'. Error: %s",
+				sourcePath,
+				ETag,
+				err,
+			))
+			cos_object.Err = err
+		}
+		cos_object.VersionId = versionId
+		cos_object.Found = versionId != ""
 		cosObjects = append(cosObjects, cos_object)
 	}
-	return cosObjects
+	return cosObjects, valid
 }
 
 /*
 Getting the list of object names and the ETags for function = RESTORE
+Returns false if the input file contains unknown keywords
 */
-func getCosObjectsForRestore() []cos.CosObject {
+func getCosObjectsForRestore(s3Client *s3.S3) ([]cos.CosObject, bool) {
 	var cosObjects []cos.CosObject
+	valid := true
 	for _, element := range global.InputFileContent {
 		splitted := strings.Split(element.Parameter, " ")
 
@@ -118,10 +180,12 @@ func getCosObjectsForRestore() []cos.CosObject {
 			}
 
 		default:
-			return nil
+			addUnknownKeywordError(element)
+			valid = false
+			continue
 		}
 
-		Key = generateCosObjectKeyname(sourcePath)
+		Key, _, err := resolveCosObjectKeyForPipe(s3Client, sourcePath, etag)
 
 		nextIndex := int64(1)
 
@@ -130,10 +194,11 @@ func getCosObjectsForRestore() []cos.CosObject {
 			Key:         Key,
 			Destination: destination,
 			Found:       false,
+			Err:         err,
 			NextIndex:   &nextIndex,
 		}
 
 		cosObjects = append(cosObjects, cosObject)
 	}
-	return cosObjects
+	return cosObjects, valid
 }
@@ -0,0 +1,48 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package backint
+
+import "time"
+
+// Datatype representing the manifest written for one backup
+type BackupManifest struct {
+	BackupId        int              `json:"backup_id"`
+	BackupLevel     string           `json:"backup_level"`
+	UserId          string           `json:"user_id"`
+	NumberOfObjects int              `json:"number_of_objects"`
+	Hostname        string           `json:"hostname"`
+	ToolVersion     string           `json:"tool_version"`
+	Created         time.Time        `json:"created"`
+	Objects         []ManifestObject `json:"objects"`
+}
+
+// Datatype representing one object of a backup in the manifest
+type ManifestObject struct {
+	Key        string  `json:"key"`
+	ETag       string  `json:"etag"`
+	VersionId  string  `json:"version_id"`
+	SourcePath string  `json:"source_path"`
+	SourceSize int64   `json:"source_size"`
+	TargetSize int64   `json:"target_size"`
+	Duration   float64 `json:"duration"`
+	Checksum   string  `json:"checksum"`
+}
+
+// Datatype representing one pipe or file to be backed up
+type BackupSource struct {
+	Path         string
+	IsFile       bool
+	ExpectedSize int64
+}
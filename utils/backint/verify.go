@@ -0,0 +1,105 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package backint
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/cos"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/logging"
+
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+)
+
+/*
+Verifying that the objects can be restored from IBM Cloud Object Storage.
+The objects are downloaded like for a restore,
+but the data is discarded instead of written to SAP HANA.
+*/
+func Verify(
+	s3Client *s3.S3,
+) bool {
+	global.Logger.Debug("Function: verify")
+	startTime := time.Now()
+	chanDownload, valid := downloadObjects(s3Client, true)
+
+	// Checking the results of the single object downloads and return
+	return verifyResultHandler(chanDownload, startTime) && valid
+}
+
+/*
+Checking the results of all verified downloads,
+setting the messages and the return code
+*/
+func verifyResultHandler(chanDownload chan cos.Result, startTime time.Time) bool {
+	success := true
+	totalSize := int64(0)
+	for result := range chanDownload {
+		if result.Err != nil {
+			success = false
+			logging.BackintResultMsgs.AddErrorMessage(
+				result.SourcePath,
+				result.Err,
+			)
+			continue
+		}
+		if result.ETag == "" {
+			// A backup which is not found cannot be restored
+			success = false
+			logging.BackintResultMsgs.AddObjectNotFoundMessage(
+				result.SourcePath,
+			)
+			continue
+		}
+
+		throughput := getThroughput(result.TargetSize, result.Duration)
+		global.Logger.Info(fmt.Sprintf(
+			"Verified '%s': %d bytes in %.2f seconds (%.2f MB/s).",
+			result.Key,
+			result.TargetSize,
+			result.Duration,
+			throughput,
+		))
+		logging.BackintResultMsgs.AddVerifySuccessMessage(
+			result.ETag,
+			result.SourcePath,
+			result.TargetSize,
+			result.Duration,
+			throughput,
+		)
+		totalSize += result.TargetSize
+	}
+
+	duration := time.Since(startTime).Seconds()
+	global.Logger.Info(fmt.Sprintf(
+		"Verify: %d bytes in %.2f seconds (%.2f MB/s).",
+		totalSize,
+		duration,
+		getThroughput(totalSize, duration),
+	))
+	return success
+}
+
+/*
+Getting the throughput in MB per second
+*/
+func getThroughput(size int64, duration float64) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	return float64(size) / duration / (1024 * 1024)
+}
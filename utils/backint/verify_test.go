@@ -0,0 +1,138 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package backint
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/cos"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+/*
+Collecting the result messages of one verification
+*/
+func runVerifyResultHandler(t *testing.T, results ...cos.Result) (bool, []string) {
+	t.Helper()
+	if global.Logger == nil {
+		global.Logger = logrus.New()
+		global.Logger.SetOutput(io.Discard)
+	}
+	previous := logging.BackintResultMsgs
+	logging.BackintResultMsgs = nil
+	t.Cleanup(func() { logging.BackintResultMsgs = previous })
+
+	chanDownload := make(chan cos.Result, len(results))
+	for _, result := range results {
+		chanDownload <- result
+	}
+	close(chanDownload)
+	success := verifyResultHandler(chanDownload, time.Now())
+	return success, slices.Clone(logging.BackintResultMsgs)
+}
+
+func TestVerifyResultHandler(t *testing.T) {
+	const pipe = "/usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_0_1"
+	verified := cos.Result{
+		Key:        "HDB/TEN/databackup_0_1",
+		ETag:       "etag-1",
+		SourcePath: pipe,
+		TargetSize: 3 * 1024 * 1024,
+		Duration:   1.5,
+	}
+
+	tests := []struct {
+		name        string
+		results     []cos.Result
+		wantSuccess bool
+		want        []string
+	}{
+		{
+			name:        "verified",
+			results:     []cos.Result{verified},
+			wantSuccess: true,
+			want:        []string{`#VERIFIED "etag-1" "` + pipe + `" "3145728" "1.50" "2.00" `},
+		},
+		{
+			name:        "verified without duration",
+			results:     []cos.Result{{ETag: "etag-2", SourcePath: pipe, TargetSize: 10}},
+			wantSuccess: true,
+			want:        []string{`#VERIFIED "etag-2" "` + pipe + `" "10" "0.00" "0.00" `},
+		},
+		{
+			name: "checksum mismatch",
+			results: []cos.Result{{ETag: "etag-1", SourcePath: pipe,
+				Err: errors.New("checksum mismatch: expected 'aa', calculated 'bb'")}},
+			want: []string{`#ERROR "` + pipe + `" "checksum mismatch: expected 'aa', calculated 'bb'" `},
+		},
+		{
+			name: "decryption failing",
+			results: []cos.Result{{ETag: "etag-1", SourcePath: pipe,
+				Err: errors.New("authentication of encrypted data failed")}},
+			want: []string{`#ERROR "` + pipe + `" "authentication of encrypted data failed" `},
+		},
+		{
+			name:    "not found",
+			results: []cos.Result{{SourcePath: pipe}},
+			want:    []string{`#NOTFOUND "` + pipe + `" `},
+		},
+		{
+			name: "one of several objects failing",
+			results: []cos.Result{verified, {ETag: "etag-2", SourcePath: pipe + "_2",
+				Err: errors.New("authentication of encrypted data failed")}},
+			want: []string{
+				`#VERIFIED "etag-1" "` + pipe + `" "3145728" "1.50" "2.00" `,
+				`#ERROR "` + pipe + `_2" "authentication of encrypted data failed" `,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			success, messages := runVerifyResultHandler(t, tt.results...)
+			if success != tt.wantSuccess {
+				t.Errorf("verifyResultHandler() = %v, want %v", success, tt.wantSuccess)
+			}
+			if !slices.Equal(messages, tt.want) {
+				t.Errorf("messages = %q, want %q", messages, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetThroughput(t *testing.T) {
+	tests := []struct {
+		size     int64
+		duration float64
+		want     float64
+	}{
+		{10 * 1024 * 1024, 2, 5},
+		{1024 * 1024, 0.5, 2},
+		{1024 * 1024, 0, 0},
+		{0, 1, 0},
+	}
+	for _, tt := range tests {
+		if got := getThroughput(tt.size, tt.duration); got != tt.want {
+			t.Errorf("getThroughput(%d, %f) = %f, want %f", tt.size, tt.duration, got, tt.want)
+		}
+	}
+}
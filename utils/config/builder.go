@@ -15,10 +15,14 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
 
@@ -48,7 +52,11 @@ func GenerateConfiguration(
 
 	BackintConfig = updateConfigWithDefaults(basicConfig)
 
+	BackintConfig = updateConfigWithEndpoints(BackintConfig)
+
 	BackintConfig = updateConfigWithApikey(BackintConfig)
+	BackintConfig = updateConfigWithHmacKeys(BackintConfig)
+	BackintConfig = updateConfigWithEncryptionKey(BackintConfig)
 	return BackintConfig, true
 }
 
@@ -154,9 +162,9 @@ Updating the configuration parameter with value from config file
 func (cfgParm ConfigParameter) updateMatchingObj() bool {
 	for i, obj := range configDefaults {
 		if obj.section == cfgParm.section && obj.key == cfgParm.key {
-			// Special case for multipart_chunksize:
+			// Special case for sizes like multipart_chunksize:
 			// value must be calculated if a size unit is specified
-			if cfgParm.key == multipart_chunksize.key {
+			if obj.validationType == CONFIG_CHUNKSIZE {
 				size, unitU := getChunksizeSizeAndUnit(cfgParm.value)
 				configDefaults[i].configValue = calculateChunksizeInBytes(size, unitU)
 			} else {
@@ -189,19 +197,180 @@ func updateConfigWithDefaults(basicConfig []Default) BackintConfigT {
 }
 
 /*
-Reading the apikey from file "auth_keypath" and storing the value in map
+Deriving the endpoint urls from the regions if only
+the endpoint type is specified. An explicit endpoint url is kept.
+The secondary endpoint has its own type, by default the primary one.
+*/
+func updateConfigWithEndpoints(backintConfig BackintConfigT) BackintConfigT {
+	endpoints := []struct {
+		urlKey       string
+		url          string
+		region       string
+		regionKey    string
+		endpointType string
+	}{
+		{
+			urlKey:       "endpoint_url",
+			url:          backintConfig.EndpointUrl(),
+			region:       backintConfig.Region(),
+			regionKey:    "region",
+			endpointType: backintConfig.EndpointType(),
+		},
+		{
+			urlKey:       "secondary_endpoint_url",
+			url:          backintConfig.SecondaryEndpointUrl(),
+			region:       backintConfig.SecondaryRegion(),
+			regionKey:    "secondary_region",
+			endpointType: backintConfig.SecondaryEndpointType(),
+		},
+	}
+
+	var table endpointTable
+	for _, endpoint := range endpoints {
+		if endpoint.url != "" || endpoint.region == "" || endpoint.endpointType == "" {
+			continue
+		}
+		if table == nil {
+			var err error
+			table, err = loadEndpointTable(backintConfig.EndpointTable())
+			if err != nil {
+				fmt.Printf("Could not load the endpoint table: %s", err)
+				os.Exit(global.WRONG_PARAMETER)
+			}
+		}
+		url, found := table.lookup(endpoint.region, endpoint.endpointType)
+		if !found {
+			fmt.Printf(
+				"The endpoint table contains no '%s' endpoint for '%s = %s'.",
+				endpoint.endpointType,
+				endpoint.regionKey,
+				endpoint.region,
+			)
+			os.Exit(global.WRONG_PARAMETER)
+		}
+		backintConfig.set(endpoint.urlKey, url)
+	}
+	return backintConfig
+}
+
+/*
+Reading the apikey from its source and storing the value in map.
+The apikey is read from "auth_keypath", "apikey_env" or "apikey_command",
+the apikey of the secondary storage from the matching secondary parameters.
+The apikey is only needed for auth_mode apikey.
 */
 func updateConfigWithApikey(backintConfig BackintConfigT) BackintConfigT {
-	apikey, err := global.ReadApikeyFromFile(backintConfig.AuthKeypath())
+	if backintConfig.AuthMethod() != AUTH_APIKEY {
+		return backintConfig
+	}
+
+	source := newApikeySource(
+		backintConfig.AuthKeypath(),
+		backintConfig.ApikeyEnv(),
+		backintConfig.ApikeyCommand(),
+	)
+	apikey, err := source.read()
 	if err != nil {
 		fmt.Printf("Could not discover the apikey."+
-			" Check if file '%s' is available and contains the apikey.",
-			backintConfig.AuthKeypath(),
+			" Check if %s provides the apikey. Error: %s",
+			source,
+			err,
 		)
 		os.Exit(global.WRONG_PARAMETER)
 	}
 	backintConfig.set("apikey", apikey)
 
+	if backintConfig.IsSecondaryStorageEnabled() {
+		source := newApikeySource(
+			backintConfig.SecondaryAuthKeypath(),
+			backintConfig.SecondaryApikeyEnv(),
+			backintConfig.SecondaryApikeyCommand(),
+		)
+		secondaryApikey, err := source.read()
+		if err != nil {
+			fmt.Printf("Could not discover the apikey of the secondary storage."+
+				" Check if %s provides the apikey. Error: %s",
+				source,
+				err,
+			)
+			os.Exit(global.WRONG_PARAMETER)
+		}
+		backintConfig.set("secondary_apikey", secondaryApikey)
+	}
+
+	return backintConfig
+}
+
+/*
+Reading the HMAC keys from file "hmac_keypath" and storing the values in map.
+The keys of the secondary storage are read from "secondary_hmac_keypath".
+*/
+func updateConfigWithHmacKeys(backintConfig BackintConfigT) BackintConfigT {
+	if !backintConfig.IsHmacEnabled() {
+		return backintConfig
+	}
+
+	accessKeyId, secretAccessKey, err := global.ReadHmacKeysFromFile(
+		backintConfig.HmacKeypath(),
+	)
+	if err != nil {
+		fmt.Printf("Could not discover the HMAC keys."+
+			" Check if file '%s' is available and contains"+
+			" access_key_id and secret_access_key.",
+			backintConfig.HmacKeypath(),
+		)
+		os.Exit(global.WRONG_PARAMETER)
+	}
+	backintConfig.set("hmac_access_key_id", accessKeyId)
+	backintConfig.set("hmac_secret_access_key", secretAccessKey)
+
+	if backintConfig.IsSecondaryStorageEnabled() {
+		accessKeyId, secretAccessKey, err := global.ReadHmacKeysFromFile(
+			backintConfig.SecondaryHmacKeypath(),
+		)
+		if err != nil {
+			fmt.Printf("Could not discover the HMAC keys of the secondary storage."+
+				" Check if file '%s' is available and contains"+
+				" access_key_id and secret_access_key.",
+				backintConfig.SecondaryHmacKeypath(),
+			)
+			os.Exit(global.WRONG_PARAMETER)
+		}
+		backintConfig.set("secondary_hmac_access_key_id", accessKeyId)
+		backintConfig.set("secondary_hmac_secret_access_key", secretAccessKey)
+	}
+
+	return backintConfig
+}
+
+/*
+Reading the master key for client-side encryption from file
+"encryption_keypath" and storing the value in map.
+If no key id is specified, the fingerprint of the key is used as key id.
+*/
+func updateConfigWithEncryptionKey(backintConfig BackintConfigT) BackintConfigT {
+	if !backintConfig.IsEncryptionEnabled() {
+		return backintConfig
+	}
+
+	encodedKey, err := global.ReadKeyFromFile(backintConfig.EncryptionKeypath())
+	masterKey, errDecode := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || errDecode != nil || len(masterKey) != ENCRYPTION_KEY_LENGTH {
+		fmt.Printf("Could not discover the encryption key."+
+			" Check if file '%s' is available and contains"+
+			" a base64 encoded key of %d bytes.",
+			backintConfig.EncryptionKeypath(),
+			ENCRYPTION_KEY_LENGTH,
+		)
+		os.Exit(global.WRONG_PARAMETER)
+	}
+	backintConfig.set("encryption_key", encodedKey)
+
+	if backintConfig.EncryptionKeyId() == "" {
+		fingerprint := sha256.Sum256(masterKey)
+		backintConfig.set("encryption_key_id", hex.EncodeToString(fingerprint[:8]))
+	}
+
 	return backintConfig
 }
 
@@ -222,6 +391,30 @@ func calculateChunksizeInBytes(size string, unit string) string {
 	return fmt.Sprintf("%d", sizeI)
 }
 
+/*
+Parsing a daily time range with the format "HH:MM-HH:MM"
+Returning start and end as offset from midnight
+*/
+func ParseTimerange(timerange string) (time.Duration, time.Duration, error) {
+	splitted := strings.Split(timerange, "-")
+	if len(splitted) != 2 {
+		return 0, 0, fmt.Errorf("invalid time range '%s'", timerange)
+	}
+
+	var offsets []time.Duration
+	for _, t := range splitted {
+		parsed, err := time.Parse(TIMERANGE_FORMAT, strings.TrimSpace(t))
+		if err != nil {
+			return 0, 0, err
+		}
+		offsets = append(offsets,
+			time.Duration(parsed.Hour())*time.Hour+
+				time.Duration(parsed.Minute())*time.Minute,
+		)
+	}
+	return offsets[0], offsets[1], nil
+}
+
 func getChunksizeSizeAndUnit(chunksize string) (string, string) {
 	_, err := strconv.Atoi(chunksize)
 	if err == nil {
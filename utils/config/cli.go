@@ -49,7 +49,8 @@ func parseArguments() global.CommandLineArguments {
 	backupId := flag.Int("s", -1, "Backup Id")
 	numberOfObjects := flag.Int("c", -1, "Number of objects")
 	backupLevel := flag.String("l", "", "backup level")
-	authKeypath := flag.String("keypath", "", "path to the apikey file")
+	authKeypath := flag.String("keypath", "", "path to the apikey or HMAC key file")
+	authMode := flag.String("authmode", AUTH_APIKEY, "authentication mode, apikey or hmac")
 	authEndpoint := flag.String("authendpoint", "https://private.iam.cloud.ibm.com/identity/token", "IBM auth endpoint")
 	region := flag.String("region", "", "region")
 	endpointUrl := flag.String("endpoint", "", "endpoint url")
@@ -82,6 +83,7 @@ func parseArguments() global.CommandLineArguments {
 
 	// Used when called from snappy agent
 	global.Args.AuthKeypath = *authKeypath
+	global.Args.AuthMode = strings.ToLower(*authMode)
 	global.Args.AuthEndpoint = *authEndpoint
 	global.Args.EndpointUrl = *endpointUrl
 	global.Args.Region = *region
@@ -206,9 +208,19 @@ func dbBackupParametersValid(function string) bool {
 		return false
 	}
 
+	if global.Args.AuthMode != AUTH_APIKEY &&
+		global.Args.AuthMode != AUTH_HMAC {
+		fmt.Printf(
+			"You must specify either '%s' or '%s' for argument '-authmode'.\n",
+			AUTH_APIKEY,
+			AUTH_HMAC,
+		)
+		return false
+	}
+
 	if isFileValid(global.Args.AuthKeypath, true) != "" {
 		fmt.Println("You must specify a valid path to the " +
-			"file containing your apikey.")
+			"file containing your apikey or HMAC keys.")
 		return false
 	}
 
@@ -14,16 +14,21 @@
 
 package config
 
+import "time"
+
 const (
 	CONFIG_BOOL      = "bool"
 	CONFIG_CHUNKSIZE = "chunksize"
 	CONFIG_FILE      = "file"
+	CONFIG_HTTP_URL  = "httpurl"
 	CONFIG_INT       = "int"
 	CONFIG_LIST      = "list"
 	CONFIG_PERIOD    = "period"
 	CONFIG_RANGE     = "range"
 	CONFIG_STRING    = "string"
 	CONFIG_TAG       = "tag"
+	CONFIG_TEMPLATE  = "template"
+	CONFIG_TIMERANGE = "timerange"
 	CONFIG_URL       = "url"
 )
 
@@ -37,12 +42,15 @@ var validConfigTypes = []string{
 	CONFIG_BOOL,
 	CONFIG_CHUNKSIZE,
 	CONFIG_FILE,
+	CONFIG_HTTP_URL,
 	CONFIG_INT,
 	CONFIG_LIST,
 	CONFIG_PERIOD,
 	CONFIG_RANGE,
 	CONFIG_STRING,
 	CONFIG_TAG,
+	CONFIG_TEMPLATE,
+	CONFIG_TIMERANGE,
 	CONFIG_URL,
 }
 
@@ -52,12 +60,61 @@ var validSizeUnits = []string{
 	UNIT_GB,
 }
 
+// Format of the start and end time of a time range
+const TIMERANGE_FORMAT = "15:04"
+
+// Placeholders of the object key template
+const (
+	PLACEHOLDER_SID           = "SID"
+	PLACEHOLDER_TENANT        = "TENANT"
+	PLACEHOLDER_LEVEL         = "LEVEL"
+	PLACEHOLDER_BACKUP_ID     = "BACKUP_ID"
+	PLACEHOLDER_USER          = "USER"
+	PLACEHOLDER_DATE          = "DATE"
+	PLACEHOLDER_HOST          = "HOST"
+	PLACEHOLDER_PIPE_BASENAME = "PIPE_BASENAME"
+)
+
+var validKeyTemplatePlaceholders = []string{
+	PLACEHOLDER_SID,
+	PLACEHOLDER_TENANT,
+	PLACEHOLDER_LEVEL,
+	PLACEHOLDER_BACKUP_ID,
+	PLACEHOLDER_USER,
+	PLACEHOLDER_DATE,
+	PLACEHOLDER_HOST,
+	PLACEHOLDER_PIPE_BASENAME,
+}
+
+// Placeholders of the object tags
+const (
+	TAG_PLACEHOLDER_BACKUP_LEVEL = "BACKUP_LEVEL"
+	TAG_PLACEHOLDER_BACKUP_ID    = "BACKUP_ID"
+	TAG_PLACEHOLDER_HOSTNAME     = "HOSTNAME"
+	TAG_PLACEHOLDER_SID          = "SID"
+	TAG_PLACEHOLDER_TENANT       = "TENANT"
+	TAG_PLACEHOLDER_USER         = "USER"
+)
+
+var validTagPlaceholders = []string{
+	TAG_PLACEHOLDER_BACKUP_LEVEL,
+	TAG_PLACEHOLDER_BACKUP_ID,
+	TAG_PLACEHOLDER_HOSTNAME,
+	TAG_PLACEHOLDER_SID,
+	TAG_PLACEHOLDER_TENANT,
+	TAG_PLACEHOLDER_USER,
+}
+
 // Parameter configuration file sections
 const (
 	SECTION_CLOUD_STORAGE = "cloud_storage"
 	SECTION_BACKINT       = "backint"
 	SECTION_OBJECTS       = "objects"
 	SECTION_TRACE         = "trace"
+	SECTION_ENCRYPTION    = "encryption"
+	SECTION_SECONDARY     = "secondary_storage"
+	SECTION_RETRY         = "retry"
+	SECTION_NETWORK       = "network"
 )
 
 var validSections = []string{
@@ -65,16 +122,118 @@ var validSections = []string{
 	SECTION_BACKINT,
 	SECTION_OBJECTS,
 	SECTION_TRACE,
+	SECTION_ENCRYPTION,
+	SECTION_SECONDARY,
+	SECTION_RETRY,
+	SECTION_NETWORK,
 }
 
 // Maximum number of allowed tags
 const MAX_NUMBER_OF_TAGS int = 10
 
+// Maximum number of characters of the key and the value of a tag
+const (
+	MAX_TAG_KEY_LENGTH   int = 128
+	MAX_TAG_VALUE_LENGTH int = 256
+)
+
 // Modes for authentication method
 const (
-	AUTH_APIKEY string = "apikey"
+	AUTH_APIKEY          string = "apikey"
+	AUTH_TRUSTED_PROFILE string = "trustedprofile"
+	AUTH_HMAC            string = "hmac"
+	AUTH_ANONYMOUS       string = "anonymous"
+)
+
+// Types of the endpoints derived from the region
+const (
+	ENDPOINT_PUBLIC  string = "public"
+	ENDPOINT_PRIVATE string = "private"
+	ENDPOINT_DIRECT  string = "direct"
+)
+
+// Providers of the object storage
+const (
+	PROVIDER_IBM           string = "ibm"
+	PROVIDER_S3_COMPATIBLE string = "s3compatible"
+)
+
+// Parameters the apikey can be read from
+var apikeySourceKeys = []string{
+	"auth_keypath",
+	"apikey_env",
+	"apikey_command",
+}
+
+// Parameters the apikey of the secondary storage can be read from
+var secondaryApikeySourceKeys = []string{
+	"secondary_auth_keypath",
+	"secondary_apikey_env",
+	"secondary_apikey_command",
+}
+
+// Secrets stored in the configuration, never written to log
+var SensitiveKeys = map[string]bool{
+	"apikey":                           true,
+	"secondary_apikey":                 true,
+	"encryption_key":                   true,
+	"hmac_secret_access_key":           true,
+	"secondary_hmac_secret_access_key": true,
+}
+
+// Maximum runtime of the helper command printing the apikey
+const APIKEY_COMMAND_TIMEOUT = 60 * time.Second
+
+// Default path of the compute resource token
+const DEFAULT_CR_TOKEN_PATH string = "/var/run/secrets/tokens/vault-token"
+
+// Minimum TLS versions
+const (
+	TLS_VERSION_12 string = "1.2"
+	TLS_VERSION_13 string = "1.3"
+)
+
+// Algorithms for client-side encryption
+const (
+	ENCRYPTION_NONE      string = "none"
+	ENCRYPTION_AES256GCM string = "aes256gcm"
+)
+
+// Algorithms for streaming compression
+const (
+	COMPRESSION_NONE string = "none"
+	COMPRESSION_ZSTD string = "zstd"
+	COMPRESSION_LZ4  string = "lz4"
+)
+
+// Jitter applied to the delay between two retries
+const (
+	JITTER_NONE  string = "none"
+	JITTER_FULL  string = "full"
+	JITTER_EQUAL string = "equal"
+)
+
+// Classes of errors which can be retried
+const (
+	RETRY_CLASS_THROTTLING string = "throttling"
+	RETRY_CLASS_TIMEOUT    string = "timeout"
+	RETRY_CLASS_SERVER     string = "server"
+	RETRY_CLASS_NETWORK    string = "network"
 )
 
+var validRetryErrorClasses = []string{
+	RETRY_CLASS_THROTTLING,
+	RETRY_CLASS_TIMEOUT,
+	RETRY_CLASS_SERVER,
+	RETRY_CLASS_NETWORK,
+}
+
+// Highest compression level supported by lz4
+const MAX_LZ4_COMPRESSION_LEVEL int = 9
+
+// Length of the master key for client-side encryption in bytes
+const ENCRYPTION_KEY_LENGTH int = 32
+
 // File validation values
 const (
 	FILEOK          = 0
@@ -0,0 +1,91 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+)
+
+/*
+Getting the source the apikey is read from.
+Only one of the sources is specified, this is checked by the validation.
+*/
+func newApikeySource(keypath string, env string, command string) apikeySource {
+	switch {
+	case command != "":
+		return apikeyCommand{command: command}
+	case env != "":
+		return apikeyEnv{name: env}
+	default:
+		return apikeyFile{path: keypath}
+	}
+}
+
+/*
+Reading the apikey from a plain or JSON key file
+*/
+func (s apikeyFile) read() (string, error) {
+	return global.ReadApikeyFromFile(s.path)
+}
+
+func (s apikeyFile) String() string {
+	return fmt.Sprintf("file '%s'", s.path)
+}
+
+/*
+Reading the apikey from an environment variable
+*/
+func (s apikeyEnv) read() (string, error) {
+	value, found := os.LookupEnv(s.name)
+	if !found {
+		return "", fmt.Errorf("environment variable '%s' is not set", s.name)
+	}
+	return global.ParseApikey(value)
+}
+
+func (s apikeyEnv) String() string {
+	return fmt.Sprintf("environment variable '%s'", s.name)
+}
+
+/*
+Running the helper command and reading the apikey from its stdout.
+The command is run by the shell, the output is either
+the plain apikey or a JSON key file.
+*/
+func (s apikeyCommand) read() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), APIKEY_COMMAND_TIMEOUT)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", s.command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return global.ParseApikey(stdout.String())
+}
+
+func (s apikeyCommand) String() string {
+	return fmt.Sprintf("command '%s'", s.command)
+}
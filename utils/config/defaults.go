@@ -20,7 +20,7 @@ cloud_storage Section
 var auth_keypath = Default{
 	key:            "auth_keypath",
 	section:        SECTION_CLOUD_STORAGE,
-	mandatory:      true,
+	mandatory:      false,
 	validationType: CONFIG_FILE}
 
 var auth_mode = Default{
@@ -28,9 +28,48 @@ var auth_mode = Default{
 	section:        SECTION_CLOUD_STORAGE,
 	mandatory:      true,
 	defaultValue:   AUTH_APIKEY,
-	possibleValues: []string{AUTH_APIKEY},
+	possibleValues: []string{AUTH_APIKEY, AUTH_TRUSTED_PROFILE, AUTH_HMAC, AUTH_ANONYMOUS},
 	validationType: CONFIG_LIST}
 
+var provider = Default{
+	key:            "provider",
+	section:        SECTION_CLOUD_STORAGE,
+	defaultValue:   PROVIDER_IBM,
+	possibleValues: []string{PROVIDER_IBM, PROVIDER_S3_COMPATIBLE},
+	mandatory:      false,
+	validationType: CONFIG_LIST}
+
+var apikey_env = Default{
+	key:            "apikey_env",
+	section:        SECTION_CLOUD_STORAGE,
+	mandatory:      false,
+	validationType: CONFIG_STRING}
+
+var apikey_command = Default{
+	key:            "apikey_command",
+	section:        SECTION_CLOUD_STORAGE,
+	mandatory:      false,
+	validationType: CONFIG_STRING}
+
+var hmac_keypath = Default{
+	key:            "hmac_keypath",
+	section:        SECTION_CLOUD_STORAGE,
+	mandatory:      false,
+	validationType: CONFIG_FILE}
+
+var trusted_profile_id = Default{
+	key:            "trusted_profile_id",
+	section:        SECTION_CLOUD_STORAGE,
+	mandatory:      false,
+	validationType: CONFIG_STRING}
+
+var cr_token_path = Default{
+	key:            "cr_token_path",
+	section:        SECTION_CLOUD_STORAGE,
+	defaultValue:   DEFAULT_CR_TOKEN_PATH,
+	mandatory:      false,
+	validationType: CONFIG_FILE}
+
 var bucket = Default{
 	key:            "bucket",
 	section:        SECTION_CLOUD_STORAGE,
@@ -40,9 +79,22 @@ var bucket = Default{
 var endpoint_url = Default{
 	key:            "endpoint_url",
 	section:        SECTION_CLOUD_STORAGE,
-	mandatory:      true,
+	mandatory:      false,
 	validationType: CONFIG_URL}
 
+var endpoint_type = Default{
+	key:            "endpoint_type",
+	section:        SECTION_CLOUD_STORAGE,
+	possibleValues: []string{ENDPOINT_PUBLIC, ENDPOINT_PRIVATE, ENDPOINT_DIRECT},
+	mandatory:      false,
+	validationType: CONFIG_LIST}
+
+var endpoint_table = Default{
+	key:            "endpoint_table",
+	section:        SECTION_CLOUD_STORAGE,
+	mandatory:      false,
+	validationType: CONFIG_FILE}
+
 var ibm_auth_endpoint = Default{
 	key:            "ibm_auth_endpoint",
 	section:        SECTION_CLOUD_STORAGE,
@@ -67,6 +119,237 @@ var region = Default{
 	mandatory:      true,
 	validationType: CONFIG_LIST}
 
+/*
+secondary_storage Section
+*/
+var secondary_auth_keypath = Default{
+	key:            "secondary_auth_keypath",
+	section:        SECTION_SECONDARY,
+	mandatory:      false,
+	validationType: CONFIG_FILE}
+
+var secondary_apikey_env = Default{
+	key:            "secondary_apikey_env",
+	section:        SECTION_SECONDARY,
+	mandatory:      false,
+	validationType: CONFIG_STRING}
+
+var secondary_apikey_command = Default{
+	key:            "secondary_apikey_command",
+	section:        SECTION_SECONDARY,
+	mandatory:      false,
+	validationType: CONFIG_STRING}
+
+var secondary_hmac_keypath = Default{
+	key:            "secondary_hmac_keypath",
+	section:        SECTION_SECONDARY,
+	mandatory:      false,
+	validationType: CONFIG_FILE}
+
+var secondary_bucket = Default{
+	key:            "secondary_bucket",
+	section:        SECTION_SECONDARY,
+	mandatory:      false,
+	validationType: CONFIG_STRING}
+
+var secondary_endpoint_url = Default{
+	key:            "secondary_endpoint_url",
+	section:        SECTION_SECONDARY,
+	mandatory:      false,
+	validationType: CONFIG_URL}
+
+var secondary_endpoint_type = Default{
+	key:            "secondary_endpoint_type",
+	section:        SECTION_SECONDARY,
+	possibleValues: endpoint_type.possibleValues,
+	mandatory:      false,
+	validationType: CONFIG_LIST}
+
+var secondary_ibm_auth_endpoint = Default{
+	key:            "secondary_ibm_auth_endpoint",
+	section:        SECTION_SECONDARY,
+	defaultValue:   "https://private.iam.cloud.ibm.com/identity/token",
+	mandatory:      false,
+	validationType: CONFIG_URL}
+
+var secondary_region = Default{
+	key:            "secondary_region",
+	section:        SECTION_SECONDARY,
+	possibleValues: region.possibleValues,
+	mandatory:      false,
+	validationType: CONFIG_LIST}
+
+var write_quorum = Default{
+	key:            "write_quorum",
+	section:        SECTION_SECONDARY,
+	defaultValue:   "2",
+	min:            1,
+	max:            2,
+	mandatory:      false,
+	validationType: CONFIG_RANGE}
+
+/*
+retry Section
+*/
+var retry_max_attempts = Default{
+	key:            "retry_max_attempts",
+	section:        SECTION_RETRY,
+	defaultValue:   "6",
+	min:            1,
+	max:            20,
+	mandatory:      false,
+	validationType: CONFIG_RANGE}
+
+var retry_base_delay = Default{
+	key:            "retry_base_delay",
+	section:        SECTION_RETRY,
+	defaultValue:   "500",
+	min:            1,
+	max:            60000,
+	mandatory:      false,
+	validationType: CONFIG_RANGE}
+
+var retry_max_delay = Default{
+	key:            "retry_max_delay",
+	section:        SECTION_RETRY,
+	defaultValue:   "30000",
+	min:            1,
+	max:            600000,
+	mandatory:      false,
+	validationType: CONFIG_RANGE}
+
+var retry_jitter = Default{
+	key:            "retry_jitter",
+	section:        SECTION_RETRY,
+	defaultValue:   JITTER_FULL,
+	possibleValues: []string{JITTER_NONE, JITTER_FULL, JITTER_EQUAL},
+	mandatory:      false,
+	validationType: CONFIG_LIST}
+
+var retry_error_classes = Default{
+	key:     "retry_error_classes",
+	section: SECTION_RETRY,
+	defaultValue: RETRY_CLASS_THROTTLING + "," +
+		RETRY_CLASS_TIMEOUT + "," +
+		RETRY_CLASS_SERVER + "," +
+		RETRY_CLASS_NETWORK,
+	mandatory:      false,
+	validationType: CONFIG_STRING}
+
+/*
+network Section
+*/
+var ca_bundle = Default{
+	key:            "ca_bundle",
+	section:        SECTION_NETWORK,
+	mandatory:      false,
+	validationType: CONFIG_FILE}
+
+var client_cert = Default{
+	key:            "client_cert",
+	section:        SECTION_NETWORK,
+	mandatory:      false,
+	validationType: CONFIG_FILE}
+
+var client_key = Default{
+	key:            "client_key",
+	section:        SECTION_NETWORK,
+	mandatory:      false,
+	validationType: CONFIG_FILE}
+
+var tls_min_version = Default{
+	key:            "tls_min_version",
+	section:        SECTION_NETWORK,
+	defaultValue:   TLS_VERSION_12,
+	possibleValues: []string{TLS_VERSION_12, TLS_VERSION_13},
+	mandatory:      false,
+	validationType: CONFIG_LIST}
+
+var proxy_url = Default{
+	key:            "proxy_url",
+	section:        SECTION_NETWORK,
+	mandatory:      false,
+	validationType: CONFIG_HTTP_URL}
+
+var connect_timeout = Default{
+	key:            "connect_timeout",
+	section:        SECTION_NETWORK,
+	defaultValue:   "60",
+	min:            1,
+	max:            600,
+	mandatory:      false,
+	validationType: CONFIG_RANGE}
+
+var tls_handshake_timeout = Default{
+	key:            "tls_handshake_timeout",
+	section:        SECTION_NETWORK,
+	defaultValue:   "50",
+	min:            1,
+	max:            600,
+	mandatory:      false,
+	validationType: CONFIG_RANGE}
+
+var response_header_timeout = Default{
+	key:            "response_header_timeout",
+	section:        SECTION_NETWORK,
+	defaultValue:   "50",
+	min:            1,
+	max:            3600,
+	mandatory:      false,
+	validationType: CONFIG_RANGE}
+
+var expect_continue_timeout = Default{
+	key:            "expect_continue_timeout",
+	section:        SECTION_NETWORK,
+	defaultValue:   "1",
+	min:            0,
+	max:            60,
+	mandatory:      false,
+	validationType: CONFIG_RANGE}
+
+var idle_conn_timeout = Default{
+	key:            "idle_conn_timeout",
+	section:        SECTION_NETWORK,
+	defaultValue:   "60",
+	min:            1,
+	max:            3600,
+	mandatory:      false,
+	validationType: CONFIG_RANGE}
+
+var keepalive_interval = Default{
+	key:            "keepalive_interval",
+	section:        SECTION_NETWORK,
+	defaultValue:   "60",
+	min:            1,
+	max:            3600,
+	mandatory:      false,
+	validationType: CONFIG_RANGE}
+
+var max_idle_conns_per_host = Default{
+	key:            "max_idle_conns_per_host",
+	section:        SECTION_NETWORK,
+	defaultValue:   "10",
+	min:            0,
+	max:            1000,
+	mandatory:      false,
+	validationType: CONFIG_RANGE}
+
+var max_conns_per_host = Default{
+	key:            "max_conns_per_host",
+	section:        SECTION_NETWORK,
+	defaultValue:   "0",
+	min:            0,
+	max:            1000,
+	mandatory:      false,
+	validationType: CONFIG_RANGE}
+
+var force_http1 = Default{
+	key:            "force_http1",
+	section:        SECTION_NETWORK,
+	defaultValue:   "false",
+	mandatory:      false,
+	validationType: CONFIG_BOOL}
+
 /*
 backint Section
 */
@@ -86,6 +369,81 @@ var multipart_chunksize = Default{
 	mandatory:      false,
 	validationType: CONFIG_CHUNKSIZE}
 
+var expected_pipe_size = Default{
+	key:            "expected_pipe_size",
+	section:        SECTION_BACKINT,
+	defaultValue:   "0",
+	mandatory:      false,
+	validationType: CONFIG_CHUNKSIZE}
+
+var download_range_size = Default{
+	key:            "download_range_size",
+	section:        SECTION_BACKINT,
+	defaultValue:   "0",
+	mandatory:      false,
+	validationType: CONFIG_CHUNKSIZE}
+
+var max_inflight_parts = Default{
+	key:            "max_inflight_parts",
+	section:        SECTION_BACKINT,
+	defaultValue:   "0",
+	min:            0,
+	max:            1000,
+	mandatory:      false,
+	validationType: CONFIG_RANGE}
+
+var max_inflight_memory = Default{
+	key:            "max_inflight_memory",
+	section:        SECTION_BACKINT,
+	defaultValue:   "0",
+	mandatory:      false,
+	validationType: CONFIG_CHUNKSIZE}
+
+var restore_buffer_memory = Default{
+	key:            "restore_buffer_memory",
+	section:        SECTION_BACKINT,
+	defaultValue:   "0",
+	mandatory:      false,
+	validationType: CONFIG_CHUNKSIZE}
+
+var max_upload_bandwidth = Default{
+	key:            "max_upload_bandwidth",
+	section:        SECTION_BACKINT,
+	defaultValue:   "0",
+	mandatory:      false,
+	validationType: CONFIG_CHUNKSIZE}
+
+var max_download_bandwidth = Default{
+	key:            "max_download_bandwidth",
+	section:        SECTION_BACKINT,
+	defaultValue:   "0",
+	mandatory:      false,
+	validationType: CONFIG_CHUNKSIZE}
+
+var bandwidth_schedule = Default{
+	key:            "bandwidth_schedule",
+	section:        SECTION_BACKINT,
+	defaultValue:   "",
+	mandatory:      false,
+	validationType: CONFIG_TIMERANGE}
+
+var compression = Default{
+	key:            "compression",
+	section:        SECTION_BACKINT,
+	defaultValue:   COMPRESSION_NONE,
+	possibleValues: []string{COMPRESSION_NONE, COMPRESSION_ZSTD, COMPRESSION_LZ4},
+	mandatory:      false,
+	validationType: CONFIG_LIST}
+
+var compression_level = Default{
+	key:            "compression_level",
+	section:        SECTION_BACKINT,
+	defaultValue:   "3",
+	min:            1,
+	max:            22,
+	mandatory:      false,
+	validationType: CONFIG_RANGE}
+
 // Not propagated to customer
 var timeout_microsecond = Default{
 	key:            "timeout_microsecond",
@@ -112,6 +470,20 @@ var remove_key_prefix = Default{
 	mandatory:      false,
 	validationType: CONFIG_STRING}
 
+var key_template = Default{
+	key:            "key_template",
+	section:        SECTION_OBJECTS,
+	defaultValue:   "",
+	mandatory:      false,
+	validationType: CONFIG_TEMPLATE}
+
+var manifest_key_prefix = Default{
+	key:            "manifest_key_prefix",
+	section:        SECTION_OBJECTS,
+	defaultValue:   "",
+	mandatory:      false,
+	validationType: CONFIG_STRING}
+
 var object_lock_legal_hold_status = Default{
 	key:            "object_lock_legal_hold_status",
 	section:        SECTION_OBJECTS,
@@ -156,21 +528,97 @@ var agent_log_level = Default{
 	mandatory:      false,
 	validationType: CONFIG_LIST}
 
+/*
+encryption Section
+*/
+var encryption_algorithm = Default{
+	key:            "encryption_algorithm",
+	section:        SECTION_ENCRYPTION,
+	defaultValue:   ENCRYPTION_NONE,
+	possibleValues: []string{ENCRYPTION_NONE, ENCRYPTION_AES256GCM},
+	mandatory:      false,
+	validationType: CONFIG_LIST}
+
+var encryption_keypath = Default{
+	key:            "encryption_keypath",
+	section:        SECTION_ENCRYPTION,
+	mandatory:      false,
+	validationType: CONFIG_FILE}
+
+var encryption_key_id = Default{
+	key:            "encryption_key_id",
+	section:        SECTION_ENCRYPTION,
+	defaultValue:   "",
+	mandatory:      false,
+	validationType: CONFIG_STRING}
+
 var configDefaults = []Default{
+	provider,
 	auth_mode,
 	auth_keypath,
+	apikey_env,
+	apikey_command,
+	hmac_keypath,
+	trusted_profile_id,
+	cr_token_path,
 	bucket,
 	region,
 	endpoint_url,
+	endpoint_type,
+	endpoint_table,
 	ibm_auth_endpoint,
 	max_concurrency,
 	multipart_chunksize,
+	expected_pipe_size,
+	download_range_size,
+	max_inflight_parts,
+	max_inflight_memory,
+	restore_buffer_memory,
+	max_upload_bandwidth,
+	max_download_bandwidth,
+	bandwidth_schedule,
+	compression,
+	compression_level,
 	remove_key_prefix,
 	additional_key_prefix,
+	key_template,
+	manifest_key_prefix,
 	object_tags,
 	object_lock_retention_mode,
 	object_lock_retention_period,
 	object_lock_legal_hold_status,
 	agent_log_level,
 	timeout_microsecond,
+	encryption_algorithm,
+	encryption_keypath,
+	encryption_key_id,
+	secondary_auth_keypath,
+	secondary_apikey_env,
+	secondary_apikey_command,
+	secondary_hmac_keypath,
+	secondary_bucket,
+	secondary_region,
+	secondary_endpoint_url,
+	secondary_endpoint_type,
+	secondary_ibm_auth_endpoint,
+	write_quorum,
+	retry_max_attempts,
+	retry_base_delay,
+	retry_max_delay,
+	retry_jitter,
+	retry_error_classes,
+	ca_bundle,
+	client_cert,
+	client_key,
+	tls_min_version,
+	proxy_url,
+	connect_timeout,
+	tls_handshake_timeout,
+	response_header_timeout,
+	expect_continue_timeout,
+	idle_conn_timeout,
+	keepalive_interval,
+	max_idle_conns_per_host,
+	max_conns_per_host,
+	force_http1,
 }
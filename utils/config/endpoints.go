@@ -0,0 +1,98 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Endpoints of all IBM Cloud Object Storage regions
+//
+//go:embed endpoints.json
+var bundledEndpoints []byte
+
+/*
+Loading the endpoint table.
+The entries of the local file, if specified,
+override the bundled endpoints of the same region and type.
+*/
+func loadEndpointTable(path string) (endpointTable, error) {
+	table := make(endpointTable)
+	if err := json.Unmarshal(bundledEndpoints, &table); err != nil {
+		return nil, fmt.Errorf("bundled endpoint table: %w", err)
+	}
+	if path == "" {
+		return table, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	local := make(endpointTable)
+	if err := json.Unmarshal(content, &local); err != nil {
+		return nil, err
+	}
+	for region, endpoints := range local {
+		if table[region] == nil {
+			table[region] = make(map[string]string)
+		}
+		for endpointType, url := range endpoints {
+			table[region][endpointType] = url
+		}
+	}
+	return table, nil
+}
+
+/*
+Getting the endpoint of the given type for a region
+*/
+func (t endpointTable) lookup(region string, endpointType string) (string, bool) {
+	url, found := t[region][endpointType]
+	return url, found && url != ""
+}
+
+/*
+Returns true if the url is one of the endpoints of the region.
+If an endpoint type is given, only the endpoint of this type matches.
+Regions missing in the table are not checked.
+*/
+func (t endpointTable) matches(region string, endpointType string, url string) bool {
+	endpoints, found := t[region]
+	if !found {
+		return true
+	}
+	url = normalizeEndpoint(url)
+	for currentType, endpoint := range endpoints {
+		if endpointType != "" && currentType != endpointType {
+			continue
+		}
+		if normalizeEndpoint(endpoint) == url {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Normalizing an endpoint url for comparison
+*/
+func normalizeEndpoint(url string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(url)), "/")
+}
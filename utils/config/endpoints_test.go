@@ -0,0 +1,248 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/*
+Writing a local endpoint table for one test
+*/
+func writeEndpointTable(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "endpoints.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBundledEndpointTable(t *testing.T) {
+	table, err := loadEndpointTable("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, region := range region.possibleValues {
+		for _, endpointType := range endpoint_type.possibleValues {
+			if _, found := table.lookup(region, endpointType); !found {
+				t.Errorf("bundled table contains no '%s' endpoint for '%s'", endpointType, region)
+			}
+		}
+	}
+}
+
+func TestEndpointLookup(t *testing.T) {
+	local := writeEndpointTable(t, `{
+		"eu-de": {"private": "https://cos.private.example.com"},
+		"on-prem": {"public": "https://cos.example.com", "direct": ""}
+	}`)
+
+	tests := []struct {
+		name         string
+		path         string
+		region       string
+		endpointType string
+		want         string
+		wantFound    bool
+	}{
+		{
+			name:         "bundled public endpoint",
+			region:       "eu-de",
+			endpointType: ENDPOINT_PUBLIC,
+			want:         "https://s3.eu-de.cloud-object-storage.appdomain.cloud",
+			wantFound:    true,
+		},
+		{
+			name:         "bundled private endpoint",
+			region:       "us-south",
+			endpointType: ENDPOINT_PRIVATE,
+			want:         "https://s3.private.us-south.cloud-object-storage.appdomain.cloud",
+			wantFound:    true,
+		},
+		{
+			name:         "unknown region",
+			region:       "mars-1",
+			endpointType: ENDPOINT_PUBLIC,
+		},
+		{
+			name:         "unknown type",
+			region:       "eu-de",
+			endpointType: "internal",
+		},
+		{
+			name:         "local entry overriding the bundled one",
+			path:         local,
+			region:       "eu-de",
+			endpointType: ENDPOINT_PRIVATE,
+			want:         "https://cos.private.example.com",
+			wantFound:    true,
+		},
+		{
+			name:         "bundled entry of a region in the local table",
+			path:         local,
+			region:       "eu-de",
+			endpointType: ENDPOINT_DIRECT,
+			want:         "https://s3.direct.eu-de.cloud-object-storage.appdomain.cloud",
+			wantFound:    true,
+		},
+		{
+			name:         "local region",
+			path:         local,
+			region:       "on-prem",
+			endpointType: ENDPOINT_PUBLIC,
+			want:         "https://cos.example.com",
+			wantFound:    true,
+		},
+		{
+			name:         "empty local entry",
+			path:         local,
+			region:       "on-prem",
+			endpointType: ENDPOINT_DIRECT,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := loadEndpointTable(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, found := table.lookup(tt.region, tt.endpointType)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("lookup(%q, %q) = %q, %v, want %q, %v",
+					tt.region, tt.endpointType, got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestLoadEndpointTableErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"missing file", filepath.Join(t.TempDir(), "missing.json")},
+		{"invalid JSON", writeEndpointTable(t, `{"eu-de": `)},
+		{"wrong structure", writeEndpointTable(t, `{"eu-de": "https://cos.example.com"}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadEndpointTable(tt.path); err == nil {
+				t.Errorf("loadEndpointTable(%q) succeeded", tt.path)
+			}
+		})
+	}
+}
+
+func TestEndpointMatches(t *testing.T) {
+	table, err := loadEndpointTable("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		region       string
+		endpointType string
+		url          string
+		want         bool
+	}{
+		{"endpoint of the region", "eu-de", "", "https://s3.eu-de.cloud-object-storage.appdomain.cloud", true},
+		{"endpoint of the type", "eu-de", ENDPOINT_DIRECT, "https://s3.direct.eu-de.cloud-object-storage.appdomain.cloud", true},
+		{"case and trailing slash", "eu-de", "", " HTTPS://S3.EU-DE.cloud-object-storage.appdomain.cloud/", true},
+		{"endpoint of another type", "eu-de", ENDPOINT_PRIVATE, "https://s3.eu-de.cloud-object-storage.appdomain.cloud", false},
+		{"endpoint of another region", "eu-de", "", "https://s3.eu-gb.cloud-object-storage.appdomain.cloud", false},
+		{"region missing in the table", "on-prem", "", "https://cos.example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := table.matches(tt.region, tt.endpointType, tt.url); got != tt.want {
+				t.Errorf("matches(%q, %q, %q) = %v, want %v", tt.region, tt.endpointType, tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateConfigWithEndpoints(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        BackintConfigT
+		wantPrimary   string
+		wantSecondary string
+	}{
+		{
+			name: "endpoint type",
+			config: BackintConfigT{
+				"region":        "eu-de",
+				"endpoint_type": ENDPOINT_PRIVATE,
+			},
+			wantPrimary: "https://s3.private.eu-de.cloud-object-storage.appdomain.cloud",
+		},
+		{
+			name: "explicit endpoint url",
+			config: BackintConfigT{
+				"region":        "eu-de",
+				"endpoint_type": ENDPOINT_PRIVATE,
+				"endpoint_url":  "https://s3.eu-de.cloud-object-storage.appdomain.cloud",
+			},
+			wantPrimary: "https://s3.eu-de.cloud-object-storage.appdomain.cloud",
+		},
+		{
+			name: "secondary region with the primary type",
+			config: BackintConfigT{
+				"region":           "eu-de",
+				"secondary_region": "eu-gb",
+				"endpoint_type":    ENDPOINT_DIRECT,
+			},
+			wantPrimary:   "https://s3.direct.eu-de.cloud-object-storage.appdomain.cloud",
+			wantSecondary: "https://s3.direct.eu-gb.cloud-object-storage.appdomain.cloud",
+		},
+		{
+			name: "secondary region with its own type",
+			config: BackintConfigT{
+				"region":                  "eu-de",
+				"secondary_region":        "us-south",
+				"endpoint_type":           ENDPOINT_PUBLIC,
+				"secondary_endpoint_type": ENDPOINT_PRIVATE,
+			},
+			wantPrimary:   "https://s3.eu-de.cloud-object-storage.appdomain.cloud",
+			wantSecondary: "https://s3.private.us-south.cloud-object-storage.appdomain.cloud",
+		},
+		{
+			name: "without endpoint type",
+			config: BackintConfigT{
+				"region":       "eu-de",
+				"endpoint_url": "https://cos.example.com",
+			},
+			wantPrimary: "https://cos.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := updateConfigWithEndpoints(tt.config)
+			if got := updated.EndpointUrl(); got != tt.wantPrimary {
+				t.Errorf("endpoint_url = %q, want %q", got, tt.wantPrimary)
+			}
+			if got := updated.SecondaryEndpointUrl(); got != tt.wantSecondary {
+				t.Errorf("secondary_endpoint_url = %q, want %q", got, tt.wantSecondary)
+			}
+		})
+	}
+}
@@ -23,9 +23,11 @@ import (
 )
 
 /*
-Reading the input file content
+Reading the input file content of a given function.
+Lines without keyword are file names of a backup,
+they are ignored for all other functions.
 */
-func ReadInputFile(filePath string) []global.InputFileContentT {
+func ReadInputFile(filePath string, function string) []global.InputFileContentT {
 	f, err := os.Open(filePath)
 	if err != nil {
 		return nil
@@ -40,11 +42,25 @@ func ReadInputFile(filePath string) []global.InputFileContentT {
 	fScanner := bufio.NewScanner(f)
 	for fScanner.Scan() {
 		line := fScanner.Text()
-		if !strings.HasPrefix(line, "#") {
+		if strings.TrimSpace(line) == "" {
 			// Ignore line
 			continue
 		}
 
+		if !strings.HasPrefix(line, "#") {
+			if function != global.BACKUP {
+				// Ignore line
+				continue
+			}
+			// Lines without keyword are file names, e.g. for catalog backups
+			inputFileContentList = append(inputFileContentList,
+				global.InputFileContentT{
+					Keyword:   "FILE",
+					Parameter: strings.ReplaceAll(line, "\"", ""),
+				})
+			continue
+		}
+
 		if strings.HasPrefix(strings.ToUpper(line), "#SOFTWAREID") {
 			// Ignore line
 			continue
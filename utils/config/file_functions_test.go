@@ -0,0 +1,114 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+)
+
+/*
+Writing an input file for one test
+*/
+func writeInputFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "input")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadInputFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		function string
+		content  string
+		want     []global.InputFileContentT
+	}{
+		{
+			name:     "backup of pipes and files",
+			function: global.BACKUP,
+			content: "#SOFTWAREID \"backint 1.04\" \"HANA HDB server 2.00\"\n" +
+				"#PIPE /usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_0_1\n" +
+				"\"/usr/sap/HDB/HDB00/backup/log/catalog_backup\"\n" +
+				"\n" +
+				"#PIPE \"/usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_1_1\" 1024\n",
+			want: []global.InputFileContentT{
+				{Keyword: "PIPE", Parameter: "/usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_0_1"},
+				{Keyword: "FILE", Parameter: "/usr/sap/HDB/HDB00/backup/log/catalog_backup"},
+				{Keyword: "PIPE", Parameter: "/usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_1_1 1024"},
+			},
+		},
+		{
+			name:     "restore ignoring lines without keyword",
+			function: global.RESTORE,
+			content: "#SOFTWAREID \"backint 1.04\"\n" +
+				"#EBID \"1234\" \"/usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_0_1\"\n" +
+				"/usr/sap/HDB/HDB00/backup/log/catalog_backup\n" +
+				"#NULL \"/usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_1_1\" \"/tmp/restore/file\"\n",
+			want: []global.InputFileContentT{
+				{Keyword: "EBID", Parameter: "1234 /usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_0_1"},
+				{Keyword: "NULL", Parameter: "/usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_1_1 /tmp/restore/file"},
+			},
+		},
+		{
+			name:     "delete ignoring lines without keyword",
+			function: global.DELETE,
+			content: "#EBID \"1234\" \"/usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_0_1\"\n" +
+				"/usr/sap/HDB/HDB00/backup/log/catalog_backup\n",
+			want: []global.InputFileContentT{
+				{Keyword: "EBID", Parameter: "1234 /usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_0_1"},
+			},
+		},
+		{
+			name:     "inquire ignoring lines without keyword",
+			function: global.INQUIRE,
+			content:  "/usr/sap/HDB/HDB00/backup/log/catalog_backup\n#NULL\n",
+			want: []global.InputFileContentT{
+				{Keyword: "NULL"},
+			},
+		},
+		{
+			name:     "restore of lines without keyword only",
+			function: global.RESTORE,
+			content:  "/usr/sap/HDB/HDB00/backup/log/catalog_backup\n",
+			want:     nil,
+		},
+		{
+			name:     "empty file",
+			function: global.BACKUP,
+			content:  "\n  \n",
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ReadInputFile(writeInputFile(t, tt.content), tt.function)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadInputFile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := ReadInputFile(filepath.Join(t.TempDir(), "missing"), global.BACKUP); got != nil {
+		t.Errorf("ReadInputFile() of a missing file = %+v, want nil", got)
+	}
+}
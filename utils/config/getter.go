@@ -15,10 +15,12 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
 
 	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
 
+	"strconv"
 	"strings"
 	"time"
 )
@@ -59,6 +61,20 @@ func (b BackintConfigT) Apikey() string {
 	return b.Get("apikey")
 }
 
+/*
+Getting the helper command printing the apikey
+*/
+func (b BackintConfigT) ApikeyCommand() string {
+	return b.Get("apikey_command")
+}
+
+/*
+Getting the name of the environment variable containing the apikey
+*/
+func (b BackintConfigT) ApikeyEnv() string {
+	return b.Get("apikey_env")
+}
+
 /*
 Getting the path to the apikey file
 */
@@ -73,6 +89,13 @@ func (b BackintConfigT) AuthMethod() string {
 	return b.Get("auth_mode")
 }
 
+/*
+Getting the daily time range the bandwidth limits are applied
+*/
+func (b BackintConfigT) BandwidthSchedule() string {
+	return b.Get("bandwidth_schedule")
+}
+
 /*
 Getting the bucket name
 */
@@ -81,12 +104,162 @@ func (b BackintConfigT) BucketName() string {
 }
 
 /*
-Getting the endpoint url
+Getting the path to the file containing additional CA certificates
+*/
+func (b BackintConfigT) CaBundle() string {
+	return b.Get("ca_bundle")
+}
+
+/*
+Getting the path to the client certificate file
+*/
+func (b BackintConfigT) ClientCert() string {
+	return b.Get("client_cert")
+}
+
+/*
+Getting the path to the private key file of the client certificate
+*/
+func (b BackintConfigT) ClientKey() string {
+	return b.Get("client_key")
+}
+
+/*
+Getting the algorithm for streaming compression
+*/
+func (b BackintConfigT) Compression() string {
+	return b.Get("compression")
+}
+
+/*
+Getting the level for streaming compression
+*/
+func (b BackintConfigT) CompressionLevel() int {
+	return global.ToInteger(b.Get("compression_level"))
+}
+
+/*
+Getting the timeout for establishing a connection
+*/
+func (b BackintConfigT) ConnectTimeout() time.Duration {
+	return time.Duration(global.ToInteger(b.Get("connect_timeout"))) * time.Second
+}
+
+/*
+Getting the path to the compute resource token file
+*/
+func (b BackintConfigT) CrTokenPath() string {
+	return b.Get("cr_token_path")
+}
+
+/*
+Getting the size of the byte ranges an object is downloaded with
+*/
+func (b BackintConfigT) DownloadRangeSize() int64 {
+	return int64(global.ToInteger(b.Get("download_range_size")))
+}
+
+/*
+Getting the time to wait for the response to "Expect: 100-continue"
+*/
+func (b BackintConfigT) ExpectContinueTimeout() time.Duration {
+	return time.Duration(global.ToInteger(b.Get("expect_continue_timeout"))) * time.Second
+}
+
+/*
+Getting the algorithm for client-side encryption
+*/
+func (b BackintConfigT) EncryptionAlgorithm() string {
+	return b.Get("encryption_algorithm")
+}
+
+/*
+Getting the master key for client-side encryption
+*/
+func (b BackintConfigT) EncryptionKey() []byte {
+	masterKey, _ := base64.StdEncoding.DecodeString(b.Get("encryption_key"))
+	return masterKey
+}
+
+/*
+Getting the id of the master key for client-side encryption
+*/
+func (b BackintConfigT) EncryptionKeyId() string {
+	return b.Get("encryption_key_id")
+}
+
+/*
+Getting the path to the master key file for client-side encryption
+*/
+func (b BackintConfigT) EncryptionKeypath() string {
+	return b.Get("encryption_keypath")
+}
+
+/*
+Getting the pathname of the local endpoint table
+*/
+func (b BackintConfigT) EndpointTable() string {
+	return b.Get("endpoint_table")
+}
+
+/*
+Getting the type of the endpoint derived from the region
+*/
+func (b BackintConfigT) EndpointType() string {
+	return b.Get("endpoint_type")
+}
+
+/*
+Getting the endpoint url, derived from the region
+if only the endpoint type is specified
 */
 func (b BackintConfigT) EndpointUrl() string {
 	return b.Get("endpoint_url")
 }
 
+/*
+Getting the expected maximum size of one pipe in bytes
+*/
+func (b BackintConfigT) ExpectedPipeSize() int64 {
+	return int64(global.ToInteger(b.Get("expected_pipe_size")))
+}
+
+/*
+Returns true if HTTP/2 is not used
+*/
+func (b BackintConfigT) ForceHTTP1() bool {
+	force, _ := strconv.ParseBool(b.Get("force_http1"))
+	return force
+}
+
+/*
+Getting the HMAC access key id
+*/
+func (b BackintConfigT) HmacAccessKeyId() string {
+	return b.Get("hmac_access_key_id")
+}
+
+/*
+Getting the path to the file containing the HMAC keys
+*/
+func (b BackintConfigT) HmacKeypath() string {
+	return b.Get("hmac_keypath")
+}
+
+/*
+Getting the HMAC secret access key
+*/
+func (b BackintConfigT) HmacSecretAccessKey() string {
+	return b.Get("hmac_secret_access_key")
+}
+
+/*
+Getting the time an idle connection is kept open
+*/
+func (b BackintConfigT) IdleConnTimeout() time.Duration {
+	return time.Duration(global.ToInteger(b.Get("idle_conn_timeout"))) * time.Second
+}
+
 /*
 Getting the IBM Authorization endpoint
 */
@@ -94,6 +267,71 @@ func (b BackintConfigT) IBMAuthEndpoint() string {
 	return b.Get("ibm_auth_endpoint")
 }
 
+/*
+Returns true if the object storage is S3 compatible, not IBM Cloud
+*/
+func (b BackintConfigT) IsS3Compatible() bool {
+	return b.Provider() == PROVIDER_S3_COMPATIBLE
+}
+
+/*
+Returns true if authentication uses HMAC keys
+*/
+func (b BackintConfigT) IsHmacEnabled() bool {
+	return b.AuthMethod() == AUTH_HMAC
+}
+
+/*
+Returns true if streaming compression is switched on
+*/
+func (b BackintConfigT) IsCompressionEnabled() bool {
+	return b.Compression() != "" &&
+		b.Compression() != COMPRESSION_NONE
+}
+
+/*
+Returns true if client-side encryption is switched on
+*/
+func (b BackintConfigT) IsEncryptionEnabled() bool {
+	return b.EncryptionAlgorithm() != "" &&
+		b.EncryptionAlgorithm() != ENCRYPTION_NONE
+}
+
+/*
+Returns true if the object keys are generated from the key template
+*/
+func (b BackintConfigT) IsKeyTemplateEnabled() bool {
+	return b.KeyTemplate() != ""
+}
+
+/*
+Getting the interval of the TCP keep-alive probes
+*/
+func (b BackintConfigT) KeepaliveInterval() time.Duration {
+	return time.Duration(global.ToInteger(b.Get("keepalive_interval"))) * time.Second
+}
+
+/*
+Getting the template for the object keys
+*/
+func (b BackintConfigT) KeyTemplate() string {
+	return b.Get("key_template")
+}
+
+/*
+Getting the key prefix for the backup manifests
+*/
+func (b BackintConfigT) ManifestKeyPrefix() string {
+	return b.Get("manifest_key_prefix")
+}
+
+/*
+Returns true if a manifest is written for every backup
+*/
+func (b BackintConfigT) IsManifestEnabled() bool {
+	return b.ManifestKeyPrefix() != ""
+}
+
 /*
 Getting the maximum concurrency
 */
@@ -101,6 +339,48 @@ func (b BackintConfigT) MaxConcurrency() int {
 	return global.ToInteger(b.Get("max_concurrency"))
 }
 
+/*
+Getting the maximum number of connections to one host
+*/
+func (b BackintConfigT) MaxConnsPerHost() int {
+	return global.ToInteger(b.Get("max_conns_per_host"))
+}
+
+/*
+Getting the maximum number of idle connections kept open to one host
+*/
+func (b BackintConfigT) MaxIdleConnsPerHost() int {
+	return global.ToInteger(b.Get("max_idle_conns_per_host"))
+}
+
+/*
+Getting the maximum number of parts transferred concurrently by all pipes
+*/
+func (b BackintConfigT) MaxInflightParts() int {
+	return global.ToInteger(b.Get("max_inflight_parts"))
+}
+
+/*
+Getting the maximum memory used by parts transferred concurrently by all pipes
+*/
+func (b BackintConfigT) MaxInflightMemory() int64 {
+	return int64(global.ToInteger(b.Get("max_inflight_memory")))
+}
+
+/*
+Getting the maximum download bandwidth in bytes per second
+*/
+func (b BackintConfigT) MaxDownloadBandwidth() int64 {
+	return int64(global.ToInteger(b.Get("max_download_bandwidth")))
+}
+
+/*
+Getting the maximum upload bandwidth in bytes per second
+*/
+func (b BackintConfigT) MaxUploadBandwidth() int64 {
+	return int64(global.ToInteger(b.Get("max_upload_bandwidth")))
+}
+
 /*
 Getting the multipart chunksize
 */
@@ -147,6 +427,20 @@ func (b BackintConfigT) ObjectLockRetentionPeriod() string {
 	return b.Get("object_lock_retention_period")
 }
 
+/*
+Getting the provider of the object storage
+*/
+func (b BackintConfigT) Provider() string {
+	return b.Get("provider")
+}
+
+/*
+Getting the URL of the proxy all requests are sent through
+*/
+func (b BackintConfigT) ProxyUrl() string {
+	return b.Get("proxy_url")
+}
+
 /*
 Getting the region
 */
@@ -161,6 +455,157 @@ func (b BackintConfigT) RemoveKeyPrefix() string {
 	return b.Get("remove_key_prefix")
 }
 
+/*
+Getting the time to wait for the response headers after a request is sent
+*/
+func (b BackintConfigT) ResponseHeaderTimeout() time.Duration {
+	return time.Duration(global.ToInteger(b.Get("response_header_timeout"))) * time.Second
+}
+
+/*
+Getting the memory available for reordering the parts of all restored objects
+*/
+func (b BackintConfigT) RestoreBufferMemory() int64 {
+	return int64(global.ToInteger(b.Get("restore_buffer_memory")))
+}
+
+/*
+Getting the list of error classes which are retried
+*/
+func (b BackintConfigT) RetryErrorClasses() []string {
+	var classes []string
+	for _, class := range strings.Split(b.Get("retry_error_classes"), ",") {
+		if class = strings.TrimSpace(class); class != "" {
+			classes = append(classes, class)
+		}
+	}
+	return classes
+}
+
+/*
+Getting the delay before the first retry
+*/
+func (b BackintConfigT) RetryBaseDelay() time.Duration {
+	return time.Duration(global.ToInteger(b.Get("retry_base_delay"))) * time.Millisecond
+}
+
+/*
+Getting the jitter applied to the delay between two retries
+*/
+func (b BackintConfigT) RetryJitter() string {
+	return b.Get("retry_jitter")
+}
+
+/*
+Getting the maximum number of attempts of one request
+*/
+func (b BackintConfigT) RetryMaxAttempts() int {
+	return global.ToInteger(b.Get("retry_max_attempts"))
+}
+
+/*
+Getting the maximum delay between two retries
+*/
+func (b BackintConfigT) RetryMaxDelay() time.Duration {
+	return time.Duration(global.ToInteger(b.Get("retry_max_delay"))) * time.Millisecond
+}
+
+/*
+Getting the apikey of the secondary storage
+*/
+func (b BackintConfigT) SecondaryApikey() string {
+	return b.Get("secondary_apikey")
+}
+
+/*
+Getting the helper command printing the apikey of the secondary storage
+*/
+func (b BackintConfigT) SecondaryApikeyCommand() string {
+	return b.Get("secondary_apikey_command")
+}
+
+/*
+Getting the name of the environment variable containing
+the apikey of the secondary storage
+*/
+func (b BackintConfigT) SecondaryApikeyEnv() string {
+	return b.Get("secondary_apikey_env")
+}
+
+/*
+Getting the path of the file containing the apikey of the secondary storage
+*/
+func (b BackintConfigT) SecondaryAuthKeypath() string {
+	return b.Get("secondary_auth_keypath")
+}
+
+/*
+Getting the bucket name of the secondary storage
+*/
+func (b BackintConfigT) SecondaryBucketName() string {
+	return b.Get("secondary_bucket")
+}
+
+/*
+Getting the endpoint url of the secondary storage
+*/
+func (b BackintConfigT) SecondaryEndpointUrl() string {
+	return b.Get("secondary_endpoint_url")
+}
+
+/*
+Getting the HMAC access key id of the secondary storage
+*/
+func (b BackintConfigT) SecondaryHmacAccessKeyId() string {
+	return b.Get("secondary_hmac_access_key_id")
+}
+
+/*
+Getting the path of the file containing the HMAC keys of the secondary storage
+*/
+func (b BackintConfigT) SecondaryHmacKeypath() string {
+	return b.Get("secondary_hmac_keypath")
+}
+
+/*
+Getting the HMAC secret access key of the secondary storage
+*/
+func (b BackintConfigT) SecondaryHmacSecretAccessKey() string {
+	return b.Get("secondary_hmac_secret_access_key")
+}
+
+/*
+Getting the IAM endpoint url of the secondary storage
+*/
+func (b BackintConfigT) SecondaryIBMAuthEndpoint() string {
+	return b.Get("secondary_ibm_auth_endpoint")
+}
+
+/*
+Getting the type of the endpoint derived from the secondary region.
+If not specified, the endpoint type of the primary storage is used.
+*/
+func (b BackintConfigT) SecondaryEndpointType() string {
+	if endpointType := b.Get("secondary_endpoint_type"); endpointType != "" {
+		return endpointType
+	}
+	return b.EndpointType()
+}
+
+/*
+Getting the region of the secondary storage
+*/
+func (b BackintConfigT) SecondaryRegion() string {
+	return b.Get("secondary_region")
+}
+
+/*
+Returns true if backups are written to a secondary storage as well
+*/
+func (b BackintConfigT) IsSecondaryStorageEnabled() bool {
+	return b.SecondaryBucketName() != ""
+}
+
 /*
 Getting the service Instance Id
 */
@@ -179,9 +624,52 @@ func (b BackintConfigT) Tags() string {
 	return tags
 }
 
+/*
+Getting the object tags for a given pipe.
+The placeholders are replaced with the values of the current backup.
+*/
+func (b BackintConfigT) TagsForPipe(pipeName string) string {
+	return expandTagPlaceholders(b.Tags(), pipeName)
+}
+
 /*
 Getting the timeout
 */
 func (b BackintConfigT) Timeout() int {
 	return global.ToInteger(b.Get("timeout_microsecond"))
 }
+
+/*
+Getting the timeout of the TLS handshake
+*/
+func (b BackintConfigT) TLSHandshakeTimeout() time.Duration {
+	return time.Duration(global.ToInteger(b.Get("tls_handshake_timeout"))) * time.Second
+}
+
+/*
+Getting the minimum TLS version
+*/
+func (b BackintConfigT) TLSMinVersion() string {
+	return b.Get("tls_min_version")
+}
+
+/*
+Getting the id of the trusted profile
+*/
+func (b BackintConfigT) TrustedProfileId() string {
+	return b.Get("trusted_profile_id")
+}
+
+/*
+Returns true if authentication uses a trusted profile
+*/
+func (b BackintConfigT) IsTrustedProfileEnabled() bool {
+	return b.AuthMethod() == AUTH_TRUSTED_PROFILE
+}
+
+/*
+Getting the number of storages a backup must be written to successfully
+*/
+func (b BackintConfigT) WriteQuorum() int {
+	return global.ToInteger(b.Get("write_quorum"))
+}
@@ -0,0 +1,208 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+)
+
+// Pipe names created by SAP HANA: /usr/sap/<SID>/SYS/global/hdb/backint/<DB>/<name>
+var pipePathRegex = regexp.MustCompile(`^/usr/sap/([^/]+)/SYS/global/hdb/backint/([^/]+)/`)
+
+// Placeholders in object tags: ${NAME}
+var tagPlaceholderRegex = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+/*
+Parsing a key template into literals and placeholders.
+Placeholders have the format {NAME} or {NAME:argument}.
+*/
+func ParseKeyTemplate(template string) ([]KeyTemplateElement, error) {
+	var elements []KeyTemplateElement
+	rest := template
+	for rest != "" {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			elements = append(elements, KeyTemplateElement{Literal: rest})
+			break
+		}
+		if start > 0 {
+			elements = append(elements, KeyTemplateElement{Literal: rest[:start]})
+		}
+
+		end := strings.Index(rest[start:], "}")
+		if end < 0 {
+			return nil, fmt.Errorf("missing '}' in '%s'", rest[start:])
+		}
+		name, argument, hasArgument := strings.Cut(rest[start+1:start+end], ":")
+		if !slices.Contains(validKeyTemplatePlaceholders, name) {
+			return nil, fmt.Errorf("unknown placeholder '{%s}'", name)
+		}
+		if name == PLACEHOLDER_DATE && (!hasArgument || argument == "") {
+			return nil, fmt.Errorf("placeholder '{%s}' needs a date layout, e.g. '{%s:2006/01/02}'",
+				name, name,
+			)
+		}
+		if name != PLACEHOLDER_DATE && hasArgument {
+			return nil, fmt.Errorf("placeholder '{%s}' does not take an argument", name)
+		}
+
+		elements = append(elements, KeyTemplateElement{
+			Placeholder: name,
+			Argument:    argument,
+		})
+		rest = rest[start+end+1:]
+	}
+	return elements, nil
+}
+
+/*
+Getting the SAP system id from the name of a pipe created by SAP HANA
+Returns an empty string if the pipe name does not follow the HANA naming
+*/
+func SidFromPipeName(pipeName string) string {
+	matches := pipePathRegex.FindStringSubmatch(pipeName)
+	if matches == nil {
+		return ""
+	}
+	return matches[1]
+}
+
+/*
+Getting the tenant database name from the name of a pipe created by SAP HANA.
+HANA uses the directory DB_<tenant> for tenant databases
+and SYSTEMDB for the system database.
+*/
+func TenantFromPipeName(pipeName string) string {
+	matches := pipePathRegex.FindStringSubmatch(pipeName)
+	if matches == nil {
+		return ""
+	}
+	tenant, _ := strings.CutPrefix(matches[2], "DB_")
+	return tenant
+}
+
+/*
+Getting the base name of a pipe
+*/
+func PipeBasename(pipeName string) string {
+	return filepath.Base(pipeName)
+}
+
+/*
+Checking that all placeholders used in the object tags are known
+*/
+func validateTagPlaceholders(tags string) error {
+	for _, match := range tagPlaceholderRegex.FindAllStringSubmatch(tags, -1) {
+		if !slices.Contains(validTagPlaceholders, match[1]) {
+			return fmt.Errorf("unknown placeholder '${%s}'", match[1])
+		}
+	}
+	// Any "${" left after removing the valid placeholders is not closed
+	if strings.Contains(tagPlaceholderRegex.ReplaceAllString(tags, ""), "${") {
+		return fmt.Errorf("missing '}' in placeholder")
+	}
+	return nil
+}
+
+/*
+Checking the length of the keys and values of the object tags.
+Keys must not contain placeholders. The length of a value is checked
+without its placeholders, which are cut on expansion.
+*/
+func validateTagLengths(tags string) error {
+	for _, tag := range strings.Split(tags, ",") {
+		key, value, _ := strings.Cut(tag, "=")
+		if tagPlaceholderRegex.MatchString(key) {
+			return fmt.Errorf("placeholder in the key '%s'", key)
+		}
+		if utf8.RuneCountInString(unescapeTag(key)) > MAX_TAG_KEY_LENGTH {
+			return fmt.Errorf("key '%s' longer than %d characters", key, MAX_TAG_KEY_LENGTH)
+		}
+		literal := tagPlaceholderRegex.ReplaceAllString(value, "")
+		if utf8.RuneCountInString(unescapeTag(literal)) > MAX_TAG_VALUE_LENGTH {
+			return fmt.Errorf("value of the key '%s' longer than %d characters", key, MAX_TAG_VALUE_LENGTH)
+		}
+	}
+	return nil
+}
+
+/*
+Replacing the placeholders of the object tags
+with the values of the current backup for a given pipe.
+The tags are separated by '&', a value exceeding the maximum
+length after the expansion is cut.
+*/
+func expandTagPlaceholders(tags string, pipeName string) string {
+	expanded := strings.Split(tags, "&")
+	for i, tag := range expanded {
+		key, value, found := strings.Cut(tag, "=")
+		if !found || !tagPlaceholderRegex.MatchString(value) {
+			continue
+		}
+		value = tagPlaceholderRegex.ReplaceAllStringFunc(value, func(match string) string {
+			name := tagPlaceholderRegex.FindStringSubmatch(match)[1]
+			return url.QueryEscape(getTagPlaceholderValue(name, pipeName))
+		})
+		if decoded := []rune(unescapeTag(value)); len(decoded) > MAX_TAG_VALUE_LENGTH {
+			value = url.QueryEscape(string(decoded[:MAX_TAG_VALUE_LENGTH]))
+		}
+		expanded[i] = key + "=" + value
+	}
+	return strings.Join(expanded, "&")
+}
+
+/*
+Decoding a key or value of the object tags
+Returns the string unchanged if it is not encoded correctly
+*/
+func unescapeTag(s string) string {
+	decoded, err := url.QueryUnescape(s)
+	if err != nil {
+		return s
+	}
+	return decoded
+}
+
+/*
+Getting the value of one placeholder of the object tags
+*/
+func getTagPlaceholderValue(name string, pipeName string) string {
+	switch name {
+	case TAG_PLACEHOLDER_BACKUP_LEVEL:
+		return global.Args.BackupLevel
+	case TAG_PLACEHOLDER_BACKUP_ID:
+		return strconv.Itoa(global.Args.BackupId)
+	case TAG_PLACEHOLDER_HOSTNAME:
+		hostname, _ := os.Hostname()
+		return hostname
+	case TAG_PLACEHOLDER_SID:
+		return SidFromPipeName(pipeName)
+	case TAG_PLACEHOLDER_TENANT:
+		return TenantFromPipeName(pipeName)
+	case TAG_PLACEHOLDER_USER:
+		return global.Args.UserId
+	}
+	return ""
+}
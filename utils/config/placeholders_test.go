@@ -0,0 +1,123 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseKeyTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     []KeyTemplateElement
+		wantErr  string
+	}{
+		{
+			name:     "literal only",
+			template: "backups/hana",
+			want:     []KeyTemplateElement{{Literal: "backups/hana"}},
+		},
+		{
+			name:     "placeholders and literals",
+			template: "hana/{SID}/{TENANT}-{PIPE_BASENAME}",
+			want: []KeyTemplateElement{
+				{Literal: "hana/"},
+				{Placeholder: PLACEHOLDER_SID},
+				{Literal: "/"},
+				{Placeholder: PLACEHOLDER_TENANT},
+				{Literal: "-"},
+				{Placeholder: PLACEHOLDER_PIPE_BASENAME},
+			},
+		},
+		{
+			name:     "date with layout",
+			template: "{DATE:2006/01/02}{BACKUP_ID}",
+			want: []KeyTemplateElement{
+				{Placeholder: PLACEHOLDER_DATE, Argument: "2006/01/02"},
+				{Placeholder: PLACEHOLDER_BACKUP_ID},
+			},
+		},
+		{
+			name:     "unclosed placeholder",
+			template: "{SID}/{TENANT",
+			wantErr:  "missing '}'",
+		},
+		{
+			name:     "unknown placeholder",
+			template: "{SID}/{tenant}",
+			wantErr:  "unknown placeholder '{tenant}'",
+		},
+		{
+			name:     "date without layout",
+			template: "{DATE}",
+			wantErr:  "needs a date layout",
+		},
+		{
+			name:     "date with empty layout",
+			template: "{DATE:}",
+			wantErr:  "needs a date layout",
+		},
+		{
+			name:     "argument for other placeholder",
+			template: "{SID:x}",
+			wantErr:  "does not take an argument",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseKeyTemplate(tt.template)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseKeyTemplate(%q) error = %v, want %q", tt.template, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseKeyTemplate(%q) = %+v, want %+v", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPipeNameParts(t *testing.T) {
+	tests := []struct {
+		pipe       string
+		wantSid    string
+		wantTenant string
+		wantBase   string
+	}{
+		{"/usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_0_1", "HDB", "TEN", "databackup_0_1"},
+		{"/usr/sap/HDB/SYS/global/hdb/backint/SYSTEMDB/log_backup_0_0_0_0", "HDB", "SYSTEMDB", "log_backup_0_0_0_0"},
+		{"/tmp/backup/pipe", "", "", "pipe"},
+	}
+	for _, tt := range tests {
+		if got := SidFromPipeName(tt.pipe); got != tt.wantSid {
+			t.Errorf("SidFromPipeName(%q) = %q, want %q", tt.pipe, got, tt.wantSid)
+		}
+		if got := TenantFromPipeName(tt.pipe); got != tt.wantTenant {
+			t.Errorf("TenantFromPipeName(%q) = %q, want %q", tt.pipe, got, tt.wantTenant)
+		}
+		if got := PipeBasename(tt.pipe); got != tt.wantBase {
+			t.Errorf("PipeBasename(%q) = %q, want %q", tt.pipe, got, tt.wantBase)
+		}
+	}
+}
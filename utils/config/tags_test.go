@@ -0,0 +1,165 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+)
+
+const tagPipe = "/usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_0_1"
+
+/*
+Setting the backup context used by the tag placeholders for one test
+*/
+func setupTagContext(t *testing.T, level string, backupId int, user string) {
+	t.Helper()
+	previousArgs := global.Args
+	global.Args.BackupLevel = level
+	global.Args.BackupId = backupId
+	global.Args.UserId = user
+	t.Cleanup(func() { global.Args = previousArgs })
+}
+
+/*
+Validating the object tags and getting the error messages
+*/
+func validateTags(t *testing.T, tags string) []string {
+	t.Helper()
+	invalidValues = nil
+	t.Cleanup(func() { invalidValues = nil })
+
+	Default{key: "object_tags", configValue: tags}.validateTag()
+	var errors []string
+	for _, invalid := range invalidValues {
+		errors = append(errors, invalid.errorMessage)
+	}
+	return errors
+}
+
+/*
+Generating the given number of tags
+*/
+func generateTags(count int, value string) string {
+	tags := make([]string, count)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("tag%d=%s", i, value)
+	}
+	return strings.Join(tags, ",")
+}
+
+func TestValidateTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    string
+		wantErr string
+	}{
+		{"literal tags", "team=basis,env=prod", ""},
+		{"known placeholders", "level=${BACKUP_LEVEL},id=${BACKUP_ID},sid=${SID}-${TENANT}", ""},
+		{"maximum number of tags", generateTags(MAX_NUMBER_OF_TAGS, "${BACKUP_LEVEL}"), ""},
+		{"too many tags", generateTags(MAX_NUMBER_OF_TAGS+1, "x"), "must not exceed '10'"},
+		{"missing value", "team", "format of the tag is wrong"},
+		{"unknown placeholder", "level=${LEVEL}", "unknown placeholder '${LEVEL}'"},
+		{"empty placeholder", "level=${}", "unknown placeholder '${}'"},
+		{"unclosed placeholder", "level=${BACKUP_LEVEL", "missing '}'"},
+		{"placeholder in the key", "${SID}=x", "placeholder in the key"},
+		{"maximum key length", strings.Repeat("k", MAX_TAG_KEY_LENGTH) + "=x", ""},
+		{"key too long", strings.Repeat("k", MAX_TAG_KEY_LENGTH+1) + "=x", "longer than 128 characters"},
+		{"maximum value length", "k=" + strings.Repeat("v", MAX_TAG_VALUE_LENGTH), ""},
+		{"value too long", "k=" + strings.Repeat("v", MAX_TAG_VALUE_LENGTH+1), "longer than 256 characters"},
+		{"encoded value at the maximum length", "k=" + strings.Repeat("%C3%A4", MAX_TAG_VALUE_LENGTH), ""},
+		{"placeholders not counted", "k=" + strings.Repeat("v", MAX_TAG_VALUE_LENGTH) + "${HOSTNAME}", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := validateTags(t, tt.tags)
+			if tt.wantErr == "" && len(errors) > 0 {
+				t.Fatalf("validateTag(%q) errors = %q, want none", tt.tags, errors)
+			}
+			if tt.wantErr != "" && (len(errors) != 1 || !strings.Contains(errors[0], tt.wantErr)) {
+				t.Errorf("validateTag(%q) errors = %q, want one containing %q", tt.tags, errors, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpandTagPlaceholders(t *testing.T) {
+	hostname, _ := os.Hostname()
+	longUser := strings.Repeat("u", MAX_TAG_VALUE_LENGTH)
+
+	tests := []struct {
+		name  string
+		tags  string
+		level string
+		user  string
+		want  string
+	}{
+		{"without placeholders", "team=basis&env=prod", "COMPLETE_DATA_BACKUP", "SYSTEM", "team=basis&env=prod"},
+		{"all placeholders", "l=${BACKUP_LEVEL}&i=${BACKUP_ID}&h=${HOSTNAME}&s=${SID}&t=${TENANT}&u=${USER}",
+			"COMPLETE_DATA_BACKUP", "SYSTEM",
+			"l=COMPLETE_DATA_BACKUP&i=42&h=" + url.QueryEscape(hostname) + "&s=HDB&t=TEN&u=SYSTEM"},
+		{"placeholders and literals", "db=${SID}-${TENANT}", "LOG", "SYSTEM", "db=HDB-TEN"},
+		{"separators in values escaped", "u=${USER}&team=basis", "LOG", "A&B=C,D", "u=A%26B%3DC%2CD&team=basis"},
+		{"value cut to the maximum length", "u=x${USER}", "LOG", longUser, "u=x" + longUser[:MAX_TAG_VALUE_LENGTH-1]},
+		{"multibyte value cut by characters", "u=${USER}", "LOG", strings.Repeat("ä", MAX_TAG_VALUE_LENGTH+1),
+			"u=" + url.QueryEscape(strings.Repeat("ä", MAX_TAG_VALUE_LENGTH))},
+		{"empty tags", "", "LOG", "SYSTEM", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTagContext(t, tt.level, 42, tt.user)
+			got := expandTagPlaceholders(tt.tags, tagPipe)
+			if got != tt.want {
+				t.Errorf("expandTagPlaceholders(%q) = %q, want %q", tt.tags, got, tt.want)
+			}
+
+			// The number of tags does not change and all values fit
+			if tt.tags == "" {
+				return
+			}
+			tags := strings.Split(got, "&")
+			if len(tags) != strings.Count(tt.tags, "&")+1 {
+				t.Errorf("%d tags after the expansion of %q", len(tags), tt.tags)
+			}
+			for _, tag := range tags {
+				_, value, _ := strings.Cut(tag, "=")
+				decoded, err := url.QueryUnescape(value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if utf8.RuneCountInString(decoded) > MAX_TAG_VALUE_LENGTH {
+					t.Errorf("value of %d characters after the expansion", utf8.RuneCountInString(decoded))
+				}
+			}
+		})
+	}
+}
+
+func TestTagsForPipe(t *testing.T) {
+	setupTagContext(t, "COMPLETE_DATA_BACKUP", 7, "SYSTEM")
+	config := BackintConfigT{"object_tags": "level=${BACKUP_LEVEL},tenant=${TENANT},team=basis"}
+	want := "level=COMPLETE_DATA_BACKUP&tenant=TEN&team=basis"
+	if got := config.TagsForPipe(tagPipe); got != want {
+		t.Errorf("TagsForPipe() = %q, want %q", got, want)
+	}
+}
@@ -43,3 +43,35 @@ type Default struct {
 
 // Datatype representing one single backint configuration value
 type BackintConfigT map[string]string
+
+// Datatype representing one literal or placeholder of the object key template
+type KeyTemplateElement struct {
+	Literal     string
+	Placeholder string
+	Argument    string
+}
+
+// Datatype representing a source the apikey is read from
+type apikeySource interface {
+	read() (string, error)
+	String() string
+}
+
+// Datatype representing a plain or JSON file containing the apikey
+type apikeyFile struct {
+	path string
+}
+
+// Datatype representing an environment variable containing the apikey
+type apikeyEnv struct {
+	name string
+}
+
+// Datatype representing a helper command printing the apikey
+type apikeyCommand struct {
+	command string
+}
+
+// Datatype representing the endpoints of all regions,
+// the URL of each endpoint type per region
+type endpointTable map[string]map[string]string
@@ -15,8 +15,11 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"slices"
 	"strconv"
@@ -64,6 +67,7 @@ func validateConfig(basicConfig []Default) []InvalidValue {
 	if validateConfigTypes(basicConfig) != nil {
 		os.Exit(global.WRONG_PARAMETER)
 	}
+	basicConfig = adaptToProvider(basicConfig)
 
 	// Validating the mandatory parameters
 	if global.Args.CheckParms {
@@ -89,6 +93,28 @@ func validateConfig(basicConfig []Default) []InvalidValue {
 	return invalidValues
 }
 
+/*
+Adapting the validation to the provider.
+S3 compatible storages accept any region and any http or https endpoint.
+*/
+func adaptToProvider(basicConfig []Default) []Default {
+	if getObjForKey(basicConfig, "provider").configValue != PROVIDER_S3_COMPATIBLE {
+		return basicConfig
+	}
+	adapted := make([]Default, len(basicConfig))
+	for i, cp := range basicConfig {
+		switch cp.key {
+		case "region", "secondary_region":
+			cp.validationType = CONFIG_STRING
+			cp.possibleValues = nil
+		case "endpoint_url", "secondary_endpoint_url":
+			cp.validationType = CONFIG_HTTP_URL
+		}
+		adapted[i] = cp
+	}
+	return adapted
+}
+
 /*
 Validating the sections
 */
@@ -240,10 +266,18 @@ func (cp Default) validateParameter() {
 
 /*
 Validating special settings:
-lock retention belong to more than one parameter
+authentication, endpoints, lock retention, compression, encryption
+the secondary storage and the network belong to more than one parameter
 */
 func validateSpecial(basicConfig []Default) {
+	validateAuthentication(basicConfig)
+	validateEndpoints(basicConfig)
 	validateLockRetention(basicConfig)
+	validateCompression(basicConfig)
+	validateEncryption(basicConfig)
+	validateSecondaryStorage(basicConfig)
+	validateRetry(basicConfig)
+	validateNetwork(basicConfig)
 }
 
 /*
@@ -257,6 +291,8 @@ func (cp Default) validateConfigValue() {
 		cp.validateChunksize()
 	case CONFIG_FILE:
 		cp.validateFile()
+	case CONFIG_HTTP_URL:
+		cp.validateHttpUrl()
 	case CONFIG_INT:
 		cp.validateInt()
 	case CONFIG_LIST:
@@ -269,6 +305,10 @@ func (cp Default) validateConfigValue() {
 		}
 	case CONFIG_TAG:
 		cp.validateTag()
+	case CONFIG_TEMPLATE:
+		cp.validateKeyTemplate()
+	case CONFIG_TIMERANGE:
+		cp.validateTimerange()
 	case CONFIG_URL:
 		cp.validateUrl()
 	}
@@ -470,6 +510,86 @@ func (cp Default) validateTag() {
 			}
 		}
 	}
+	// Validate placeholders replaced per backup
+	if err := validateTagPlaceholders(cp.configValue); err != nil {
+		message := fmt.Sprintf("The tags you specified are not valid: %s."+
+			" Supported placeholders are: '${%s}'",
+			err,
+			strings.Join(validTagPlaceholders, "}', '${"),
+		)
+		cp.addInvalidValueMsg(message)
+		return
+	}
+	// Validate the length of keys and values
+	if err := validateTagLengths(cp.configValue); err != nil {
+		message := fmt.Sprintf("The tags you specified are not valid: %s."+
+			" Keys must not exceed %d and values %d characters,"+
+			" placeholders are only supported in values",
+			err,
+			MAX_TAG_KEY_LENGTH,
+			MAX_TAG_VALUE_LENGTH,
+		)
+		cp.addInvalidValueMsg(message)
+		return
+	}
+	addOkMessage(cp.key)
+}
+
+/*
+Validating the object key template
+*/
+func (cp Default) validateKeyTemplate() {
+	elements, err := ParseKeyTemplate(cp.configValue)
+	if err != nil {
+		cp.addInvalidValueMsg(fmt.Sprintf(
+			"The template you specified is not valid: %s.", err,
+		))
+		return
+	}
+
+	contains := func(placeholder string) bool {
+		return slices.ContainsFunc(elements, func(e KeyTemplateElement) bool {
+			return e.Placeholder == placeholder
+		})
+	}
+
+	// The pipe name and the database are needed to keep the keys unique
+	// and to find the objects on restore, the base names of the pipes
+	// are the same for all databases of a system
+	for _, placeholder := range []string{PLACEHOLDER_PIPE_BASENAME, PLACEHOLDER_TENANT} {
+		if !contains(placeholder) {
+			cp.addInvalidValueMsg(fmt.Sprintf(
+				"The template you specified must contain the placeholder '{%s}'.",
+				placeholder,
+			))
+			return
+		}
+	}
+	addOkMessage(cp.key)
+
+	// Only unique if the bucket is not shared by several systems
+	if !contains(PLACEHOLDER_SID) {
+		addWarningMsg(fmt.Sprintf(
+			"The template of '%s' does not contain the placeholder '{%s}',"+
+				" the keys of systems sharing the bucket may collide.",
+			cp.key,
+			PLACEHOLDER_SID,
+		))
+	}
+}
+
+/*
+Validating a daily time range
+*/
+func (cp Default) validateTimerange() {
+	// time range has the format: "HH:MM-HH:MM"
+	if _, _, err := ParseTimerange(cp.configValue); err != nil {
+		cp.addInvalidValueMsg(
+			"The value you specified does not have the correct format." +
+				" It must be a daily time range with the format 'HH:MM-HH:MM'.",
+		)
+		return
+	}
 	addOkMessage(cp.key)
 }
 
@@ -488,6 +608,22 @@ func (cp Default) validateUrl() {
 	addOkMessage(cp.key)
 }
 
+/*
+Validating an url of any host, starting with http:// or https://
+*/
+func (cp Default) validateHttpUrl() {
+	value, err := url.Parse(cp.configValue)
+	if err != nil ||
+		(value.Scheme != "http" && value.Scheme != "https") ||
+		value.Host == "" {
+		cp.addInvalidValueMsg(
+			"The value must be an URL starting with http:// or https://.",
+		)
+		return
+	}
+	addOkMessage(cp.key)
+}
+
 /*
 Special validation:
 Validating object lock retention
@@ -509,6 +645,376 @@ func validateLockRetention(basicConfig []Default) {
 	}
 }
 
+/*
+Special validation:
+Validating the compression level for the selected algorithm
+*/
+func validateCompression(basicConfig []Default) {
+	if getObjForKey(basicConfig, "compression").configValue != COMPRESSION_LZ4 {
+		return
+	}
+	level := getObjForKey(basicConfig, "compression_level")
+	if level.configValue != "" &&
+		global.ToInteger(level.configValue) > MAX_LZ4_COMPRESSION_LEVEL {
+		message := "ERROR: You specified 'compression = lz4', "
+		message += fmt.Sprintf(
+			"but 'compression_level' is higher than '%d'.",
+			MAX_LZ4_COMPRESSION_LEVEL,
+		)
+		Default{}.addInvalidValueMsg(message)
+	}
+}
+
+/*
+Special validation:
+Validating client-side encryption
+*/
+func validateEncryption(basicConfig []Default) {
+	algorithm := getObjForKey(basicConfig, "encryption_algorithm").configValue
+	if algorithm == "" || algorithm == ENCRYPTION_NONE {
+		return
+	}
+	if getObjForKey(basicConfig, "encryption_keypath").configValue == "" {
+		message := fmt.Sprintf(
+			"ERROR: You specified 'encryption_algorithm = %s', ",
+			algorithm,
+		)
+		message += "but no 'encryption_keypath' is specified."
+		Default{}.addInvalidValueMsg(message)
+	}
+}
+
+/*
+Special validation:
+Validating the authentication, exactly one apikey source is needed
+for apikey, the trusted profile id for trustedprofile
+and the HMAC key file for hmac.
+S3 compatible storages only support hmac and anonymous,
+anonymous access is not supported by IBM Cloud.
+*/
+func validateAuthentication(basicConfig []Default) {
+	authMode := getAuthMode(basicConfig)
+	provider := getObjForKey(basicConfig, "provider").configValue
+	if provider == PROVIDER_S3_COMPATIBLE &&
+		authMode != AUTH_HMAC && authMode != AUTH_ANONYMOUS {
+		message := fmt.Sprintf(
+			"ERROR: You specified 'provider = %s', but 'auth_mode = %s' is not supported. Use '%s' or '%s'.",
+			PROVIDER_S3_COMPATIBLE,
+			authMode,
+			AUTH_HMAC,
+			AUTH_ANONYMOUS,
+		)
+		Default{}.addInvalidValueMsg(message)
+		return
+	}
+	if provider != PROVIDER_S3_COMPATIBLE && authMode == AUTH_ANONYMOUS {
+		message := fmt.Sprintf(
+			"ERROR: 'auth_mode = %s' is only supported with 'provider = %s'.",
+			AUTH_ANONYMOUS,
+			PROVIDER_S3_COMPATIBLE,
+		)
+		Default{}.addInvalidValueMsg(message)
+		return
+	}
+
+	var key string
+	switch authMode {
+	case AUTH_APIKEY:
+		validateApikeySource(basicConfig, apikeySourceKeys, true)
+		validateApikeySource(basicConfig, secondaryApikeySourceKeys, false)
+		return
+	case AUTH_HMAC:
+		key = "hmac_keypath"
+	case AUTH_TRUSTED_PROFILE:
+		validateSecondaryTrustedProfile(basicConfig)
+		key = "trusted_profile_id"
+	default:
+		return
+	}
+	if getObjForKey(basicConfig, key).configValue == "" {
+		message := fmt.Sprintf(
+			"ERROR: You specified 'auth_mode = %s', but no '%s' is specified.",
+			authMode,
+			key,
+		)
+		Default{}.addInvalidValueMsg(message)
+	}
+}
+
+/*
+Validating the authentication of the secondary storage with a trusted profile.
+The trusted profile and the compute resource token of the primary storage
+are used for both storages, keys of the secondary storage are rejected.
+*/
+func validateSecondaryTrustedProfile(basicConfig []Default) {
+	keys := append([]string{"secondary_hmac_keypath"}, secondaryApikeySourceKeys...)
+	specified := getSpecifiedKeys(basicConfig, keys)
+	if len(specified) == 0 {
+		return
+	}
+	message := fmt.Sprintf(
+		"ERROR: You specified 'auth_mode = %s', but '%s' is specified. The trusted profile is used for the secondary storage as well.",
+		AUTH_TRUSTED_PROFILE,
+		strings.Join(specified, "', '"),
+	)
+	Default{}.addInvalidValueMsg(message)
+}
+
+/*
+Validating the sources of one apikey, at most one of them
+may be specified, at least one if the apikey is required
+*/
+func validateApikeySource(basicConfig []Default, keys []string, required bool) {
+	specified := getSpecifiedKeys(basicConfig, keys)
+	if len(specified) > 1 {
+		message := fmt.Sprintf(
+			"ERROR: You specified '%s', but only one of them is allowed.",
+			strings.Join(specified, "', '"),
+		)
+		Default{}.addInvalidValueMsg(message)
+	}
+	if len(specified) == 0 && required {
+		message := fmt.Sprintf(
+			"ERROR: You specified 'auth_mode = %s', but none of '%s' is specified.",
+			AUTH_APIKEY,
+			strings.Join(keys, "', '"),
+		)
+		Default{}.addInvalidValueMsg(message)
+	}
+}
+
+/*
+Special validation:
+Validating the endpoints, either the endpoint url or the endpoint type
+must be specified. The endpoint type needs an endpoint for the region
+in the endpoint table, an explicit endpoint url not matching
+the region is reported as warning.
+The secondary endpoint type defaults to the primary one.
+The endpoint types are not supported by S3 compatible storages.
+*/
+func validateEndpoints(basicConfig []Default) {
+	endpointUrl := getObjForKey(basicConfig, "endpoint_url")
+	endpointType := getObjForKey(basicConfig, "endpoint_type")
+	secondaryEndpointType := getObjForKey(basicConfig, "secondary_endpoint_type").configValue
+	if secondaryEndpointType == "" {
+		secondaryEndpointType = endpointType.configValue
+	}
+	if getObjForKey(basicConfig, "provider").configValue == PROVIDER_S3_COMPATIBLE {
+		for _, key := range []string{"endpoint_type", "secondary_endpoint_type"} {
+			if getObjForKey(basicConfig, key).configValue != "" {
+				message := fmt.Sprintf(
+					"ERROR: '%s' is not supported with 'provider = %s'.",
+					key,
+					PROVIDER_S3_COMPATIBLE,
+				)
+				Default{}.addInvalidValueMsg(message)
+			}
+		}
+		if endpointUrl.configValue == "" {
+			endpointUrl.addMissingMandatoryMsg()
+		}
+		return
+	}
+	if endpointUrl.configValue == "" && endpointType.configValue == "" {
+		message := "ERROR: Neither 'endpoint_url' nor 'endpoint_type' is specified."
+		Default{}.addInvalidValueMsg(message)
+		return
+	}
+
+	endpointTable := getObjForKey(basicConfig, "endpoint_table")
+	table, err := loadEndpointTable(endpointTable.configValue)
+	if err != nil {
+		endpointTable.addInvalidValueMsg(fmt.Sprintf(
+			"The endpoint table could not be loaded: %s",
+			err,
+		))
+		return
+	}
+
+	pairs := [][3]string{
+		{"region", "endpoint_url", endpointType.configValue},
+		{"secondary_region", "secondary_endpoint_url", secondaryEndpointType},
+	}
+	for _, pair := range pairs {
+		region := getObjForKey(basicConfig, pair[0]).configValue
+		url := getObjForKey(basicConfig, pair[1]).configValue
+		pairType := pair[2]
+		if region == "" || (url == "" && pairType == "") {
+			// A missing secondary endpoint is reported with the secondary storage
+			continue
+		}
+		if url == "" {
+			if _, found := table.lookup(region, pairType); !found {
+				message := fmt.Sprintf(
+					"ERROR: The endpoint table contains no '%s' endpoint for '%s = %s'.",
+					pairType,
+					pair[0],
+					region,
+				)
+				Default{}.addInvalidValueMsg(message)
+			}
+		} else if !table.matches(region, pairType, url) {
+			message := fmt.Sprintf(
+				"'%s = %s' does not match '%s = %s'.",
+				pair[1],
+				url,
+				pair[0],
+				region,
+			)
+			if expected, found := table.lookup(region, pairType); found {
+				message += fmt.Sprintf(
+					" The '%s' endpoint of the region is '%s'.",
+					pairType,
+					expected,
+				)
+			}
+			addWarningMsg(message)
+		}
+	}
+}
+
+/*
+Special validation:
+Validating the secondary storage, all or none of the
+connection parameters must be specified.
+With a trusted profile no apikey is needed,
+with an endpoint type no endpoint url.
+*/
+func validateSecondaryStorage(basicConfig []Default) {
+	keys := []string{
+		"secondary_bucket",
+		"secondary_region",
+	}
+	if getObjForKey(basicConfig, "endpoint_type").configValue == "" &&
+		getObjForKey(basicConfig, "secondary_endpoint_type").configValue == "" {
+		// Otherwise the endpoint is derived from the region
+		keys = append(keys, "secondary_endpoint_url")
+	}
+	if getAuthMode(basicConfig) == AUTH_HMAC {
+		keys = append(keys, "secondary_hmac_keypath")
+	}
+
+	var missing []string
+	for _, key := range keys {
+		if getObjForKey(basicConfig, key).configValue == "" {
+			missing = append(missing, key)
+		}
+	}
+	total := len(keys)
+	if getAuthMode(basicConfig) == AUTH_APIKEY {
+		// The apikey may be taken from any of its sources
+		total++
+		if len(getSpecifiedKeys(basicConfig, secondaryApikeySourceKeys)) == 0 {
+			missing = append(missing, secondaryApikeySourceKeys[0])
+		}
+	}
+	if len(missing) == 0 || len(missing) == total {
+		return
+	}
+
+	message := fmt.Sprintf(
+		"ERROR: You specified a secondary storage, but '%s' is not specified.",
+		strings.Join(missing, "', '"),
+	)
+	Default{}.addInvalidValueMsg(message)
+}
+
+/*
+Special validation:
+Validating the retry policy
+*/
+func validateRetry(basicConfig []Default) {
+	classes := getObjForKey(basicConfig, "retry_error_classes").configValue
+	if classes != "" {
+		for _, class := range strings.Split(classes, ",") {
+			class = strings.TrimSpace(class)
+			if !slices.Contains(validRetryErrorClasses, class) {
+				message := fmt.Sprintf(
+					"ERROR: 'retry_error_classes' contains the unknown class '%s'. ",
+					class,
+				)
+				message += fmt.Sprintf(
+					"Valid classes are '%s'.",
+					strings.Join(validRetryErrorClasses, "', '"),
+				)
+				Default{}.addInvalidValueMsg(message)
+			}
+		}
+	}
+
+	baseDelay := getObjForKey(basicConfig, "retry_base_delay")
+	maxDelay := getObjForKey(basicConfig, "retry_max_delay")
+	base := baseDelay.configValue
+	if base == "" {
+		base = baseDelay.defaultValue
+	}
+	max := maxDelay.configValue
+	if max == "" {
+		max = maxDelay.defaultValue
+	}
+	if global.ToInteger(base) > global.ToInteger(max) {
+		message := "ERROR: 'retry_base_delay' must not be higher than 'retry_max_delay'."
+		Default{}.addInvalidValueMsg(message)
+	}
+}
+
+/*
+Special validation:
+Validating the network settings, the CA bundle must contain certificates,
+client certificate and key must be specified together and match
+*/
+func validateNetwork(basicConfig []Default) {
+	caBundle := getObjForKey(basicConfig, "ca_bundle")
+	if caBundle.configValue != "" {
+		pem, err := os.ReadFile(caBundle.configValue)
+		if err == nil && !x509.NewCertPool().AppendCertsFromPEM(pem) {
+			caBundle.addInvalidValueMsg(
+				"The file does not contain any PEM encoded certificate.",
+			)
+		}
+	}
+
+	clientCert := getObjForKey(basicConfig, "client_cert")
+	clientKey := getObjForKey(basicConfig, "client_key")
+	if (clientCert.configValue == "") != (clientKey.configValue == "") {
+		message := "ERROR: 'client_cert' and 'client_key' must be specified together."
+		Default{}.addInvalidValueMsg(message)
+	} else if clientCert.configValue != "" {
+		_, err := tls.LoadX509KeyPair(clientCert.configValue, clientKey.configValue)
+		if err != nil {
+			message := fmt.Sprintf(
+				"ERROR: Could not load the client certificate: %s",
+				err,
+			)
+			Default{}.addInvalidValueMsg(message)
+		}
+	}
+}
+
+/*
+Getting the authentication mode, the default if not specified
+*/
+func getAuthMode(basicConfig []Default) string {
+	authMode := getObjForKey(basicConfig, "auth_mode")
+	if authMode.configValue == "" {
+		return authMode.defaultValue
+	}
+	return authMode.configValue
+}
+
+/*
+Getting the keys of a given list which are specified in the config file
+*/
+func getSpecifiedKeys(basicConfig []Default, keys []string) []string {
+	var specified []string
+	for _, key := range keys {
+		if getObjForKey(basicConfig, key).configValue != "" {
+			specified = append(specified, key)
+		}
+	}
+	return specified
+}
+
 /*
 returns true if config value is of type boolean
 */
@@ -606,6 +1112,16 @@ func (cp Default) addInvalidValueMsg(msg string) {
 	invalidValues = append(invalidValues, invalid)
 }
 
+/*
+Adding a warning to the messages of -check,
+warnings do not make the configuration invalid
+*/
+func addWarningMsg(msg string) {
+	if global.Args.CheckParms {
+		checkParmMessages = append(checkParmMessages, "\tWARNING: "+msg)
+	}
+}
+
 func addOkMessage(key string) {
 	if global.Args.CheckParms {
 
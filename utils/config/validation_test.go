@@ -0,0 +1,663 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+)
+
+// Expected outcome of one validation
+type validationTest struct {
+	name        string
+	values      map[string]string
+	wantErrors  []string
+	wantWarning string
+}
+
+/*
+Running a validation on the defaults with the given values specified.
+Returns the error messages and the warnings reported.
+*/
+func runValidation(
+	t *testing.T,
+	values map[string]string,
+	validate func(basicConfig []Default),
+) ([]string, []string) {
+	t.Helper()
+	basicConfig := make([]Default, len(configDefaults))
+	copy(basicConfig, configDefaults)
+	for key, value := range values {
+		found := false
+		for i := range basicConfig {
+			if basicConfig[i].key == key {
+				basicConfig[i].configValue = value
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("unknown parameter '%s'", key)
+		}
+	}
+
+	previousArgs := global.Args
+	global.Args.CheckParms = true
+	invalidValues = nil
+	checkParmMessages = nil
+	t.Cleanup(func() {
+		global.Args = previousArgs
+		invalidValues = nil
+		checkParmMessages = nil
+	})
+
+	validate(basicConfig)
+
+	var errors []string
+	for _, invalid := range invalidValues {
+		errors = append(errors, invalid.errorMessage)
+	}
+	var warnings []string
+	for _, message := range checkParmMessages {
+		if warning, found := strings.CutPrefix(message, "\tWARNING: "); found {
+			warnings = append(warnings, warning)
+		}
+	}
+	return errors, warnings
+}
+
+/*
+Running a table of validation tests, every expected error
+and the expected warning must be contained in one of the messages
+*/
+func runValidationTests(t *testing.T, tests []validationTest, validate func(basicConfig []Default)) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors, warnings := runValidation(t, tt.values, validate)
+			if len(errors) != len(tt.wantErrors) {
+				t.Fatalf("errors = %q, want %d errors containing %q", errors, len(tt.wantErrors), tt.wantErrors)
+			}
+			for i, want := range tt.wantErrors {
+				if !strings.Contains(errors[i], want) {
+					t.Errorf("error %q does not contain %q", errors[i], want)
+				}
+			}
+			if tt.wantWarning == "" && len(warnings) > 0 {
+				t.Errorf("unexpected warnings %q", warnings)
+			}
+			if tt.wantWarning != "" &&
+				(len(warnings) != 1 || !strings.Contains(warnings[0], tt.wantWarning)) {
+				t.Errorf("warnings = %q, want one containing %q", warnings, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestValidateEncryption(t *testing.T) {
+	tests := []validationTest{
+		{
+			name: "encryption not specified",
+		},
+		{
+			name:   "encryption switched off",
+			values: map[string]string{"encryption_algorithm": ENCRYPTION_NONE},
+		},
+		{
+			name: "key file",
+			values: map[string]string{
+				"encryption_algorithm": ENCRYPTION_AES256GCM,
+				"encryption_keypath":   "/hana/backint/master.key",
+			},
+		},
+		{
+			name:       "without key file",
+			values:     map[string]string{"encryption_algorithm": ENCRYPTION_AES256GCM},
+			wantErrors: []string{"'encryption_algorithm = aes256gcm', but no 'encryption_keypath' is specified"},
+		},
+	}
+	runValidationTests(t, tests, validateEncryption)
+}
+
+func TestValidateCompression(t *testing.T) {
+	tests := []validationTest{
+		{
+			name:   "zstd with the highest level",
+			values: map[string]string{"compression": COMPRESSION_ZSTD, "compression_level": "22"},
+		},
+		{
+			name:   "lz4 with the default level",
+			values: map[string]string{"compression": COMPRESSION_LZ4},
+		},
+		{
+			name:   "lz4 with the highest level",
+			values: map[string]string{"compression": COMPRESSION_LZ4, "compression_level": "9"},
+		},
+		{
+			name:       "lz4 with a zstd level",
+			values:     map[string]string{"compression": COMPRESSION_LZ4, "compression_level": "10"},
+			wantErrors: []string{"'compression = lz4', but 'compression_level' is higher than '9'"},
+		},
+		{
+			name:   "level without compression",
+			values: map[string]string{"compression": COMPRESSION_NONE, "compression_level": "22"},
+		},
+	}
+	runValidationTests(t, tests, validateCompression)
+}
+
+func TestValidateKeyTemplate(t *testing.T) {
+	tests := []validationTest{
+		{
+			name:   "all placeholders",
+			values: map[string]string{"key_template": "{SID}/{TENANT}/{LEVEL}/{BACKUP_ID}/{DATE:2006}/{HOST}/{USER}/{PIPE_BASENAME}"},
+		},
+		{
+			name:        "without system id",
+			values:      map[string]string{"key_template": "{TENANT}/{PIPE_BASENAME}"},
+			wantWarning: "'{SID}'",
+		},
+		{
+			name:       "without pipe basename",
+			values:     map[string]string{"key_template": "{SID}/{TENANT}/{BACKUP_ID}"},
+			wantErrors: []string{"must contain the placeholder '{PIPE_BASENAME}'"},
+		},
+		{
+			name:       "without tenant",
+			values:     map[string]string{"key_template": "{SID}/{PIPE_BASENAME}"},
+			wantErrors: []string{"must contain the placeholder '{TENANT}'"},
+		},
+		{
+			name:       "unknown placeholder",
+			values:     map[string]string{"key_template": "{SID}/{TENANT}/{DB}/{PIPE_BASENAME}"},
+			wantErrors: []string{"unknown placeholder '{DB}'"},
+		},
+	}
+	runValidationTests(t, tests, func(basicConfig []Default) {
+		getObjForKey(basicConfig, "key_template").validateKeyTemplate()
+	})
+}
+
+func TestValidateSecondaryStorage(t *testing.T) {
+	tests := []validationTest{
+		{
+			name: "no secondary storage",
+		},
+		{
+			name: "complete with apikey",
+			values: map[string]string{
+				"secondary_bucket":       "backups-dr",
+				"secondary_region":       "eu-gb",
+				"secondary_endpoint_url": "https://s3.eu-gb.cloud-object-storage.appdomain.cloud",
+				"secondary_apikey_env":   "COS_SECONDARY_APIKEY",
+			},
+		},
+		{
+			name:   "bucket only",
+			values: map[string]string{"secondary_bucket": "backups-dr"},
+			wantErrors: []string{
+				"'secondary_region', 'secondary_endpoint_url', 'secondary_auth_keypath' is not specified",
+			},
+		},
+		{
+			name: "endpoint derived from the endpoint type",
+			values: map[string]string{
+				"endpoint_type":          ENDPOINT_PRIVATE,
+				"secondary_bucket":       "backups-dr",
+				"secondary_region":       "eu-gb",
+				"secondary_auth_keypath": "/hana/secondary_apikey",
+			},
+		},
+		{
+			name: "trusted profile without apikey",
+			values: map[string]string{
+				"auth_mode":               AUTH_TRUSTED_PROFILE,
+				"secondary_bucket":        "backups-dr",
+				"secondary_region":        "eu-gb",
+				"secondary_endpoint_type": ENDPOINT_DIRECT,
+			},
+		},
+		{
+			name: "HMAC without key file",
+			values: map[string]string{
+				"auth_mode":              AUTH_HMAC,
+				"secondary_bucket":       "backups-dr",
+				"secondary_region":       "eu-gb",
+				"secondary_endpoint_url": "https://s3.eu-gb.cloud-object-storage.appdomain.cloud",
+			},
+			wantErrors: []string{"'secondary_hmac_keypath' is not specified"},
+		},
+	}
+	runValidationTests(t, tests, validateSecondaryStorage)
+}
+
+func TestValidateRetry(t *testing.T) {
+	tests := []validationTest{
+		{
+			name: "defaults",
+		},
+		{
+			name: "all error classes",
+			values: map[string]string{
+				"retry_error_classes": RETRY_CLASS_THROTTLING + ", " + RETRY_CLASS_TIMEOUT + "," +
+					RETRY_CLASS_SERVER + "," + RETRY_CLASS_NETWORK,
+			},
+		},
+		{
+			name:       "unknown error class",
+			values:     map[string]string{"retry_error_classes": RETRY_CLASS_SERVER + ",client"},
+			wantErrors: []string{"'retry_error_classes' contains the unknown class 'client'"},
+		},
+		{
+			name:   "equal delays",
+			values: map[string]string{"retry_base_delay": "1000", "retry_max_delay": "1000"},
+		},
+		{
+			name:       "base delay higher than max delay",
+			values:     map[string]string{"retry_base_delay": "2000", "retry_max_delay": "1000"},
+			wantErrors: []string{"'retry_base_delay' must not be higher than 'retry_max_delay'"},
+		},
+		{
+			name:       "base delay higher than the default max delay",
+			values:     map[string]string{"retry_base_delay": "60000"},
+			wantErrors: []string{"'retry_base_delay' must not be higher than 'retry_max_delay'"},
+		},
+	}
+	runValidationTests(t, tests, validateRetry)
+}
+
+func TestValidateTrustedProfileAuthentication(t *testing.T) {
+	tests := []validationTest{
+		{
+			name: "trusted profile id",
+			values: map[string]string{
+				"auth_mode":          AUTH_TRUSTED_PROFILE,
+				"trusted_profile_id": "Profile-0000",
+			},
+		},
+		{
+			name:       "without trusted profile id",
+			values:     map[string]string{"auth_mode": AUTH_TRUSTED_PROFILE},
+			wantErrors: []string{"'auth_mode = trustedprofile', but no 'trusted_profile_id' is specified"},
+		},
+		{
+			name: "secondary storage with the trusted profile",
+			values: map[string]string{
+				"auth_mode":          AUTH_TRUSTED_PROFILE,
+				"trusted_profile_id": "Profile-0000",
+				"secondary_bucket":   "backups-dr",
+				"secondary_region":   "eu-gb",
+			},
+		},
+		{
+			name: "secondary storage with keys",
+			values: map[string]string{
+				"auth_mode":              AUTH_TRUSTED_PROFILE,
+				"trusted_profile_id":     "Profile-0000",
+				"secondary_bucket":       "backups-dr",
+				"secondary_region":       "eu-gb",
+				"secondary_auth_keypath": "/hana/secondary_apikey",
+				"secondary_hmac_keypath": "/hana/secondary_hmac",
+			},
+			wantErrors: []string{"'secondary_hmac_keypath', 'secondary_auth_keypath' is specified"},
+		},
+	}
+	runValidationTests(t, tests, validateAuthentication)
+}
+
+func TestValidateHmacAuthentication(t *testing.T) {
+	tests := []validationTest{
+		{
+			name: "HMAC key file",
+			values: map[string]string{
+				"auth_mode":    AUTH_HMAC,
+				"hmac_keypath": "/hana/backint/hmac_keys",
+			},
+		},
+		{
+			name:       "without HMAC key file",
+			values:     map[string]string{"auth_mode": AUTH_HMAC},
+			wantErrors: []string{"'auth_mode = hmac', but no 'hmac_keypath' is specified"},
+		},
+		{
+			name: "apikey sources are not needed",
+			values: map[string]string{
+				"auth_mode":    AUTH_HMAC,
+				"hmac_keypath": "/hana/backint/hmac_keys",
+				"auth_keypath": "/hana/backint/apikey",
+				"apikey_env":   "COS_APIKEY",
+			},
+		},
+	}
+	runValidationTests(t, tests, validateAuthentication)
+}
+
+func TestValidateApikeySources(t *testing.T) {
+	tests := []validationTest{
+		{
+			name:   "key file",
+			values: map[string]string{"auth_mode": AUTH_APIKEY, "auth_keypath": "/hana/apikey"},
+		},
+		{
+			name:   "environment",
+			values: map[string]string{"auth_mode": AUTH_APIKEY, "apikey_env": "COS_APIKEY"},
+		},
+		{
+			name:   "helper command",
+			values: map[string]string{"apikey_command": "/usr/bin/vault read apikey"},
+		},
+		{
+			name: "two sources",
+			values: map[string]string{
+				"auth_mode":    AUTH_APIKEY,
+				"auth_keypath": "/hana/apikey",
+				"apikey_env":   "COS_APIKEY",
+			},
+			wantErrors: []string{"'auth_keypath', 'apikey_env', but only one of them is allowed"},
+		},
+		{
+			name:       "no source",
+			values:     map[string]string{"auth_mode": AUTH_APIKEY},
+			wantErrors: []string{"none of 'auth_keypath', 'apikey_env', 'apikey_command' is specified"},
+		},
+		{
+			name: "two sources of the secondary apikey",
+			values: map[string]string{
+				"auth_keypath":             "/hana/apikey",
+				"secondary_apikey_env":     "COS_SECONDARY_APIKEY",
+				"secondary_apikey_command": "/usr/bin/vault read secondary",
+			},
+			wantErrors: []string{"'secondary_apikey_env', 'secondary_apikey_command', but only one"},
+		},
+	}
+	runValidationTests(t, tests, validateAuthentication)
+}
+
+/*
+Writing a self-signed certificate and its key in PEM format
+*/
+func writeCertificate(t *testing.T, dir string, name string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPath := filepath.Join(dir, name+".crt")
+	keyPath := filepath.Join(dir, name+".key")
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	if err := os.WriteFile(certPath, certPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, keyPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certPath, keyPath
+}
+
+func TestValidateNetwork(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, clientKey := writeCertificate(t, dir, "client")
+	_, otherKey := writeCertificate(t, dir, "other")
+	noCertificate := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(noCertificate, []byte("no certificate\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []validationTest{
+		{
+			name: "defaults",
+		},
+		{
+			name:   "CA bundle",
+			values: map[string]string{"ca_bundle": clientCert},
+		},
+		{
+			name:       "CA bundle without certificate",
+			values:     map[string]string{"ca_bundle": noCertificate},
+			wantErrors: []string{"does not contain any PEM encoded certificate"},
+		},
+		{
+			name:   "client certificate and key",
+			values: map[string]string{"client_cert": clientCert, "client_key": clientKey},
+		},
+		{
+			name:       "client certificate without key",
+			values:     map[string]string{"client_cert": clientCert},
+			wantErrors: []string{"'client_cert' and 'client_key' must be specified together"},
+		},
+		{
+			name:       "client key without certificate",
+			values:     map[string]string{"client_key": clientKey},
+			wantErrors: []string{"'client_cert' and 'client_key' must be specified together"},
+		},
+		{
+			name:       "key of another certificate",
+			values:     map[string]string{"client_cert": clientCert, "client_key": otherKey},
+			wantErrors: []string{"Could not load the client certificate"},
+		},
+	}
+	runValidationTests(t, tests, validateNetwork)
+}
+
+func TestValidateS3CompatibleAuthentication(t *testing.T) {
+	tests := []validationTest{
+		{
+			name: "HMAC",
+			values: map[string]string{
+				"provider":     PROVIDER_S3_COMPATIBLE,
+				"auth_mode":    AUTH_HMAC,
+				"hmac_keypath": "/hana/backint/hmac_keys",
+			},
+		},
+		{
+			name: "anonymous",
+			values: map[string]string{
+				"provider":  PROVIDER_S3_COMPATIBLE,
+				"auth_mode": AUTH_ANONYMOUS,
+			},
+		},
+		{
+			name: "apikey",
+			values: map[string]string{
+				"provider":     PROVIDER_S3_COMPATIBLE,
+				"auth_mode":    AUTH_APIKEY,
+				"auth_keypath": "/hana/backint/apikey",
+			},
+			wantErrors: []string{"'provider = s3compatible', but 'auth_mode = apikey' is not supported"},
+		},
+		{
+			name: "trusted profile",
+			values: map[string]string{
+				"provider":           PROVIDER_S3_COMPATIBLE,
+				"auth_mode":          AUTH_TRUSTED_PROFILE,
+				"trusted_profile_id": "Profile-0000",
+			},
+			wantErrors: []string{"'auth_mode = trustedprofile' is not supported"},
+		},
+		{
+			name:       "anonymous with IBM Cloud",
+			values:     map[string]string{"auth_mode": AUTH_ANONYMOUS},
+			wantErrors: []string{"'auth_mode = anonymous' is only supported with 'provider = s3compatible'"},
+		},
+	}
+	runValidationTests(t, tests, validateAuthentication)
+}
+
+func TestAdaptToProvider(t *testing.T) {
+	validateRegionAndEndpoint := func(basicConfig []Default) {
+		for _, cp := range adaptToProvider(basicConfig) {
+			if cp.key == "region" || cp.key == "endpoint_url" {
+				cp.validateParameter()
+			}
+		}
+	}
+
+	tests := []validationTest{
+		{
+			name: "any region and http endpoint of S3 compatible storage",
+			values: map[string]string{
+				"provider":     PROVIDER_S3_COMPATIBLE,
+				"region":       "us-east-1",
+				"endpoint_url": "http://minio.example.com:9000",
+			},
+		},
+		{
+			name: "invalid endpoint of S3 compatible storage",
+			values: map[string]string{
+				"provider":     PROVIDER_S3_COMPATIBLE,
+				"region":       "us-east-1",
+				"endpoint_url": "minio.example.com:9000",
+			},
+			wantErrors: []string{"must be an URL starting with http:// or https://"},
+		},
+		{
+			name: "region and http endpoint of IBM Cloud",
+			values: map[string]string{
+				"region":       "us-east-1",
+				"endpoint_url": "http://minio.example.com:9000",
+			},
+			wantErrors: []string{"'region'", "'endpoint_url'"},
+		},
+	}
+	runValidationTests(t, tests, validateRegionAndEndpoint)
+}
+
+func TestValidateEndpoints(t *testing.T) {
+	dir := t.TempDir()
+	table := dir + "/endpoints.json"
+	if err := os.WriteFile(table, []byte(`{"on-prem": {"public": "https://cos.example.com"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []validationTest{
+		{
+			name:       "neither endpoint url nor type",
+			values:     map[string]string{"region": "eu-de"},
+			wantErrors: []string{"Neither 'endpoint_url' nor 'endpoint_type' is specified"},
+		},
+		{
+			name:   "endpoint type",
+			values: map[string]string{"region": "eu-de", "endpoint_type": ENDPOINT_DIRECT},
+		},
+		{
+			name: "endpoint url of the region",
+			values: map[string]string{
+				"region":       "eu-de",
+				"endpoint_url": "https://s3.private.eu-de.cloud-object-storage.appdomain.cloud",
+			},
+		},
+		{
+			name: "endpoint url of another region",
+			values: map[string]string{
+				"region":       "eu-de",
+				"endpoint_url": "https://s3.eu-gb.cloud-object-storage.appdomain.cloud",
+			},
+			wantWarning: "does not match 'region = eu-de'",
+		},
+		{
+			name: "endpoint url of another type",
+			values: map[string]string{
+				"region":        "eu-de",
+				"endpoint_type": ENDPOINT_PRIVATE,
+				"endpoint_url":  "https://s3.eu-de.cloud-object-storage.appdomain.cloud",
+			},
+			wantWarning: "The 'private' endpoint of the region is 'https://s3.private.eu-de.cloud-object-storage.appdomain.cloud'",
+		},
+		{
+			name: "type missing in the local table",
+			values: map[string]string{
+				"region":         "on-prem",
+				"endpoint_type":  ENDPOINT_PRIVATE,
+				"endpoint_table": table,
+			},
+			wantErrors: []string{"contains no 'private' endpoint for 'region = on-prem'"},
+		},
+		{
+			name: "secondary type missing in the local table",
+			values: map[string]string{
+				"region":                  "eu-de",
+				"secondary_region":        "on-prem",
+				"endpoint_type":           ENDPOINT_PUBLIC,
+				"secondary_endpoint_type": ENDPOINT_DIRECT,
+				"endpoint_table":          table,
+			},
+			wantErrors: []string{"contains no 'direct' endpoint for 'secondary_region = on-prem'"},
+		},
+		{
+			name: "secondary region with the primary type",
+			values: map[string]string{
+				"region":           "eu-de",
+				"secondary_region": "on-prem",
+				"endpoint_type":    ENDPOINT_PUBLIC,
+				"endpoint_table":   table,
+			},
+		},
+		{
+			name: "endpoint table not loadable",
+			values: map[string]string{
+				"region":         "eu-de",
+				"endpoint_type":  ENDPOINT_PUBLIC,
+				"endpoint_table": dir + "/missing.json",
+			},
+			wantErrors: []string{"The endpoint table could not be loaded"},
+		},
+		{
+			name: "endpoint types of S3 compatible storage",
+			values: map[string]string{
+				"provider":                PROVIDER_S3_COMPATIBLE,
+				"endpoint_url":            "http://minio:9000",
+				"endpoint_type":           ENDPOINT_PUBLIC,
+				"secondary_endpoint_type": ENDPOINT_PUBLIC,
+			},
+			wantErrors: []string{
+				"'endpoint_type' is not supported with 'provider = s3compatible'",
+				"'secondary_endpoint_type' is not supported",
+			},
+		},
+		{
+			name:       "S3 compatible storage without endpoint url",
+			values:     map[string]string{"provider": PROVIDER_S3_COMPATIBLE},
+			wantErrors: []string{"mandatory parameter 'endpoint_url'"},
+		},
+	}
+	runValidationTests(t, tests, validateEndpoints)
+}
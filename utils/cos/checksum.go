@@ -0,0 +1,303 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+)
+
+// Error returned if the restored data does not match the stored checksum
+var errChecksumMismatch = errors.New("checksum mismatch")
+var errSizeMismatch = errors.New("size mismatch")
+
+/*
+Getting the object metadata holding the checksum of the backup data
+*/
+func getChecksumMetadata(checksum string) map[string]*string {
+	return map[string]*string{
+		METADATA_CHECKSUM_SHA256: aws.String(checksum),
+	}
+}
+
+/*
+Storing the checksum of the backup data in the metadata of the uploaded object.
+The checksum of a file is sent with the upload. The checksum of a pipe is
+known only after the upload and the metadata of an object version cannot
+be changed, therefore the object is copied onto itself with the checksum
+added to its metadata, and the uploaded version is deleted afterwards.
+Returns the object version holding the checksum.
+*/
+func storeChecksum(
+	target uploadTarget,
+	Key string,
+	sourcePath string,
+	versionId string,
+	checksum string,
+) (uploadedObject, error) {
+	head, err := getHeadObjectInBucket(target.client, target.bucket, Key, versionId)
+	if err != nil {
+		return uploadedObject{}, err
+	}
+	uploaded := uploadedObject{
+		ETag:      aws.StringValue(head.ETag),
+		VersionId: aws.StringValue(head.VersionId),
+		size:      aws.Int64Value(head.ContentLength),
+	}
+
+	// Stored with the upload
+	switch getMetadataValue(head.Metadata, METADATA_CHECKSUM_SHA256) {
+	case checksum:
+		return uploaded, nil
+	case "":
+	default:
+		return uploaded, fmt.Errorf(
+			"%w: '%s' changed during the upload",
+			errChecksumMismatch,
+			sourcePath,
+		)
+	}
+
+	global.Logger.Debug(fmt.Sprintf(
+		"Storing checksum '%s' for key '%s' in bucket '%s'.",
+		checksum,
+		Key,
+		target.bucket,
+	))
+	metadata := make(map[string]*string)
+	maps.Copy(metadata, head.Metadata)
+	maps.Copy(metadata, getChecksumMetadata(checksum))
+	copied, err := copyObjectInPlace(
+		target.client,
+		target.bucket,
+		Key,
+		versionId,
+		uploaded.size,
+		metadata,
+		config.BackintConfig.TagsForPipe(sourcePath),
+	)
+	if err != nil {
+		return uploaded, err
+	}
+	copied.size = uploaded.size
+
+	// Without versioning, the copy replaced the uploaded object
+	if versionId != "" && copied.VersionId != versionId {
+		err = deleteObjectVersion(target.client, target.bucket, Key, versionId)
+		if err != nil {
+			global.Logger.Warn(fmt.Sprintf(
+				"The version '%s' of '%s' without checksum is kept in bucket '%s'. Error: %s",
+				versionId,
+				Key,
+				target.bucket,
+				err,
+			))
+		}
+	}
+	return copied, nil
+}
+
+/*
+Copying an object version onto itself with new metadata.
+Objects larger than the maximum size of a single copy
+are copied with a multipart copy.
+*/
+func copyObjectInPlace(
+	s3Client *s3.S3,
+	bucket string,
+	Key string,
+	versionId string,
+	size int64,
+	metadata map[string]*string,
+	tags string,
+) (uploadedObject, error) {
+	copySource := fmt.Sprintf("%s/%s", bucket, (&url.URL{Path: Key}).EscapedPath())
+	if versionId != "" {
+		copySource += "?versionId=" + url.QueryEscape(versionId)
+	}
+	lockMode, lockDate, lockLegalHold := getObjectLockSettings()
+
+	if size > MAX_COPY_OBJECT_SIZE {
+		input := s3.CreateMultipartUploadInput{
+			Bucket:                    aws.String(bucket),
+			Key:                       aws.String(Key),
+			Metadata:                  metadata,
+			ObjectLockLegalHoldStatus: lockLegalHold,
+			ObjectLockMode:            lockMode,
+			ObjectLockRetainUntilDate: lockDate,
+		}
+		// The tags are not copied with the parts
+		if tags != "" {
+			input.Tagging = aws.String(tags)
+		}
+		return copyObjectParts(s3Client, &input, copySource, size)
+	}
+
+	output, err := s3Client.CopyObject(&s3.CopyObjectInput{
+		Bucket:                    aws.String(bucket),
+		Key:                       aws.String(Key),
+		CopySource:                aws.String(copySource),
+		Metadata:                  metadata,
+		MetadataDirective:         aws.String(s3.MetadataDirectiveReplace),
+		ObjectLockLegalHoldStatus: lockLegalHold,
+		ObjectLockMode:            lockMode,
+		ObjectLockRetainUntilDate: lockDate,
+	})
+	if err != nil {
+		return uploadedObject{}, err
+	}
+	copied := uploadedObject{VersionId: aws.StringValue(output.VersionId)}
+	if output.CopyObjectResult != nil {
+		copied.ETag = aws.StringValue(output.CopyObjectResult.ETag)
+	}
+	return copied, nil
+}
+
+/*
+Deleting one object version
+*/
+func deleteObjectVersion(s3Client *s3.S3, bucket string, Key string, versionId string) error {
+	input := s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(Key),
+	}
+	if versionId != "" {
+		input.VersionId = aws.String(versionId)
+	}
+	_, err := s3Client.DeleteObject(&input)
+	return err
+}
+
+/*
+Setting up the target calculating the checksum
+of the data written to pipe
+*/
+func newChecksumTarget(target restoreTarget) *checksumTarget {
+	return &checksumTarget{
+		target: target,
+		hash:   sha256.New(),
+	}
+}
+
+/*
+Writer function calculating the checksum of the data written to pipe
+*/
+func (c *checksumTarget) Write(p []byte) (int, error) {
+	n, err := c.target.Write(p)
+	c.hash.Write(p[:n])
+	return n, err
+}
+
+/*
+Getting the name of the pipe
+*/
+func (c *checksumTarget) Name() string {
+	return c.target.Name()
+}
+
+/*
+Getting the checksum of the data written to pipe
+*/
+func (c *checksumTarget) checksum() string {
+	return hex.EncodeToString(c.hash.Sum(nil))
+}
+
+/*
+Verifying the checksum of the restored data
+*/
+func verifyChecksum(Key string, expected string, calculated string) error {
+	if expected == "" {
+		global.Logger.Warn(fmt.Sprintf(
+			"'%s': No checksum stored with object, the restored data is not verified.",
+			Key,
+		))
+		return nil
+	}
+
+	if expected != calculated {
+		global.Logger.Error(fmt.Sprintf(
+			"'%s': Checksum of restored data '%s' does not match stored checksum '%s'.",
+			Key,
+			calculated,
+			expected,
+		))
+		return fmt.Errorf("%w: expected '%s', calculated '%s'",
+			errChecksumMismatch,
+			expected,
+			calculated,
+		)
+	}
+
+	global.Logger.Info(fmt.Sprintf(
+		"'%s': Checksum '%s' verified.", Key, calculated,
+	))
+	return nil
+}
+
+/*
+Verifying that all parts are downloaded and
+the size of the downloaded data matches the size of the object
+*/
+func verifyDownloadedSize(
+	Key string,
+	expectedParts int64,
+	downloadedParts int64,
+	expectedSize int64,
+	downloadedSize int64,
+) error {
+	if downloadedParts != expectedParts {
+		global.Logger.Error(fmt.Sprintf(
+			"'%s': Downloaded %d of %d parts.",
+			Key,
+			downloadedParts,
+			expectedParts,
+		))
+		return fmt.Errorf("%w: expected %d parts, downloaded %d parts",
+			errSizeMismatch,
+			expectedParts,
+			downloadedParts,
+		)
+	}
+	if downloadedSize != expectedSize {
+		global.Logger.Error(fmt.Sprintf(
+			"'%s': Downloaded %d bytes, but the object has %d bytes.",
+			Key,
+			downloadedSize,
+			expectedSize,
+		))
+		return fmt.Errorf("%w: expected %d bytes, downloaded %d bytes",
+			errSizeMismatch,
+			expectedSize,
+			downloadedSize,
+		)
+	}
+	global.Logger.Info(fmt.Sprintf(
+		"'%s': Size of %d bytes in %d parts verified.",
+		Key,
+		downloadedSize,
+		downloadedParts,
+	))
+	return nil
+}
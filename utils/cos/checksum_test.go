@@ -0,0 +1,388 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/credentials"
+	"github.com/IBM/ibm-cos-sdk-go/aws/session"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+// Stub of a bucket holding the versions of one object
+type checksumBucket struct {
+	mu       sync.Mutex
+	size     int64
+	metadata map[string]map[string]string
+	versions int
+	failCopy bool
+	copies   int
+	ranges   []string
+	tagging  string
+	deleted  []string
+}
+
+func (b *checksumBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	query := r.URL.Query()
+	newVersion := func() string {
+		b.versions++
+		versionId := fmt.Sprintf("v%d", b.versions)
+		b.metadata[versionId] = requestMetadata(r)
+		return versionId
+	}
+
+	switch {
+	case r.Method == http.MethodHead:
+		metadata, found := b.metadata[query.Get("versionId")]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		for key, value := range metadata {
+			w.Header().Set("X-Amz-Meta-"+key, value)
+		}
+		w.Header().Set("ETag", `"uploaded-etag"`)
+		w.Header().Set("X-Amz-Version-Id", query.Get("versionId"))
+		w.Header().Set("Content-Length", strconv.FormatInt(b.size, 10))
+	case r.Method == http.MethodPut && b.failCopy:
+		w.WriteHeader(http.StatusInternalServerError)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		b.ranges = append(b.ranges, r.Header.Get("X-Amz-Copy-Source-Range"))
+		fmt.Fprintf(w, `<CopyPartResult><ETag>"part-%s"</ETag></CopyPartResult>`, query.Get("partNumber"))
+	case r.Method == http.MethodPut:
+		b.copies++
+		w.Header().Set("X-Amz-Version-Id", newVersion())
+		fmt.Fprint(w, `<CopyObjectResult><ETag>"copied-etag"</ETag></CopyObjectResult>`)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		b.tagging = r.Header.Get("X-Amz-Tagging")
+		b.metadata["upload"] = requestMetadata(r)
+		fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>upload</UploadId></InitiateMultipartUploadResult>`)
+	case r.Method == http.MethodPost:
+		_, _ = io.Copy(io.Discard, r.Body)
+		b.versions++
+		versionId := fmt.Sprintf("v%d", b.versions)
+		b.metadata[versionId] = b.metadata["upload"]
+		w.Header().Set("X-Amz-Version-Id", versionId)
+		fmt.Fprint(w, `<CompleteMultipartUploadResult><ETag>"copied-etag-2"</ETag></CompleteMultipartUploadResult>`)
+	case r.Method == http.MethodDelete:
+		b.deleted = append(b.deleted, query.Get("versionId"))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+/*
+Getting the object metadata sent with a request
+*/
+func requestMetadata(r *http.Request) map[string]string {
+	metadata := make(map[string]string)
+	for name, values := range r.Header {
+		if key, found := strings.CutPrefix(strings.ToLower(name), "x-amz-meta-"); found {
+			metadata[key] = values[0]
+		}
+	}
+	return metadata
+}
+
+/*
+Setting up a stub bucket holding one uploaded object version
+and the configuration for storing its checksum
+*/
+func newChecksumBucket(t *testing.T, size int64, metadata map[string]string) (*checksumBucket, uploadTarget) {
+	t.Helper()
+	previous := config.BackintConfig
+	config.BackintConfig = config.BackintConfigT{
+		"max_concurrency": "2",
+		"object_tags":     "team=basis",
+	}
+	t.Cleanup(func() { config.BackintConfig = previous })
+
+	bucket := &checksumBucket{
+		size:     size,
+		metadata: map[string]map[string]string{"v1": metadata},
+		versions: 1,
+	}
+	server := httptest.NewServer(bucket)
+	t.Cleanup(server.Close)
+
+	s3Session := session.Must(session.NewSession(aws.NewConfig().
+		WithEndpoint(server.URL).
+		WithRegion("us-east-1").
+		WithCredentials(credentials.NewStaticCredentials("key-id", "secret", "")).
+		WithS3ForcePathStyle(true).
+		WithMaxRetries(0),
+	))
+	return bucket, uploadTarget{
+		session: s3Session,
+		client:  s3.New(s3Session),
+		bucket:  "backups",
+	}
+}
+
+/*
+Capturing the log messages of one test
+*/
+func setupChecksumLog(t *testing.T) *logtest.Hook {
+	t.Helper()
+	previous := global.Logger
+	global.Logger = logrus.New()
+	global.Logger.SetOutput(io.Discard)
+	hook := logtest.NewLocal(global.Logger)
+	t.Cleanup(func() { global.Logger = previous })
+	return hook
+}
+
+func TestStoreChecksum(t *testing.T) {
+	const checksum = "0123abcd"
+	tests := []struct {
+		name        string
+		size        int64
+		metadata    map[string]string
+		versionId   string
+		failCopy    bool
+		wantErr     bool
+		wantCopies  int
+		wantRanges  int
+		wantETag    string
+		wantVersion string
+		wantDeleted []string
+	}{
+		{
+			name:        "pipe uploaded without checksum",
+			size:        1024,
+			metadata:    map[string]string{"backint-compression": "zstd"},
+			versionId:   "v1",
+			wantCopies:  1,
+			wantETag:    `"copied-etag"`,
+			wantVersion: "v2",
+			wantDeleted: []string{"v1"},
+		},
+		{
+			name:        "file uploaded with checksum",
+			size:        1024,
+			metadata:    map[string]string{"backint-sha256": checksum},
+			versionId:   "v1",
+			wantETag:    `"uploaded-etag"`,
+			wantVersion: "v1",
+		},
+		{
+			name:      "file changed during the upload",
+			size:      1024,
+			metadata:  map[string]string{"backint-sha256": "ffff"},
+			versionId: "v1",
+			wantErr:   true,
+		},
+		{
+			name:      "copy failing",
+			size:      1024,
+			metadata:  map[string]string{},
+			versionId: "v1",
+			failCopy:  true,
+			wantErr:   true,
+		},
+		{
+			name:        "object larger than a single copy",
+			size:        MAX_COPY_OBJECT_SIZE + 1,
+			metadata:    map[string]string{"backint-compression": "lz4"},
+			versionId:   "v1",
+			wantRanges:  int(MAX_COPY_OBJECT_SIZE/COPY_PART_SIZE) + 1,
+			wantETag:    `"copied-etag-2"`,
+			wantVersion: "v2",
+			wantDeleted: []string{"v1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupChecksumLog(t)
+			bucket, target := newChecksumBucket(t, tt.size, tt.metadata)
+			bucket.failCopy = tt.failCopy
+
+			got, err := storeChecksum(target, "backup/data", "/tmp/pipe", tt.versionId, checksum)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("storeChecksum() succeeded")
+				}
+				if len(bucket.deleted) > 0 {
+					t.Errorf("versions %v deleted after a failure", bucket.deleted)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ETag != tt.wantETag || got.VersionId != tt.wantVersion || got.size != tt.size {
+				t.Errorf("storeChecksum() = %+v, want ETag %s, version %s, size %d",
+					got, tt.wantETag, tt.wantVersion, tt.size)
+			}
+
+			bucket.mu.Lock()
+			defer bucket.mu.Unlock()
+			if bucket.copies != tt.wantCopies || len(bucket.ranges) != tt.wantRanges {
+				t.Errorf("%d copies and %d copied parts, want %d and %d",
+					bucket.copies, len(bucket.ranges), tt.wantCopies, tt.wantRanges)
+			}
+			stored := bucket.metadata[got.VersionId]
+			if stored["backint-sha256"] != checksum {
+				t.Errorf("metadata of version %s = %v, want the checksum", got.VersionId, stored)
+			}
+			for key, value := range tt.metadata {
+				if stored[key] != value {
+					t.Errorf("metadata %s = %q, want %q kept", key, stored[key], value)
+				}
+			}
+			// The tags are not copied with the parts of a multipart copy
+			if tt.wantRanges > 0 && bucket.tagging != "team=basis" {
+				t.Errorf("tags of the multipart copy = %q, want the configured tags", bucket.tagging)
+			}
+			if strings.Join(bucket.deleted, ",") != strings.Join(tt.wantDeleted, ",") {
+				t.Errorf("deleted versions %v, want %v", bucket.deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestStoreChecksumWithoutVersioning(t *testing.T) {
+	setupChecksumLog(t)
+	bucket, target := newChecksumBucket(t, 16, map[string]string{})
+	bucket.metadata[""] = bucket.metadata["v1"]
+
+	if _, err := storeChecksum(target, "backup/data", "/tmp/pipe", "", "0123abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if bucket.copies != 1 || len(bucket.deleted) != 0 {
+		t.Errorf("%d copies and deleted versions %v, want the object replaced by one copy",
+			bucket.copies, bucket.deleted)
+	}
+}
+
+func TestGetCopyPartRanges(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int64
+		wantParts int
+		wantLast  string
+	}{
+		{"one byte above a single copy", MAX_COPY_OBJECT_SIZE + 1, 6, "bytes=5368709120-5368709120"},
+		{"multiple of the part size", 8 * COPY_PART_SIZE, 8, fmt.Sprintf("bytes=%d-%d", 7*COPY_PART_SIZE, 8*COPY_PART_SIZE-1)},
+		{"more than the maximum number of parts", 20 * 1024 * COPY_PART_SIZE, 10000, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges := getCopyPartRanges(tt.size)
+			if len(ranges) > tt.wantParts || (tt.wantLast != "" && len(ranges) != tt.wantParts) {
+				t.Fatalf("%d parts, want %d", len(ranges), tt.wantParts)
+			}
+			if tt.wantLast != "" && ranges[len(ranges)-1] != tt.wantLast {
+				t.Errorf("last range %q, want %q", ranges[len(ranges)-1], tt.wantLast)
+			}
+			// The ranges cover the object without gaps
+			next := int64(0)
+			for _, byteRange := range ranges {
+				var first, last int64
+				if _, err := fmt.Sscanf(byteRange, "bytes=%d-%d", &first, &last); err != nil {
+					t.Fatal(err)
+				}
+				if first != next || last < first {
+					t.Fatalf("range %q does not continue at %d", byteRange, next)
+				}
+				next = last + 1
+			}
+			if next != tt.size {
+				t.Errorf("ranges end at %d, want %d", next, tt.size)
+			}
+		})
+	}
+}
+
+func TestGetFileChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog")
+	content := []byte("SAP HANA backup catalog")
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	checksum, err := getFileChecksum(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+	if checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("getFileChecksum() = %s, want %s", checksum, hex.EncodeToString(sum[:]))
+	}
+	// The upload reads the file from its start
+	if data, _ := io.ReadAll(file); string(data) != string(content) {
+		t.Errorf("file read after the checksum = %q, want %q", data, content)
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	tests := []struct {
+		name        string
+		expected    string
+		calculated  string
+		wantErr     error
+		wantWarning bool
+	}{
+		{"matching checksum", "0123", "0123", nil, false},
+		{"different checksum", "0123", "4567", errChecksumMismatch, false},
+		{"object without checksum", "", "4567", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := setupChecksumLog(t)
+			err := verifyChecksum("backup/data", tt.expected, tt.calculated)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("verifyChecksum() = %v, want %v", err, tt.wantErr)
+			}
+			warned := false
+			for _, entry := range hook.AllEntries() {
+				warned = warned || entry.Level == logrus.WarnLevel
+			}
+			if warned != tt.wantWarning {
+				t.Errorf("warning logged = %v, want %v", warned, tt.wantWarning)
+			}
+		})
+	}
+}
@@ -15,7 +15,12 @@
 package cos
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -30,27 +35,215 @@ import (
 )
 
 /*
-Uploading one object to IBM Cloud Object Storage
+Uploading the data of one pipe to IBM Cloud Object Storage.
+The size of a pipe is not known in advance, the part size
+is derived from the expected size of the pipe.
 */
 func Upload(
 	s3Session *session.Session,
 	s3Client *s3.S3,
 	sourcePath string,
 	Key string,
+	expectedSize int64,
+) Result {
+	global.Logger.Debug("Opening the input pipe for reading.")
+	rPipe, err := os.OpenFile(sourcePath, os.O_CREATE, os.ModeNamedPipe)
+	global.CheckForError(
+		err,
+		fmt.Sprintf("Error opening named pipe '%s'", sourcePath),
+		global.FAILURE,
+	)
+	defer func() {
+		_ = rPipe.Close()
+	}()
+
+	schedule := getPipePartSchedule(sourcePath, expectedSize)
+	return upload(s3Session, s3Client, sourcePath, Key, rPipe, schedule, -1, "")
+}
+
+/*
+Getting the part sizes for uploading a pipe.
+Without expected size, the part size grows during the upload,
+so that large pipes do not exceed the maximum number of parts.
+*/
+func getPipePartSchedule(sourcePath string, expectedSize int64) partSchedule {
+	if expectedSize <= 0 {
+		maxPartSize := getGrowingPartSizeLimit()
+		global.Logger.Info(fmt.Sprintf(
+			"'%s': Size not known, doubling the part size every %d parts up to %d bytes.",
+			sourcePath,
+			PART_SIZE_GROWTH_INTERVAL,
+			maxPartSize,
+		))
+		return partSchedule{
+			partSize:    config.BackintConfig.MultipartChunksize(),
+			growing:     true,
+			maxPartSize: maxPartSize,
+		}
+	}
+	return partSchedule{partSize: getPipePartSize(sourcePath, expectedSize)}
+}
+
+/*
+Getting the limit of growing part sizes.
+All concurrent parts of one pipe must fit into max_inflight_memory
+or DEFAULT_GROWING_PART_MEMORY, but a part is never smaller
+than the multipart chunksize.
+*/
+func getGrowingPartSizeLimit() int64 {
+	memory := config.BackintConfig.MaxInflightMemory()
+	if memory <= 0 {
+		memory = DEFAULT_GROWING_PART_MEMORY
+	}
+	limit := memory / int64(max(config.BackintConfig.MaxConcurrency(), 1))
+	return min(max(limit, config.BackintConfig.MultipartChunksize()), MAX_UPLOAD_PART_SIZE)
+}
+
+/*
+Getting the part size for uploading a pipe.
+The part size is increased if the expected size of the pipe
+plus a margin does not fit into the maximum number of parts.
+*/
+func getPipePartSize(sourcePath string, expectedSize int64) int64 {
+	partSize := config.BackintConfig.MultipartChunksize()
+	if expectedSize <= 0 {
+		return partSize
+	}
+
+	expectedSize += expectedSize / 100 * EXPECTED_PIPE_SIZE_MARGIN
+	minPartSize := expectedSize/s3manager.MaxUploadParts + 1
+	if minPartSize <= partSize {
+		return partSize
+	}
+
+	partSize = min(minPartSize, MAX_UPLOAD_PART_SIZE)
+	global.Logger.Info(fmt.Sprintf(
+		"'%s': Increasing the part size to %d bytes for an expected size of %d bytes.",
+		sourcePath,
+		partSize,
+		expectedSize,
+	))
+	return partSize
+}
+
+/*
+Uploading one regular file to IBM Cloud Object Storage.
+As the size is known, files smaller than the multipart chunksize
+are uploaded with a single PUT.
+*/
+func UploadFile(
+	s3Session *session.Session,
+	s3Client *s3.S3,
+	sourcePath string,
+	Key string,
+) Result {
+	global.Logger.Debug("Opening the input file for reading.")
+	file, err := os.Open(sourcePath)
+	if err != nil {
+		global.Logger.Error(fmt.Sprintf(
+			"Error opening file '%s'. Error: %s", sourcePath, err,
+		))
+		return Result{Err: err, SourcePath: sourcePath, Key: Key}
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	info, err := file.Stat()
+	if err == nil && !info.Mode().IsRegular() {
+		err = fmt.Errorf("'%s' is not a regular file", sourcePath)
+	}
+	if err != nil {
+		global.Logger.Error(fmt.Sprintf(
+			"Error reading file '%s'. Error: %s", sourcePath, err,
+		))
+		return Result{Err: err, SourcePath: sourcePath, Key: Key}
+	}
+
+	// The checksum is sent with the upload
+	checksum, err := getFileChecksum(file)
+	if err != nil {
+		global.Logger.Error(fmt.Sprintf(
+			"Error reading file '%s'. Error: %s", sourcePath, err,
+		))
+		return Result{Err: err, SourcePath: sourcePath, Key: Key}
+	}
+
+	schedule := partSchedule{partSize: getFilePartSize(info.Size())}
+	return upload(s3Session, s3Client, sourcePath, Key, file, schedule, info.Size(), checksum)
+}
+
+/*
+Calculating the checksum of a file and rewinding it for the upload
+*/
+func getFileChecksum(file *os.File) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+/*
+Getting the part size for uploading a file of a given size
+*/
+func getFilePartSize(size int64) int64 {
+	partSize := config.BackintConfig.MultipartChunksize()
+
+	// Files fitting into one part are uploaded with a single PUT
+	if size+FILE_ENCODING_RESERVE < partSize {
+		return max(size+FILE_ENCODING_RESERVE, s3manager.MinUploadPartSize)
+	}
+
+	// Keeping the number of parts of large files below the maximum
+	if size/partSize >= s3manager.MaxUploadParts {
+		partSize = size/(s3manager.MaxUploadParts-1) + 1
+	}
+	return partSize
+}
+
+/*
+Uploading one object to IBM Cloud Object Storage.
+If a secondary storage is configured, the object is uploaded
+to both storages concurrently.
+If the expected size is known (not negative),
+the upload is aborted if the number of bytes read from source differs.
+A checksum known in advance is sent with the upload,
+otherwise it is stored after the upload.
+*/
+func upload(
+	s3Session *session.Session,
+	s3Client *s3.S3,
+	sourcePath string,
+	Key string,
+	source io.Reader,
+	schedule partSchedule,
+	expectedSize int64,
+	checksum string,
 ) Result {
 	global.Logger.Info(
 		fmt.Sprintf("Uploading data from '%s' to '%s'.", sourcePath, Key),
 	)
 	startTime := time.Now()
-	global.Logger.Debug(fmt.Sprintf("multipart chunksize: %d", config.BackintConfig.MultipartChunksize()))
-	uploader := s3manager.NewUploader(s3Session, func(u *s3manager.Uploader) {
-		u.PartSize = config.BackintConfig.MultipartChunksize()
-		u.Concurrency = config.BackintConfig.MaxConcurrency()
-	})
-
-	uploadInputInfo, readerFromPipe := setupUploadInputInfo(Key, sourcePath)
+	global.Logger.Debug(fmt.Sprintf("multipart chunksize: %d", schedule.partSize))
+
+	// A changed size aborts the upload, no incomplete version is stored
+	if expectedSize >= 0 {
+		source = &sizeCheckingReader{
+			r:          source,
+			sourcePath: sourcePath,
+			expected:   expectedSize,
+		}
+	}
+	uploadInputInfo, readerFromPipe, closeBody := setupUploadInputInfo(Key, sourcePath, source, checksum)
 
-	uploadResult, copyError := uploader.Upload(&uploadInputInfo)
+	targets := getUploadTargets(s3Session, s3Client)
+	uploadResults, uploadErrors := uploadToTargets(targets, uploadInputInfo, schedule)
+	// The body is not read anymore, a failed upload may have stopped early
+	closeBody(errors.Join(uploadErrors...))
 
 	global.Logger.Debug(fmt.Sprintf(
 		"Bytes written: '%d'.",
@@ -60,40 +253,121 @@ func Upload(
 	endTime := time.Now()
 	duration := endTime.Sub(startTime).Seconds()
 
-	if copyError != nil {
-		global.Logger.Error(fmt.Sprintf(
-			"Error uploading from %s. Error: %s",
+	checksum = readerFromPipe.checksum()
+	var copyError error
+	if expectedSize >= 0 && readerFromPipe.noOfbytes != expectedSize {
+		copyError = fmt.Errorf(
+			"size of '%s' changed during backup, expected %d bytes, read %d bytes",
 			sourcePath,
-			copyError),
+			expectedSize,
+			readerFromPipe.noOfbytes,
 		)
+		global.Logger.Error(copyError.Error())
 		return Result{
 			Err:        copyError,
-			Duration:   float64(0),
-			SourceSize: int64(0),
-			TargetSize: int64(0),
 			SourcePath: sourcePath,
 			Key:        Key,
-			ETag:       "",
 		}
-	} else {
+	}
+	succeeded := []int{}
+	uploaded := make([]uploadedObject, len(targets))
+	for i, target := range targets {
+		err := uploadErrors[i]
+		if err == nil {
+			// A backup without checksum is not reported as saved
+			uploaded[i], err = storeChecksum(
+				target,
+				Key,
+				sourcePath,
+				aws.StringValue(uploadResults[i].VersionID),
+				checksum,
+			)
+			if err != nil {
+				err = fmt.Errorf("error storing the checksum: %w", err)
+				// No object without checksum is left behind
+				removeUploadedObject(target, Key, aws.StringValue(uploadResults[i].VersionID))
+			}
+		}
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf(
+				"Error uploading from %s to bucket '%s'. Error: %s",
+				sourcePath,
+				target.bucket,
+				err),
+			)
+			if copyError == nil {
+				copyError = err
+			}
+			continue
+		}
 		global.Logger.Info(fmt.Sprintf(
-			"Successfully uploaded '%s' to '%s'.",
+			"Successfully uploaded '%s' to '%s' in bucket '%s'.",
 			sourcePath,
-			Key),
+			Key,
+			target.bucket),
 		)
-		size := getCosObjectSize(s3Client, Key)
-		ETag := *uploadResult.ETag
+		succeeded = append(succeeded, i)
+	}
 
+	if len(succeeded) < getWriteQuorum(len(targets)) {
+		global.Logger.Error(fmt.Sprintf(
+			"'%s' uploaded to %d of %d buckets, the write quorum of %d is not met.",
+			Key,
+			len(succeeded),
+			len(targets),
+			getWriteQuorum(len(targets))),
+		)
+		// The backup failed, the objects uploaded are not restored anymore
+		for _, i := range succeeded {
+			removeUploadedObject(targets[i], Key, uploaded[i].VersionId)
+		}
 		return Result{
-			Err:        nil,
-			Duration:   duration,
-			SourceSize: readerFromPipe.noOfbytes,
-			TargetSize: size,
+			Err:        copyError,
+			Duration:   float64(0),
+			SourceSize: int64(0),
+			TargetSize: int64(0),
 			SourcePath: sourcePath,
 			Key:        Key,
-			ETag:       ETag,
+			ETag:       "",
 		}
 	}
+
+	// Reporting the first storage the object was uploaded to successfully
+	object := uploaded[succeeded[0]]
+	return Result{
+		Err:        nil,
+		Duration:   duration,
+		SourceSize: readerFromPipe.noOfbytes,
+		TargetSize: object.size,
+		SourcePath: sourcePath,
+		Key:        Key,
+		ETag:       object.ETag,
+		VersionId:  object.VersionId,
+		Checksum:   checksum,
+	}
+}
+
+/*
+Uploading the data to one storage.
+The s3manager uploads all parts with the same size,
+growing parts are uploaded part by part.
+*/
+func uploadToTarget(
+	s3Session *session.Session,
+	input *s3manager.UploadInput,
+	budget *uploadBudget,
+) (*s3manager.UploadOutput, error) {
+	if budget.schedule.growing {
+		return uploadGrowingParts(s3Session, input, budget)
+	}
+	uploader := s3manager.NewUploader(s3Session, func(u *s3manager.Uploader) {
+		u.PartSize = budget.schedule.partSize
+		u.Concurrency = Scheduler.StreamConcurrency(budget.schedule.partSize)
+		u.RequestOptions = append(u.RequestOptions,
+			budget.requestOption(),
+		)
+	})
+	return uploader.Upload(input)
 }
 
 /*
@@ -116,29 +390,85 @@ func UploadSingleFile(s3Client *s3.S3, bucket string, source string, key string)
 }
 
 /*
-Deleting multiple objects
+Uploading data held in memory as one object to all storages
 */
-func DeleteMultiple(s3Client *s3.S3, cosObjects []CosObject) []CosObject {
-	var results []CosObject
-	for _, element := range cosObjects {
-		if !element.Found {
-			element.Status = "NOTFOUND"
-			results = append(results, element)
-			continue
+func UploadData(s3Client *s3.S3, Key string, data []byte) error {
+	var errs []error
+	for _, target := range getUploadTargets(nil, s3Client) {
+		input := s3.PutObjectInput{
+			Bucket: aws.String(target.bucket),
+			Key:    aws.String(Key),
+			Body:   bytes.NewReader(data),
 		}
-
-		deleteObjectInput := &s3.DeleteObjectInput{
-			Bucket: aws.String(config.BackintConfig.BucketName()),
-			Key:    aws.String(element.Key),
+		if _, err := target.client.PutObject(&input); err != nil {
+			errs = append(errs, fmt.Errorf("bucket '%s': %w", target.bucket, err))
 		}
-		_, err := s3Client.DeleteObject(deleteObjectInput)
-		element.Status = "DELETED"
+	}
+	return errors.Join(errs...)
+}
+
+/*
+Downloading a small object without multiparts
+*/
+func DownloadData(s3Client *s3.S3, Key string) ([]byte, error) {
+	input := s3.GetObjectInput{
+		Bucket: aws.String(config.BackintConfig.BucketName()),
+		Key:    aws.String(Key),
+	}
+	output, err := s3Client.GetObject(&input)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = output.Body.Close()
+	}()
+	return io.ReadAll(output.Body)
+}
+
+/*
+Getting the key of the manifest stored in the metadata of an object version.
+Returns an empty string for objects without manifest.
+*/
+func GetManifestKey(s3Client *s3.S3, Key string, versionId string) (string, error) {
+	head, err := getHeadObjectInBucket(
+		s3Client,
+		config.BackintConfig.BucketName(),
+		Key,
+		versionId,
+	)
+	if err != nil {
+		return "", err
+	}
+	return getMetadataValue(head.Metadata, METADATA_MANIFEST_KEY), nil
+}
+
+/*
+Getting the list of the objects with a given key prefix
+*/
+func ListObjectsWithPrefix(s3Client *s3.S3, keyPrefix string) ([]*s3.Object, error) {
+	var cosObjectList []*s3.Object
+
+	listObjectsInput := s3.ListObjectsInput{
+		Bucket: aws.String(config.BackintConfig.BucketName()),
+		Prefix: aws.String(keyPrefix),
+	}
+	for {
+		objectsOutput, err := s3Client.ListObjects(&listObjectsInput)
 		if err != nil {
-			element.Status = "ERROR"
+			return nil, err
+		}
+
+		cosObjectList = append(cosObjectList, objectsOutput.Contents...)
+		if !aws.BoolValue(objectsOutput.IsTruncated) || len(objectsOutput.Contents) == 0 {
+			break
+		}
+		// NextMarker is only returned if a delimiter is specified
+		listObjectsInput.Marker = objectsOutput.NextMarker
+		if listObjectsInput.Marker == nil {
+			listObjectsInput.Marker = objectsOutput.Contents[len(objectsOutput.Contents)-1].Key
 		}
-		results = append(results, element)
 	}
-	return results
+	return cosObjectList, nil
 }
 
 /*
@@ -182,16 +512,15 @@ func RunBucketExists(s3Client *s3.S3, bucket string) (bool, error) {
 }
 
 /*
-Checking if a specific object exists
+Checking if a version with a specific ETag exists for a given object.
+Returns the error if the versions could not be listed.
 */
-func BackupExists(s3Client *s3.S3, ETag string) bool {
-	cosObjectList := ListObjectsOfBucket(s3Client)
-	for _, element := range cosObjectList {
-		if element.ETag == &ETag {
-			return true
-		}
+func BackupExists(s3Client *s3.S3, Key string, ETag string) (bool, error) {
+	if Key == "" {
+		return false, nil
 	}
-	return false
+	_, versionId, err := GetObjectVersionForKey(s3Client, Key, ETag)
+	return versionId != "", err
 }
 
 /*
@@ -237,48 +566,83 @@ func RunIsBucketVersioning(s3Client *s3.S3, bucket string) (string, error) {
 }
 
 /*
-Getting the ETag of the latest version of a given object
+Getting the ETag and the version id of a given object.
+If no ETag is given, the latest version is returned,
+otherwise the version with the given ETag.
+Returns empty strings if no matching version exists,
+and the error if the versions could not be listed.
 */
-func GetETagOfLatestVersionForKey(s3Client *s3.S3, Key string) string {
-	global.Logger.Info(fmt.Sprintf("Getting latest version for '%s'.", Key))
-
-	objectVersions := listObjectVersions(s3Client, Key, "")
-
-	for _, v := range objectVersions {
-		if *v.Key == Key && *v.IsLatest {
-			ETag := strings.ReplaceAll(*v.ETag, "\"", "")
-			global.Logger.Info(
-				fmt.Sprintf("Latest version for key '%s' has entity tag '%s'.",
-					Key,
-					ETag,
-				),
-			)
-			return ETag
-		}
+func GetObjectVersionForKey(s3Client *s3.S3, Key string, ETag string) (string, string, error) {
+	return getObjectVersionInBucket(s3Client, config.BackintConfig.BucketName(), Key, ETag)
+}
+
+/*
+Getting the ETag and the version id of a given object in a given bucket.
+Returns empty strings if no matching version exists,
+and the error if the versions could not be listed.
+*/
+func getObjectVersionInBucket(
+	s3Client *s3.S3,
+	bucket string,
+	Key string,
+	ETag string,
+) (string, string, error) {
+	versions, err := listObjectVersions(s3Client, bucket, Key)
+	if err != nil {
+		return "", "", err
 	}
-	global.Logger.Info(fmt.Sprintf("No version found for key '%s'.", Key))
-	return ""
+	versionETag, versionId := findObjectVersion(versions, Key, ETag)
+	return versionETag, versionId, nil
 }
 
 /*
-Getting the list of all objects for a given bucket
+Searching the version of a given object in a list of versions.
+If no ETag is given, the latest version is returned,
+otherwise the newest version with the given ETag.
+Versions of other keys with the same prefix are skipped.
+If a delete marker is the latest version, only an ETag matches.
 */
-func ListObjectsOfBucket(s3Client *s3.S3) []*s3.Object {
-	bucket := config.BackintConfig.BucketName()
+func findObjectVersion(versions []*s3.ObjectVersion, Key string, ETag string) (string, string) {
+	ETag = strings.ReplaceAll(ETag, "\"", "")
+	if ETag == "" {
+		global.Logger.Info(fmt.Sprintf("Getting latest version for '%s'.", Key))
+	} else {
+		global.Logger.Info(fmt.Sprintf(
+			"Getting version with entity tag '%s' for '%s'.", ETag, Key,
+		))
+	}
 
-	global.Logger.Info(
-		fmt.Sprintf("Creating list of all objects for bucket '%s'.", bucket),
-	)
+	var found *s3.ObjectVersion
+	for _, v := range versions {
+		if aws.StringValue(v.Key) != Key {
+			continue
+		}
+		versionETag := strings.ReplaceAll(aws.StringValue(v.ETag), "\"", "")
+		if ETag == "" && aws.BoolValue(v.IsLatest) {
+			found = v
+			break
+		}
+		// The same data may have been uploaded more than once
+		if ETag != "" && versionETag == ETag && (found == nil ||
+			aws.TimeValue(v.LastModified).After(aws.TimeValue(found.LastModified))) {
+			found = v
+		}
+	}
+	if found == nil {
+		global.Logger.Info(fmt.Sprintf("No matching version found for key '%s'.", Key))
+		return "", ""
+	}
 
-	cosObjectList, err := RunListObjectsOfBucket(s3Client, bucket)
-	global.CheckForError(err,
-		fmt.Sprintf(
-			"Could not discover objects from bucket '%s'. Error: %s",
-			bucket,
-			err),
-		global.FAILURE,
+	versionETag := strings.ReplaceAll(aws.StringValue(found.ETag), "\"", "")
+	versionId := aws.StringValue(found.VersionId)
+	global.Logger.Info(
+		fmt.Sprintf("Version of key '%s' with entity tag '%s' is '%s'.",
+			Key,
+			versionETag,
+			versionId,
+		),
 	)
-	return cosObjectList
+	return versionETag, versionId
 }
 
 /*
@@ -322,63 +686,68 @@ func RunGetBucketLifecycleRules(s3Client *s3.S3, bucket string) ([]*s3.Lifecycle
 }
 
 /*
-Getting the list of versions for a given object
+Listing all versions of all objects with a given key prefix
+*/
+func ListObjectVersions(s3Client *s3.S3, keyPrefix string) ([]*s3.ObjectVersion, error) {
+	return listObjectVersions(s3Client, config.BackintConfig.BucketName(), keyPrefix)
+}
+
+/*
+Listing all versions of all objects with a given key prefix in a given bucket
 */
 func listObjectVersions(
 	s3Client *s3.S3,
+	bucket string,
 	keyPrefix string,
-	keyMarker string,
-) []*s3.ObjectVersion {
-
-	global.Logger.Info(
-		fmt.Sprintf("Getting the list of object versions for key with prefix '%s'.",
-			keyPrefix),
-	)
-	bucket := config.BackintConfig.BucketName()
+) ([]*s3.ObjectVersion, error) {
+	global.Logger.Info(fmt.Sprintf(
+		"Getting all object versions for key prefix '%s' in bucket '%s'.",
+		keyPrefix,
+		bucket,
+	))
 
-	listObjectVersionsInput := s3.ListObjectVersionsInput{}
-	if keyMarker != "" {
-		listObjectVersionsInput = s3.ListObjectVersionsInput{
-			Bucket:    aws.String(bucket),
-			Prefix:    aws.String(keyPrefix),
-			KeyMarker: aws.String(keyMarker)}
-	} else {
-		listObjectVersionsInput = s3.ListObjectVersionsInput{
-			Bucket: aws.String(bucket),
-			Prefix: aws.String(keyPrefix)}
+	var versions []*s3.ObjectVersion
+	listObjectVersionsInput := s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(keyPrefix),
 	}
+	for {
+		listObjectVersionsOut, err := s3Client.ListObjectVersions(&listObjectVersionsInput)
+		if err != nil {
+			return nil, err
+		}
 
-	listObjectVersionsOut, err := s3Client.ListObjectVersions(&listObjectVersionsInput)
-	global.CheckForError(
-		err,
-		fmt.Sprintf("Error discovering versions for '%s'.", keyPrefix),
-		global.FAILURE,
-	)
-
-	if len(listObjectVersionsOut.Versions) == 0 {
-		global.Logger.Error("No versions found")
-	}
-	var versions []*s3.ObjectVersion
-	versions = append(versions, listObjectVersionsOut.Versions...)
-	for _, v := range versions {
-		global.Logger.Debug("Version :" + *v.VersionId)
+		versions = append(versions, listObjectVersionsOut.Versions...)
+		if !aws.BoolValue(listObjectVersionsOut.IsTruncated) {
+			break
+		}
+		listObjectVersionsInput.KeyMarker = listObjectVersionsOut.NextKeyMarker
+		listObjectVersionsInput.VersionIdMarker = listObjectVersionsOut.NextVersionIdMarker
 	}
-	return versions
+	return versions, nil
 }
 
 /*
-Getting the HeadObject for a given object
+Getting the HeadObject for a given version of an object in a given bucket
+The latest version is used if no version id is given
 */
-func getHeadObject(s3Client *s3.S3, Key string) *s3.HeadObjectOutput {
+func getHeadObjectInBucket(
+	s3Client *s3.S3,
+	bucket string,
+	Key string,
+	versionId string,
+) (*s3.HeadObjectOutput, error) {
 	headObj := s3.HeadObjectInput{
-		Bucket: aws.String(config.BackintConfig.BucketName()),
+		Bucket: aws.String(bucket),
 		Key:    aws.String(Key),
 	}
+	if versionId != "" {
+		headObj.VersionId = aws.String(versionId)
+	}
 
 	result, err := s3Client.HeadObject(&headObj)
-	global.CheckForError(err,
-		fmt.Sprintf("Error getting HeadObject for Key '%s'.", Key),
-		global.FAILURE,
-	)
-	return result
+	if err != nil {
+		return nil, fmt.Errorf("error getting HeadObject for key '%s': %w", Key, err)
+	}
+	return result, nil
 }
@@ -0,0 +1,187 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	MiB int64 = 1024 * 1024
+	GiB int64 = 1024 * MiB
+	TiB int64 = 1024 * GiB
+)
+
+/*
+Setting the multipart chunksize for one test
+*/
+func setupChunksize(t *testing.T, chunksize int64) {
+	t.Helper()
+	if global.Logger == nil {
+		global.Logger = logrus.New()
+		global.Logger.SetOutput(io.Discard)
+	}
+	previous := config.BackintConfig
+	config.BackintConfig = config.BackintConfigT{
+		"multipart_chunksize": strconv.FormatInt(chunksize, 10),
+	}
+	t.Cleanup(func() { config.BackintConfig = previous })
+}
+
+func TestGetFilePartSize(t *testing.T) {
+	tests := []struct {
+		name string
+		size int64
+		want int64
+	}{
+		{"empty file", 0, s3manager.MinUploadPartSize},
+		{"small file", 10 * MiB, 10*MiB + FILE_ENCODING_RESERVE},
+		{"fitting into one part", 62 * MiB, 62*MiB + FILE_ENCODING_RESERVE},
+		{"reserve exceeding the chunksize", 63 * MiB, 64 * MiB},
+		{"several parts", 100 * GiB, 64 * MiB},
+		{"just below the maximum number of parts", (s3manager.MaxUploadParts - 1) * 64 * MiB, 64 * MiB},
+		{"maximum number of parts", s3manager.MaxUploadParts * 64 * MiB, s3manager.MaxUploadParts*64*MiB/(s3manager.MaxUploadParts-1) + 1},
+		{"larger than the chunksize allows", TiB, TiB/(s3manager.MaxUploadParts-1) + 1},
+	}
+
+	setupChunksize(t, 64*MiB)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getFilePartSize(tt.size)
+			if got != tt.want {
+				t.Errorf("getFilePartSize(%d) = %d, want %d", tt.size, got, tt.want)
+			}
+			if parts := (tt.size + got - 1) / got; parts > s3manager.MaxUploadParts {
+				t.Errorf("%d bytes need %d parts of %d bytes", tt.size, parts, got)
+			}
+		})
+	}
+}
+
+func TestGetPipePartSize(t *testing.T) {
+	tests := []struct {
+		name         string
+		expectedSize int64
+		want         int64
+	}{
+		{"size not known", -1, 64 * MiB},
+		{"empty pipe", 0, 64 * MiB},
+		{"small pipe", 10 * MiB, 64 * MiB},
+		{"fitting into the chunksize", 400 * GiB, 64 * MiB},
+		{"larger than the chunksize allows", TiB, TiB/100*(100+EXPECTED_PIPE_SIZE_MARGIN)/s3manager.MaxUploadParts + 1},
+		{"larger than the maximum part size", 100 * TiB, MAX_UPLOAD_PART_SIZE},
+	}
+
+	setupChunksize(t, 64*MiB)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getPipePartSize("/tmp/pipe", tt.expectedSize)
+			if got != tt.want {
+				t.Errorf("getPipePartSize(%d) = %d, want %d", tt.expectedSize, got, tt.want)
+			}
+			// The expected size plus the margin must fit into the parts
+			margin := tt.expectedSize / 100 * EXPECTED_PIPE_SIZE_MARGIN
+			if got < MAX_UPLOAD_PART_SIZE && got*s3manager.MaxUploadParts < tt.expectedSize+margin {
+				t.Errorf("%d parts of %d bytes do not fit %d bytes", s3manager.MaxUploadParts, got, tt.expectedSize+margin)
+			}
+		})
+	}
+}
+
+func TestGetPipePartSchedule(t *testing.T) {
+	setupChunksize(t, 64*MiB)
+
+	schedule := getPipePartSchedule("/tmp/pipe", -1)
+	if !schedule.growing || schedule.partSize != 64*MiB {
+		t.Errorf("schedule without expected size = %+v, want growing parts of the chunksize", schedule)
+	}
+	schedule = getPipePartSchedule("/tmp/pipe", TiB)
+	if schedule.growing || schedule.partSize != getPipePartSize("/tmp/pipe", TiB) {
+		t.Errorf("schedule with expected size = %+v, want fixed parts", schedule)
+	}
+}
+
+func TestPartScheduleSize(t *testing.T) {
+	growing := partSchedule{partSize: 64 * MiB, growing: true}
+	fixed := partSchedule{partSize: 64 * MiB}
+
+	tests := []struct {
+		name       string
+		schedule   partSchedule
+		partNumber int64
+		want       int64
+	}{
+		{"fixed first part", fixed, 1, 64 * MiB},
+		{"fixed last part", fixed, s3manager.MaxUploadParts, 64 * MiB},
+		{"growing first part", growing, 1, 64 * MiB},
+		{"growing end of first interval", growing, PART_SIZE_GROWTH_INTERVAL, 64 * MiB},
+		{"growing start of second interval", growing, PART_SIZE_GROWTH_INTERVAL + 1, 128 * MiB},
+		{"growing third interval", growing, 2*PART_SIZE_GROWTH_INTERVAL + 1, 256 * MiB},
+		{"growing up to the maximum", growing, s3manager.MaxUploadParts, MAX_UPLOAD_PART_SIZE},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.size(tt.partNumber); got != tt.want {
+				t.Errorf("size(%d) = %d, want %d", tt.partNumber, got, tt.want)
+			}
+		})
+	}
+
+	// The growing parts must hold far more than the fixed parts
+	var total int64
+	for partNumber := int64(1); partNumber <= s3manager.MaxUploadParts; partNumber++ {
+		total += growing.size(partNumber)
+	}
+	if total < 10*TiB {
+		t.Errorf("growing parts hold %d bytes, want at least %d", total, 10*TiB)
+	}
+}
+
+func TestReadUploadPart(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		size    int64
+		want    string
+		wantErr error
+	}{
+		{"part of the stream", "0123456789", 4, "0123", nil},
+		{"stream of exactly one part", "0123456789", 10, "0123456789", nil},
+		{"end of the stream", "0123456789", 16, "0123456789", io.EOF},
+		{"empty stream", "", 16, "", io.EOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readUploadPart(strings.NewReader(tt.data), tt.size)
+			if err != tt.wantErr {
+				t.Errorf("readUploadPart() error = %v, want %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("readUploadPart() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// lz4 compression levels in the order of the configured compression_level
+var lz4Levels = []lz4.CompressionLevel{
+	lz4.Level1,
+	lz4.Level2,
+	lz4.Level3,
+	lz4.Level4,
+	lz4.Level5,
+	lz4.Level6,
+	lz4.Level7,
+	lz4.Level8,
+	lz4.Level9,
+}
+
+/*
+Setting up the streaming compression of an upload stream.
+The data read from the source is compressed asynchronously,
+the compressed data can be read from the returned reader.
+The reader must be closed if the upload stops reading,
+otherwise the compressing goroutine blocks forever.
+*/
+func newCompressingReader(src io.Reader) (*io.PipeReader, map[string]*string, error) {
+	algorithm := config.BackintConfig.Compression()
+	level := config.BackintConfig.CompressionLevel()
+
+	pr, pw := io.Pipe()
+
+	var compressor io.WriteCloser
+	switch algorithm {
+	case config.COMPRESSION_ZSTD:
+		encoder, err := zstd.NewWriter(pw,
+			zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)),
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		compressor = encoder
+	case config.COMPRESSION_LZ4:
+		writer := lz4.NewWriter(pw)
+		err := writer.Apply(
+			lz4.CompressionLevelOption(lz4Levels[min(level, len(lz4Levels))-1]),
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		compressor = writer
+	default:
+		return nil, nil, fmt.Errorf("unsupported compression algorithm '%s'", algorithm)
+	}
+
+	go func() {
+		_, err := io.Copy(compressor, src)
+		if closeErr := compressor.Close(); err == nil {
+			err = closeErr
+		}
+		_ = pw.CloseWithError(err)
+	}()
+
+	metadata := map[string]*string{
+		METADATA_COMPRESSION: aws.String(algorithm),
+	}
+	return pr, metadata, nil
+}
+
+/*
+Setting up the decompression of a download stream
+based on the algorithm stored in the object metadata.
+The reader must be closed to release the resources of the decoder.
+*/
+func newDecompressingReader(src io.Reader, metadata map[string]*string) (io.ReadCloser, error) {
+	algorithm := getMetadataValue(metadata, METADATA_COMPRESSION)
+	switch algorithm {
+	case config.COMPRESSION_ZSTD:
+		// Decoding synchronously, no goroutines are left behind
+		decoder, err := zstd.NewReader(src, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	case config.COMPRESSION_LZ4:
+		return io.NopCloser(lz4.NewReader(src)), nil
+	}
+	return nil, fmt.Errorf("unsupported compression algorithm '%s'", algorithm)
+}
+
+/*
+Returns true if the object metadata describes a compressed object
+*/
+func isCompressed(metadata map[string]*string) bool {
+	algorithm := getMetadataValue(metadata, METADATA_COMPRESSION)
+	return algorithm != "" && algorithm != config.COMPRESSION_NONE
+}
@@ -0,0 +1,271 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+)
+
+/*
+Setting the compression algorithm and level for one test
+*/
+func setupCompression(t *testing.T, algorithm string, level int) {
+	t.Helper()
+	previous := config.BackintConfig
+	config.BackintConfig = config.BackintConfigT{
+		"compression":       algorithm,
+		"compression_level": strconv.Itoa(level),
+	}
+	t.Cleanup(func() { config.BackintConfig = previous })
+}
+
+func TestCompressionRoundTrip(t *testing.T) {
+	random := make([]byte, 3*1024*1024+5)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatal(err)
+	}
+	text := []byte(strings.Repeat("SAP HANA backup data ", 200000))
+
+	inputs := []struct {
+		name string
+		data []byte
+	}{
+		{"empty stream", nil},
+		{"single byte", []byte{42}},
+		{"compressible data", text},
+		{"random data", random},
+	}
+	settings := []struct {
+		algorithm string
+		level     int
+	}{
+		{config.COMPRESSION_ZSTD, 1},
+		{config.COMPRESSION_ZSTD, 3},
+		{config.COMPRESSION_ZSTD, 22},
+		{config.COMPRESSION_LZ4, 1},
+		{config.COMPRESSION_LZ4, 9},
+		{config.COMPRESSION_LZ4, 22},
+	}
+
+	for _, setting := range settings {
+		for _, input := range inputs {
+			name := setting.algorithm + "/" + strconv.Itoa(setting.level) + "/" + input.name
+			t.Run(name, func(t *testing.T) {
+				setupCompression(t, setting.algorithm, setting.level)
+
+				reader, metadata, err := newCompressingReader(iotest.HalfReader(bytes.NewReader(input.data)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				compressed, err := io.ReadAll(reader)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !isCompressed(metadata) {
+					t.Fatal("isCompressed() = false for the metadata of a compressed stream")
+				}
+				if input.name == "compressible data" && len(compressed) >= len(input.data)/10 {
+					t.Errorf("compressed size = %d for %d bytes of repeated text", len(compressed), len(input.data))
+				}
+
+				decompressor, err := newDecompressingReader(iotest.HalfReader(bytes.NewReader(compressed)), metadata)
+				if err != nil {
+					t.Fatal(err)
+				}
+				plain, err := io.ReadAll(decompressor)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := decompressor.Close(); err != nil {
+					t.Errorf("Close() = %v", err)
+				}
+				if !bytes.Equal(plain, input.data) {
+					t.Errorf("decompressed %d bytes differ from the %d original bytes", len(plain), len(input.data))
+				}
+			})
+		}
+	}
+}
+
+func TestCompressionFailures(t *testing.T) {
+	t.Run("unsupported algorithm for upload", func(t *testing.T) {
+		setupCompression(t, "gzip", 3)
+		if _, _, err := newCompressingReader(bytes.NewReader(nil)); err == nil {
+			t.Error("newCompressingReader() succeeded for an unsupported algorithm")
+		}
+	})
+
+	t.Run("unsupported algorithm for restore", func(t *testing.T) {
+		metadata := map[string]*string{METADATA_COMPRESSION: aws.String("gzip")}
+		if _, err := newDecompressingReader(bytes.NewReader(nil), metadata); err == nil {
+			t.Error("newDecompressingReader() succeeded for an unsupported algorithm")
+		}
+	})
+
+	t.Run("uncompressed metadata", func(t *testing.T) {
+		tests := []map[string]*string{
+			nil,
+			{METADATA_COMPRESSION: aws.String(config.COMPRESSION_NONE)},
+		}
+		for _, metadata := range tests {
+			if isCompressed(metadata) {
+				t.Errorf("isCompressed(%v) = true", metadata)
+			}
+		}
+	})
+
+	t.Run("read error of the source", func(t *testing.T) {
+		setupCompression(t, config.COMPRESSION_ZSTD, 3)
+		sourceErr := errors.New("pipe broken")
+		reader, _, err := newCompressingReader(iotest.ErrReader(sourceErr))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadAll(reader); !errors.Is(err, sourceErr) {
+			t.Errorf("reading the compressed stream returned %v, want %v", err, sourceErr)
+		}
+	})
+
+	t.Run("upload stops reading", func(t *testing.T) {
+		setupCompression(t, config.COMPRESSION_LZ4, 1)
+		src := &countingReader{}
+		reader, _, err := newCompressingReader(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadFull(reader, make([]byte, 1024)); err != nil {
+			t.Fatal(err)
+		}
+		reader.CloseWithError(errors.New("upload failed"))
+
+		// The compressor stops at the next write into the closed pipe,
+		// afterwards the source is not read anymore
+		deadline := time.Now().Add(5 * time.Second)
+		for read := src.n.Load(); ; {
+			time.Sleep(50 * time.Millisecond)
+			current := src.n.Load()
+			if current == read {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("the compressor still reads the source after the upload stopped")
+			}
+			read = current
+		}
+	})
+}
+
+func TestDecompressorClose(t *testing.T) {
+	setupCompression(t, config.COMPRESSION_ZSTD, 3)
+	reader, metadata, err := newCompressingReader(strings.NewReader("SAP HANA backup data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decompressor, err := newDecompressingReader(bytes.NewReader(compressed), metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := decompressor.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// A closed zstd decoder rejects further reads
+	if _, err := decompressor.Read(make([]byte, 16)); err == nil {
+		t.Error("Read() succeeded after the decoder is closed")
+	}
+}
+
+func TestRestoreStreamClosesDecoder(t *testing.T) {
+	tests := []struct {
+		name    string
+		abort   bool
+		wantErr bool
+	}{
+		{"complete download", false, false},
+		{"aborted download", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded := &closingReader{}
+			decode := func(r io.Reader) (io.ReadCloser, error) {
+				decoded.r = r
+				return decoded, nil
+			}
+			stream := newRestoreStream(discardTarget{}, RESTORE_STREAM_BUFFER_SIZE, decode)
+			if _, err := stream.Write([]byte("downloaded part")); err != nil {
+				t.Fatal(err)
+			}
+
+			var err error
+			if tt.abort {
+				err = stream.abort(errors.New("download failed"))
+			} else {
+				err = stream.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("closing the stream returned %v, wantErr %v", err, tt.wantErr)
+			}
+			if !decoded.closed.Load() {
+				t.Error("decoder is not closed")
+			}
+		})
+	}
+}
+
+// Decoder passing the data unchanged and recording its Close
+type closingReader struct {
+	r      io.Reader
+	closed atomic.Bool
+}
+
+func (c *closingReader) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+func (c *closingReader) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+// Endless source of compressible data
+type countingReader struct {
+	n atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(i % 7)
+	}
+	r.n.Add(int64(len(p)))
+	return len(p), nil
+}
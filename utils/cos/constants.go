@@ -0,0 +1,89 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+// Object metadata keys describing the client-side encryption
+const (
+	METADATA_ENCRYPTION_ALGORITHM   = "Backint-Encryption-Algorithm"
+	METADATA_ENCRYPTION_KEY_ID      = "Backint-Encryption-Key-Id"
+	METADATA_ENCRYPTION_WRAPPED_KEY = "Backint-Encryption-Wrapped-Key"
+	METADATA_ENCRYPTION_NONCE       = "Backint-Encryption-Nonce"
+)
+
+// Object metadata key describing the compression algorithm
+const METADATA_COMPRESSION = "Backint-Compression"
+
+// Object metadata key naming the manifest of the backup
+const METADATA_MANIFEST_KEY = "Backint-Manifest-Key"
+
+// Object metadata key holding the SHA-256 checksum of the backup data
+const METADATA_CHECKSUM_SHA256 = "Backint-Sha256"
+
+// Algorithm name stored in the object metadata of encrypted objects
+const ENCRYPTION_ALGORITHM = "AES256-GCM-STREAM"
+
+// Size of the plaintext encrypted in one frame of the encrypted stream
+const ENCRYPTION_FRAME_SIZE = 1024 * 1024
+
+// Length of the random prefix of the nonce used for one encrypted stream
+const ENCRYPTION_NONCE_PREFIX_SIZE = 7
+
+// Size of the buffer used for writing decoded data to pipe
+const RESTORE_STREAM_BUFFER_SIZE = 1024 * 1024
+
+// Name shown for the destination of a verified object
+const VERIFY_DESTINATION_NAME = "verify"
+
+// Maximum number of bytes read at once while the bandwidth is limited
+const THROTTLE_READ_SIZE = 256 * 1024
+
+// Number of parts per concurrent download one object may be ahead
+// of the part written to pipe if restore_buffer_memory is not set
+const REORDER_WINDOW_FACTOR = 2
+
+// Reserve for the overhead of compression and encryption
+// when uploading a file with a single PUT
+const FILE_ENCODING_RESERVE = 1024 * 1024
+
+// Maximum size of one part of a multipart upload
+const MAX_UPLOAD_PART_SIZE int64 = 5 * 1024 * 1024 * 1024
+
+// Maximum size of an object copied with a single request
+const MAX_COPY_OBJECT_SIZE int64 = 5 * 1024 * 1024 * 1024
+
+// Size of one part if an object is copied with a multipart copy
+const COPY_PART_SIZE int64 = 1024 * 1024 * 1024
+
+// Number of parts after which the part size of a pipe
+// with unknown size is doubled
+const PART_SIZE_GROWTH_INTERVAL = 1000
+
+// Memory shared by the growing parts of one pipe
+// if max_inflight_memory is not specified
+const DEFAULT_GROWING_PART_MEMORY int64 = 8 * 1024 * 1024 * 1024
+
+// Additional growth in percent expected for a pipe
+// compared to the expected size
+const EXPECTED_PIPE_SIZE_MARGIN = 50
+
+// Maximum number of objects deleted with one DeleteObjects request
+const MAX_DELETE_OBJECTS = 1000
+
+// Name of the credentials provider using a trusted profile
+const TRUSTED_PROFILE_PROVIDER = "TrustedProfileProviderBackint"
+
+// Share of the lifetime of an access token of a trusted profile
+// left when it is requested again, in percent
+const TRUSTED_PROFILE_REFRESH_WINDOW = 20
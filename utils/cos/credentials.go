@@ -0,0 +1,141 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/credentials"
+	"github.com/IBM/ibm-cos-sdk-go/aws/credentials/ibmiam"
+	"github.com/IBM/ibm-cos-sdk-go/aws/credentials/ibmiam/token"
+)
+
+/*
+Setting up the credentials for the given authentication method.
+HMAC keys are used for signing the requests with signature version 4,
+anonymous requests are not signed,
+the other methods request IAM access tokens using the given HTTP client.
+*/
+func newCredentials(
+	auth authCredentials,
+	httpClient *http.Client,
+) *credentials.Credentials {
+	switch auth.method {
+	case config.AUTH_APIKEY:
+		return ibmiam.NewStaticCredentials(aws.NewConfig().WithHTTPClient(httpClient),
+			auth.authEndpoint,
+			auth.apikey,
+			"",
+		)
+	case config.AUTH_HMAC:
+		return credentials.NewStaticCredentials(
+			auth.accessKeyId,
+			auth.secretAccessKey,
+			"",
+		)
+	case config.AUTH_ANONYMOUS:
+		return credentials.AnonymousCredentials
+	case config.AUTH_TRUSTED_PROFILE:
+		provider, err := newTrustedProfileProvider(
+			httpClient,
+			auth.authEndpoint,
+			auth.trustedProfileId,
+			auth.crTokenPath,
+		)
+		global.CheckForError(
+			err,
+			"Error setting up the trusted profile authentication",
+			global.FAILURE,
+		)
+		return credentials.NewCredentials(provider)
+	default:
+		return nil
+	}
+}
+
+/*
+Setting up the provider exchanging the compute resource token
+for an IAM access token of the trusted profile
+*/
+func newTrustedProfileProvider(
+	httpClient *http.Client,
+	authEndpoint string,
+	trustedProfileId string,
+	crTokenPath string,
+) (*trustedProfileProvider, error) {
+	authenticator, err := core.NewContainerAuthenticatorBuilder().
+		SetCRTokenFilename(crTokenPath).
+		SetIAMProfileID(trustedProfileId).
+		SetURL(authEndpoint).
+		SetClient(httpClient).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+
+	global.Logger.Debug(fmt.Sprintf(
+		"Using trusted profile '%s' with compute resource token '%s'.",
+		trustedProfileId,
+		crTokenPath,
+	))
+	return &trustedProfileProvider{authenticator: authenticator}, nil
+}
+
+/*
+Requesting an access token of the trusted profile.
+The compute resource token is read again for every request,
+the credentials expire once 80% of the lifetime of the token have passed.
+*/
+func (p *trustedProfileProvider) Retrieve() (credentials.Value, error) {
+	response, err := p.authenticator.RequestToken()
+	if err != nil {
+		return credentials.Value{ProviderName: TRUSTED_PROFILE_PROVIDER}, err
+	}
+
+	expiration := time.Unix(response.Expiration, 0)
+	if response.Expiration == 0 {
+		expiration = p.currentTime().Add(time.Duration(response.ExpiresIn) * time.Second)
+	}
+	window := time.Duration(response.ExpiresIn) * time.Second * TRUSTED_PROFILE_REFRESH_WINDOW / 100
+	p.SetExpiration(expiration, window)
+
+	return credentials.Value{
+		Token: token.Token{
+			AccessToken: response.AccessToken,
+			TokenType:   "Bearer",
+			ExpiresIn:   response.ExpiresIn,
+			Expiration:  response.Expiration,
+		},
+		ProviderName: TRUSTED_PROFILE_PROVIDER,
+		ProviderType: "oauth",
+	}, nil
+}
+
+/*
+Getting the time the expiration is compared with
+*/
+func (p *trustedProfileProvider) currentTime() time.Time {
+	if p.CurrentTime != nil {
+		return p.CurrentTime()
+	}
+	return time.Now()
+}
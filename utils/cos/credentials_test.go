@@ -0,0 +1,219 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws/credentials"
+	"github.com/sirupsen/logrus"
+)
+
+// Stub of the IAM token service handing out numbered access tokens
+type stubIam struct {
+	mu        sync.Mutex
+	requests  int
+	lifetime  int64
+	status    int
+	crTokens  []string
+	profileId string
+}
+
+func (s *stubIam) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.crTokens = append(s.crTokens, r.PostForm.Get("cr_token"))
+	s.profileId = r.PostForm.Get("profile_id")
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+	s.requests++
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w,
+		`{"access_token": "token-%d", "refresh_token": "", "token_type": "Bearer", "expires_in": %d, "expiration": %d}`,
+		s.requests,
+		s.lifetime,
+		time.Now().Unix()+s.lifetime,
+	)
+}
+
+/*
+Setting up a trusted profile provider using the stub IAM service
+*/
+func newStubTrustedProfileProvider(t *testing.T, iam *stubIam) *trustedProfileProvider {
+	t.Helper()
+	if global.Logger == nil {
+		global.Logger = logrus.New()
+		global.Logger.SetOutput(io.Discard)
+	}
+	server := httptest.NewServer(iam)
+	t.Cleanup(server.Close)
+
+	crTokenPath := filepath.Join(t.TempDir(), "cr-token")
+	if err := os.WriteFile(crTokenPath, []byte("compute-resource-token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	provider, err := newTrustedProfileProvider(
+		server.Client(),
+		server.URL,
+		"Profile-0000",
+		crTokenPath,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func TestTrustedProfileProvider(t *testing.T) {
+	tests := []struct {
+		name        string
+		lifetime    int64
+		status      int
+		elapsed     time.Duration
+		wantErr     bool
+		wantExpired bool
+	}{
+		{
+			name:     "valid token",
+			lifetime: 3600,
+			elapsed:  47 * time.Minute,
+		},
+		{
+			name:        "token within the refresh window",
+			lifetime:    3600,
+			elapsed:     49 * time.Minute,
+			wantExpired: true,
+		},
+		{
+			name:        "short lived token",
+			lifetime:    5,
+			elapsed:     5 * time.Second,
+			wantExpired: true,
+		},
+		{
+			name:        "failing token service",
+			status:      http.StatusInternalServerError,
+			wantErr:     true,
+			wantExpired: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iam := &stubIam{lifetime: tt.lifetime, status: tt.status}
+			provider := newStubTrustedProfileProvider(t, iam)
+			now := time.Now()
+			provider.CurrentTime = func() time.Time { return now }
+
+			value, err := provider.Retrieve()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Retrieve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if value.ProviderName != TRUSTED_PROFILE_PROVIDER {
+				t.Errorf("ProviderName = %q, want %q", value.ProviderName, TRUSTED_PROFILE_PROVIDER)
+			}
+			now = now.Add(tt.elapsed)
+			if expired := provider.IsExpired(); expired != tt.wantExpired {
+				t.Errorf("IsExpired() = %v after %v, want %v", expired, tt.elapsed, tt.wantExpired)
+			}
+			if tt.wantErr {
+				return
+			}
+			if value.Token.AccessToken != "token-1" || value.Token.TokenType != "Bearer" {
+				t.Errorf("Token = %+v, want Bearer %q", value.Token, "token-1")
+			}
+
+			iam.mu.Lock()
+			defer iam.mu.Unlock()
+			// IsExpired compares the cached expiration only
+			if iam.requests != 1 {
+				t.Errorf("token requests = %d, want 1", iam.requests)
+			}
+			if iam.profileId != "Profile-0000" {
+				t.Errorf("profile_id = %q, want %q", iam.profileId, "Profile-0000")
+			}
+			for _, crToken := range iam.crTokens {
+				if crToken != "compute-resource-token" {
+					t.Errorf("cr_token = %q, want the content of the token file", crToken)
+				}
+			}
+		})
+	}
+}
+
+func TestTrustedProfileCredentialsCache(t *testing.T) {
+	iam := &stubIam{lifetime: 3600}
+	creds := credentials.NewCredentials(newStubTrustedProfileProvider(t, iam))
+
+	for range 3 {
+		value, err := creds.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value.Token.AccessToken != "token-1" {
+			t.Errorf("AccessToken = %q, want %q", value.Token.AccessToken, "token-1")
+		}
+	}
+	if creds.IsExpired() {
+		t.Error("IsExpired() = true for a valid token")
+	}
+
+	iam.mu.Lock()
+	defer iam.mu.Unlock()
+	if iam.requests != 1 {
+		t.Errorf("token requests = %d, want 1", iam.requests)
+	}
+}
+
+func TestTrustedProfileCredentialsRefresh(t *testing.T) {
+	iam := &stubIam{lifetime: 3600}
+	provider := newStubTrustedProfileProvider(t, iam)
+	now := time.Now()
+	provider.CurrentTime = func() time.Time { return now }
+	creds := credentials.NewCredentials(provider)
+
+	for _, want := range []string{"token-1", "token-2"} {
+		value, err := creds.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value.Token.AccessToken != want {
+			t.Errorf("AccessToken = %q, want %q", value.Token.AccessToken, want)
+		}
+		now = now.Add(50 * time.Minute)
+	}
+
+	iam.mu.Lock()
+	defer iam.mu.Unlock()
+	if iam.requests != 2 {
+		t.Errorf("token requests = %d, want 2", iam.requests)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+)
+
+var errObjectLegalHold = errors.New("object version is under legal hold")
+var errObjectRetention = errors.New("object version is under retention")
+
+/*
+Deleting multiple objects.
+The version matching the EBID of every object is deleted permanently,
+up to MAX_DELETE_OBJECTS versions are deleted with one request.
+Objects whose version could not be searched are returned as errors.
+The results have the order of the given objects.
+*/
+func DeleteMultiple(s3Client *s3.S3, cosObjects []CosObject) []CosObject {
+	results := make([]CosObject, len(cosObjects))
+	var found []int
+	for i, element := range cosObjects {
+		switch {
+		case element.Err != nil:
+			// The version could not be searched
+			element.Status = "ERROR"
+			results[i] = element
+		case !element.Found:
+			element.Status = "NOTFOUND"
+			results[i] = element
+		default:
+			found = append(found, i)
+		}
+	}
+
+	for start := 0; start < len(found); start += MAX_DELETE_OBJECTS {
+		batch := found[start:min(start+MAX_DELETE_OBJECTS, len(found))]
+		elements := make([]CosObject, 0, len(batch))
+		for _, i := range batch {
+			elements = append(elements, cosObjects[i])
+		}
+		for j, result := range deleteObjectVersions(s3Client, elements) {
+			results[batch[j]] = result
+		}
+	}
+	return results
+}
+
+/*
+Deleting the versions of a batch of objects with one request
+*/
+func deleteObjectVersions(s3Client *s3.S3, cosObjects []CosObject) []CosObject {
+	bucket := config.BackintConfig.BucketName()
+	global.Logger.Info(fmt.Sprintf(
+		"Deleting %d object versions from bucket '%s'.",
+		len(cosObjects),
+		bucket,
+	))
+
+	identifiers := make([]*s3.ObjectIdentifier, 0, len(cosObjects))
+	for _, element := range cosObjects {
+		identifiers = append(identifiers, &s3.ObjectIdentifier{
+			Key:       aws.String(element.Key),
+			VersionId: aws.String(element.VersionId),
+		})
+	}
+
+	output, err := s3Client.DeleteObjects(&s3.DeleteObjectsInput{
+		Bucket: aws.String(bucket),
+		Delete: &s3.Delete{
+			Objects: identifiers,
+			Quiet:   aws.Bool(true),
+		},
+	})
+
+	// In quiet mode only the versions which could not be deleted are returned
+	failed := make(map[string]*s3.Error)
+	if err == nil {
+		for _, e := range output.Errors {
+			failed[aws.StringValue(e.Key)+"\x00"+aws.StringValue(e.VersionId)] = e
+		}
+	}
+
+	results := make([]CosObject, 0, len(cosObjects))
+	for _, element := range cosObjects {
+		element.Status = "DELETED"
+		if err != nil {
+			element.Status = "ERROR"
+			element.Err = err
+		} else if e, ok := failed[element.Key+"\x00"+element.VersionId]; ok {
+			element.Status = "ERROR"
+			element.Err = getDeleteError(s3Client, element, e)
+		}
+
+		if element.Err != nil {
+			global.Logger.Error(fmt.Sprintf(
+				"'%s': Error deleting version '%s'. Error: %s",
+				element.Key,
+				element.VersionId,
+				element.Err,
+			))
+		} else {
+			global.Logger.Info(fmt.Sprintf(
+				"'%s': Version '%s' deleted.",
+				element.Key,
+				element.VersionId,
+			))
+		}
+		results = append(results, element)
+	}
+	return results
+}
+
+/*
+Getting the reason why a version could not be deleted.
+Versions protected by object lock are reported with the
+legal hold or the retention date.
+*/
+func getDeleteError(s3Client *s3.S3, element CosObject, e *s3.Error) error {
+	legalHold, err := s3Client.GetObjectLegalHold(&s3.GetObjectLegalHoldInput{
+		Bucket:    aws.String(config.BackintConfig.BucketName()),
+		Key:       aws.String(element.Key),
+		VersionId: aws.String(element.VersionId),
+	})
+	if err == nil && legalHold.LegalHold != nil &&
+		aws.StringValue(legalHold.LegalHold.Status) == s3.ObjectLockLegalHoldStatusOn {
+		return errObjectLegalHold
+	}
+
+	retention, err := s3Client.GetObjectRetention(&s3.GetObjectRetentionInput{
+		Bucket:    aws.String(config.BackintConfig.BucketName()),
+		Key:       aws.String(element.Key),
+		VersionId: aws.String(element.VersionId),
+	})
+	if err == nil && retention.Retention != nil &&
+		aws.TimeValue(retention.Retention.RetainUntilDate).After(time.Now()) {
+		return fmt.Errorf("%w until %s (mode %s)",
+			errObjectRetention,
+			aws.TimeValue(retention.Retention.RetainUntilDate).Format(time.RFC3339),
+			aws.StringValue(retention.Retention.Mode),
+		)
+	}
+
+	return fmt.Errorf("%s: %s", aws.StringValue(e.Code), aws.StringValue(e.Message))
+}
@@ -0,0 +1,265 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/credentials"
+	"github.com/IBM/ibm-cos-sdk-go/aws/session"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+)
+
+// Request body of DeleteObjects
+type deleteObjectsRequest struct {
+	Quiet   bool
+	Objects []struct {
+		Key       string
+		VersionId string
+	} `xml:"Object"`
+}
+
+// Error of one version returned by DeleteObjects
+type deleteObjectsError struct {
+	Key       string
+	VersionId string
+	Code      string
+	Message   string
+}
+
+// Stub of a bucket answering DeleteObjects like a bucket with object lock
+type deleteObjectsBucket struct {
+	mu          sync.Mutex
+	batches     []deleteObjectsRequest
+	failAll     bool
+	denied      map[string]string
+	legalHold   map[string]bool
+	retainUntil map[string]time.Time
+}
+
+func (b *deleteObjectsBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	query := r.URL.Query()
+	_, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	version := key + "@" + query.Get("versionId")
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("delete"):
+		if b.failAll {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var request deleteObjectsRequest
+		if err := xml.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b.batches = append(b.batches, request)
+
+		// Quiet mode returns only the errors
+		result := struct {
+			XMLName xml.Name             `xml:"DeleteResult"`
+			Errors  []deleteObjectsError `xml:"Error"`
+		}{}
+		for _, object := range request.Objects {
+			if code, denied := b.denied[object.Key+"@"+object.VersionId]; denied {
+				result.Errors = append(result.Errors, deleteObjectsError{
+					Key: object.Key, VersionId: object.VersionId, Code: code, Message: "denied",
+				})
+			}
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		_ = xml.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodGet && query.Has("legal-hold"):
+		status := "OFF"
+		if b.legalHold[version] {
+			status = "ON"
+		}
+		_, _ = fmt.Fprintf(w, "<LegalHold><Status>%s</Status></LegalHold>", status)
+	case r.Method == http.MethodGet && query.Has("retention"):
+		until, found := b.retainUntil[version]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprintf(w, "<Retention><Mode>COMPLIANCE</Mode><RetainUntilDate>%s</RetainUntilDate></Retention>",
+			until.Format(time.RFC3339))
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+/*
+Setting up a client of a stub bucket for one test
+*/
+func setupDeleteObjects(t *testing.T, bucket *deleteObjectsBucket) *s3.S3 {
+	t.Helper()
+	if global.Logger == nil {
+		global.Logger = logrus.New()
+		global.Logger.SetOutput(io.Discard)
+	}
+	previous := config.BackintConfig
+	config.BackintConfig = config.BackintConfigT{"bucket": "backups"}
+	t.Cleanup(func() { config.BackintConfig = previous })
+
+	server := httptest.NewServer(bucket)
+	t.Cleanup(server.Close)
+	return s3.New(session.Must(session.NewSession(aws.NewConfig().
+		WithEndpoint(server.URL).
+		WithRegion("us-east-1").
+		WithCredentials(credentials.NewStaticCredentials("key-id", "secret", "")).
+		WithS3ForcePathStyle(true).
+		WithMaxRetries(0),
+	)))
+}
+
+/*
+Creating the objects to be deleted, every third one is not found
+*/
+func newDeletedObjects(count int) []CosObject {
+	objects := make([]CosObject, count)
+	for i := range objects {
+		objects[i] = CosObject{
+			Key:         fmt.Sprintf("HDB/TEN/databackup_%d", i),
+			ETag:        fmt.Sprintf("etag-%d", i),
+			VersionId:   fmt.Sprintf("v%d", i),
+			Destination: fmt.Sprintf("/backint/databackup_%d", i),
+			Found:       i%3 != 0,
+		}
+	}
+	return objects
+}
+
+func TestDeleteMultipleBatches(t *testing.T) {
+	tests := []struct {
+		name        string
+		count       int
+		wantBatches []int
+	}{
+		{"nothing found", 1, nil},
+		{"one batch", 30, []int{20}},
+		{"exactly one full batch", 1500, []int{MAX_DELETE_OBJECTS}},
+		{"several batches", 3500, []int{MAX_DELETE_OBJECTS, MAX_DELETE_OBJECTS, 333}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket := &deleteObjectsBucket{}
+			objects := newDeletedObjects(tt.count)
+			results := DeleteMultiple(setupDeleteObjects(t, bucket), objects)
+
+			var batches []int
+			for _, batch := range bucket.batches {
+				batches = append(batches, len(batch.Objects))
+				if !batch.Quiet {
+					t.Error("versions deleted without quiet mode")
+				}
+			}
+			if fmt.Sprint(batches) != fmt.Sprint(tt.wantBatches) {
+				t.Errorf("batches %v, want %v", batches, tt.wantBatches)
+			}
+
+			// The results have the order of the input file
+			if len(results) != len(objects) {
+				t.Fatalf("%d results, want %d", len(results), len(objects))
+			}
+			for i, result := range results {
+				want := "DELETED"
+				if !objects[i].Found {
+					want = "NOTFOUND"
+				}
+				if result.Key != objects[i].Key || result.Status != want || result.Err != nil {
+					t.Fatalf("result %d = %s %s %v, want %s %s", i, result.Key, result.Status, result.Err, objects[i].Key, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteMultipleErrors(t *testing.T) {
+	retainUntil := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	bucket := &deleteObjectsBucket{
+		denied: map[string]string{
+			"HDB/TEN/databackup_1@v1": "AccessDenied",
+			"HDB/TEN/databackup_2@v2": "AccessDenied",
+			"HDB/TEN/databackup_4@v4": "InternalError",
+			"HDB/TEN/databackup_5@v5": "AccessDenied",
+		},
+		legalHold:   map[string]bool{"HDB/TEN/databackup_1@v1": true},
+		retainUntil: map[string]time.Time{"HDB/TEN/databackup_2@v2": retainUntil, "HDB/TEN/databackup_5@v5": time.Now().Add(-time.Hour)},
+	}
+	objects := newDeletedObjects(7)
+	objects[3].Found = true
+	objects[6].Found = false
+	objects[6].Err = errors.New("versions could not be listed")
+
+	results := DeleteMultiple(setupDeleteObjects(t, bucket), objects)
+	tests := []struct {
+		status  string
+		wantErr string
+	}{
+		{"NOTFOUND", ""},
+		{"ERROR", errObjectLegalHold.Error()},
+		{"ERROR", errObjectRetention.Error() + " until " + retainUntil.Format(time.RFC3339) + " (mode COMPLIANCE)"},
+		{"DELETED", ""},
+		{"ERROR", "InternalError: denied"},
+		{"ERROR", "AccessDenied: denied"},
+		{"ERROR", "versions could not be listed"},
+	}
+	if len(results) != len(tests) {
+		t.Fatalf("%d results, want %d", len(results), len(tests))
+	}
+	for i, tt := range tests {
+		gotErr := ""
+		if results[i].Err != nil {
+			gotErr = results[i].Err.Error()
+		}
+		if results[i].Key != objects[i].Key || results[i].Status != tt.status || gotErr != tt.wantErr {
+			t.Errorf("result %d = %s %s %q, want %s %s %q",
+				i, results[i].Key, results[i].Status, gotErr, objects[i].Key, tt.status, tt.wantErr)
+		}
+	}
+	if !errors.Is(results[1].Err, errObjectLegalHold) || !errors.Is(results[2].Err, errObjectRetention) {
+		t.Errorf("object lock errors %v, %v, want legal hold and retention", results[1].Err, results[2].Err)
+	}
+}
+
+func TestDeleteMultipleRequestFailing(t *testing.T) {
+	objects := newDeletedObjects(3)
+	results := DeleteMultiple(setupDeleteObjects(t, &deleteObjectsBucket{failAll: true}), objects)
+	for i, result := range results {
+		if objects[i].Found && (result.Status != "ERROR" || result.Err == nil) {
+			t.Errorf("result %d = %s %v, want an error", i, result.Status, result.Err)
+		}
+		if !objects[i].Found && result.Status != "NOTFOUND" {
+			t.Errorf("result %d = %s, want NOTFOUND", i, result.Status)
+		}
+	}
+}
@@ -22,7 +22,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
 	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
 
 	"github.com/IBM/ibm-cos-sdk-go/aws"
@@ -38,37 +37,114 @@ func Download(s3Client *s3.S3, element CosObject) Result {
 		element.Key),
 	)
 
-	sourceSize := getCosObjectSize(s3Client, element.Key)
+	// Resolving size and metadata, the secondary storage is used
+	// if the object cannot be read from the primary storage
+	head, err := getRestoreHeadObject(s3Client, &element)
+	if err != nil {
+		global.Logger.Error(fmt.Sprintf(
+			"'%s': Error getting the object: %s",
+			element.Key,
+			err,
+		))
+		return Result{
+			Err:        err,
+			Key:        element.Key,
+			ETag:       element.ETag,
+			SourcePath: element.Destination,
+		}
+	}
+	client, bucket := getRestoreSource(s3Client, element)
+	if element.Secondary {
+		global.Logger.Info(fmt.Sprintf(
+			"'%s': Restoring version '%s' from secondary bucket '%s'.",
+			element.Key,
+			element.VersionId,
+			bucket,
+		))
+	}
+
+	sourceSize := aws.Int64Value(head.ContentLength)
+	global.Logger.Debug(fmt.Sprintf(
+		"COS Object size for key '%s' is '%d'.",
+		element.Key,
+		sourceSize,
+	))
 	downloadParts, numParts := generateDownloadParts(
-		s3Client,
+		bucket,
 		sourceSize,
 		element.Key,
+		element.VersionId,
+		getPartsCount(head),
 	)
 
 	startTime := time.Now()
 
-	// Opening destination pipe for writing
-	fifo := openPipeForWriting(element.Destination)
-
-	defer func() {
-		_ = fifo.Close()
-	}()
+	// Opening destination pipe or file for writing,
+	// the data is discarded if the object is only verified
+	var fifo restoreTarget = discardTarget{}
+	pipeBufferSize := RESTORE_STREAM_BUFFER_SIZE
+	if !element.Verify {
+		file := openRestoreDestination(element.Destination)
+		defer func() {
+			_ = file.Close()
+		}()
+		fifo = file
+
+		// Getting the buffer size of the pipe
+		pipeBufferSize = getPipeBufferSize(file)
+	}
 
-	// Getting the buffer size of the pipe
-	pipeBufferSize := getPipeBufferSize(fifo)
+	// Getting the checksum stored with the object
+	expectedChecksum := getMetadataValue(head.Metadata, METADATA_CHECKSUM_SHA256)
+
+	// Calculating the checksum of the data written to pipe
+	checksumTgt := newChecksumTarget(fifo)
+
+	// Decrypting and decompressing the data before it is written to pipe
+	// in case the object is encrypted or compressed on client side
+	var target restoreTarget = checksumTgt
+	var stream *restoreStream
+	metadata := head.Metadata
+	if isEncrypted(metadata) {
+		global.Logger.Info(fmt.Sprintf(
+			"'%s': Object is encrypted with key '%s'.",
+			element.Key,
+			getMetadataValue(metadata, METADATA_ENCRYPTION_KEY_ID),
+		))
+	}
+	if isCompressed(metadata) {
+		global.Logger.Info(fmt.Sprintf(
+			"'%s': Object is compressed with '%s'.",
+			element.Key,
+			getMetadataValue(metadata, METADATA_COMPRESSION),
+		))
+	}
+	if decode := getRestoreDecoder(metadata); decode != nil {
+		stream = newRestoreStream(checksumTgt, pipeBufferSize, decode)
+		target = stream
+	}
 
 	// Initializing asynchronous processing
 	var wgGetObject sync.WaitGroup
 	downloadPartsResults := make(chan DownloadPartResult, numParts)
 
 	// Make sure that not more than the maximum number run concurrently
-	sem := make(chan struct{}, config.BackintConfig.MaxConcurrency())
+	partSize := int64(0)
+	if len(downloadParts) > 0 {
+		partSize = downloadParts[0].size
+	}
+	sem := make(chan struct{}, Scheduler.StreamConcurrency(partSize))
 
-	// Map containing the parts which are already downloaded
-	// but could not yet be written to pipe.
-	// As the download is asynchronously,
+	// Bounding the parts which are downloaded ahead of the part
+	// written to pipe, as the download is asynchronously,
 	// the parts may not have the correct order.
-	partsNotYetWritten := make(ByteMap)
+	window := newReorderWindow(
+		target,
+		element.NextIndex,
+		pipeBufferSize,
+		partSize,
+		cap(sem),
+	)
 
 	// Downloading parts asynchronously
 	// (limited by value of maxConcurrency)
@@ -77,17 +153,16 @@ func Download(s3Client *s3.S3, element CosObject) Result {
 		sem <- struct{}{} // block if maxConcurrency reached
 
 		downloadSingle := DownloadSingePart{
-			fifo:               fifo,
-			partsNotYetWritten: partsNotYetWritten,
-			downloadPart:       downloadPart,
-			nextIndex:          element.NextIndex,
-			pipeBufferSize:     pipeBufferSize,
+			window:       window,
+			downloadPart: downloadPart,
+			eTag:         element.ETag,
+			secondary:    element.Secondary,
 		}
 
 		global.Logger.Debug(fmt.Sprintf("Next index for '%s' is '%d'", fifo.Name(), *element.NextIndex))
 
 		go runDownloadSinglePart(
-			s3Client,
+			client,
 			&wgGetObject,
 			sem,
 			downloadPartsResults,
@@ -104,23 +179,69 @@ func Download(s3Client *s3.S3, element CosObject) Result {
 		close(downloadPartsResults)
 	}(element.Key)
 
-	endTime := time.Now()
-	duration := endTime.Sub(startTime).Seconds()
-
 	downloadedSize := int64(0)
+	downloadedParts := int64(0)
 
 	for r := range downloadPartsResults {
 		if r.err != nil {
+			window.abort()
+			if stream != nil {
+				if streamErr := stream.abort(r.err); streamErr != nil {
+					r.err = streamErr
+				}
+			}
 			global.Logger.Info(fmt.Sprintf("'%s': Error %s", element.Key, r.err))
 			return Result{
 				Err:        r.err,
-				Duration:   duration,
+				Duration:   time.Since(startTime).Seconds(),
 				Key:        element.Key,
 				ETag:       element.ETag,
 				SourcePath: element.Destination,
 			}
 		}
 		downloadedSize += r.size
+		downloadedParts++
+	}
+
+	if stream != nil {
+		if err := stream.Close(); err != nil {
+			global.Logger.Error(fmt.Sprintf(
+				"'%s': Error decoding the downloaded data: %s",
+				element.Key,
+				err,
+			))
+			return Result{
+				Err:        err,
+				Duration:   time.Since(startTime).Seconds(),
+				Key:        element.Key,
+				ETag:       element.ETag,
+				SourcePath: element.Destination,
+			}
+		}
+	}
+
+	duration := time.Since(startTime).Seconds()
+
+	// Never report an incomplete or corrupted restore as successful
+	err = verifyDownloadedSize(element.Key, numParts, downloadedParts, sourceSize, downloadedSize)
+	if err != nil {
+		return Result{
+			Err:        err,
+			Duration:   duration,
+			Key:        element.Key,
+			ETag:       element.ETag,
+			SourcePath: element.Destination,
+		}
+	}
+	err = verifyChecksum(element.Key, expectedChecksum, checksumTgt.checksum())
+	if err != nil {
+		return Result{
+			Err:        err,
+			Duration:   duration,
+			Key:        element.Key,
+			ETag:       element.ETag,
+			SourcePath: element.Destination,
+		}
 	}
 
 	global.Logger.Info(
@@ -135,11 +256,14 @@ func Download(s3Client *s3.S3, element CosObject) Result {
 		SourcePath: element.Destination,
 		SourceSize: sourceSize,
 		TargetSize: downloadedSize,
+		Checksum:   checksumTgt.checksum(),
 	}
 }
 
 /*
 Downloading one single part
+The part is written to pipe while it is downloaded if it is the next one,
+otherwise it is kept in a buffer until all previous parts are written.
 */
 func runDownloadSinglePart(
 	s3Client *s3.S3,
@@ -151,24 +275,61 @@ func runDownloadSinglePart(
 	defer wgGetObject.Done()
 	defer func() { <-sem }()
 
+	partNumber := downloadSingle.downloadPart.partNumber
+
+	// Blocking while the part is too far ahead of the part written to pipe
+	if !downloadSingle.window.wait(partNumber) {
+		results <- DownloadPartResult{
+			partNumber: partNumber,
+			err:        errRestoreAborted,
+		}
+		return
+	}
+
 	global.Logger.Debug(
 		fmt.Sprintf("Downloading part number '%d' of '%d' for key '%s'.",
-			downloadSingle.downloadPart.partNumber,
+			partNumber,
 			downloadSingle.downloadPart.numParts,
 			downloadSingle.downloadPart.Key),
 	)
-	input := s3.GetObjectInput{
-		Bucket:     aws.String(config.BackintConfig.BucketName()),
-		Key:        aws.String(downloadSingle.downloadPart.Key),
-		PartNumber: aws.Int64(downloadSingle.downloadPart.partNumber),
-		Range:      aws.String(downloadSingle.downloadPart.byteRange),
+	// Parts ahead of the next one need memory for buffering,
+	// which is bounded for all restored objects.
+	// The memory is reserved before the part is in flight,
+	// parts waiting for memory must not block the next parts.
+	buffered, err := downloadSingle.window.reserveBuffer(partNumber)
+	if err != nil {
+		results <- DownloadPartResult{
+			partNumber: partNumber,
+			err:        err,
+		}
+		return
 	}
 
-	response, err := s3Client.GetObject(&input)
+	// Bounding the parts in flight of all pipes
+	Scheduler.acquire(downloadSingle.downloadPart.size)
+	defer Scheduler.release(downloadSingle.downloadPart.size)
+
+	input := getDownloadPartInput(downloadSingle.downloadPart)
+
+	var size int64
+	if !buffered {
+		size, err = streamPartData(s3Client, input, downloadSingle)
+		if err == nil {
+			err = downloadSingle.window.finish()
+		}
+	} else {
+		var buf *bytes.Buffer
+		buf, size, err = bufferPartData(s3Client, input, downloadSingle)
+		if err == nil {
+			err = downloadSingle.window.store(partNumber, buf)
+		} else {
+			Scheduler.releaseBuffer(downloadSingle.window.partSize)
+		}
+	}
 
 	global.Logger.Debug(
 		fmt.Sprintf("Finished downloading part number '%d' of '%d' for key '%s'.",
-			downloadSingle.downloadPart.partNumber,
+			partNumber,
 			downloadSingle.downloadPart.numParts,
 			downloadSingle.downloadPart.Key,
 		),
@@ -176,114 +337,199 @@ func runDownloadSinglePart(
 
 	if err != nil {
 		global.Logger.Error(
-			fmt.Sprintf("'%s': Error downloading part with number '%d'.",
+			fmt.Sprintf("'%s': Error downloading part with number '%d'. Error: %s",
 				downloadSingle.downloadPart.Key,
-				downloadSingle.downloadPart.partNumber,
+				partNumber,
+				err,
 			))
-		results <- DownloadPartResult{
-			partNumber: downloadSingle.downloadPart.partNumber,
-			err:        err,
+	}
+	results <- DownloadPartResult{
+		partNumber: partNumber,
+		err:        err,
+		size:       size,
+	}
+}
+
+/*
+Downloading one part into a buffer taken from the pool.
+The SDK retries the request itself, reading the body is retried here.
+*/
+func bufferPartData(
+	s3Client *s3.S3,
+	input s3.GetObjectInput,
+	downloadSingle DownloadSingePart,
+) (*bytes.Buffer, int64, error) {
+	retryer := newRetryer()
+	for attempt := 1; ; attempt++ {
+		buf := getPartBuffer(downloadSingle.downloadPart.size)
+		size, bodyErr, err := copyPartData(s3Client, input, downloadSingle, buf)
+		if err == nil {
+			return buf, size, nil
+		}
+		releasePartBuffer(buf)
+		if !retryPartData(retryer, downloadSingle, attempt, bodyErr, err) {
+			return nil, 0, err
 		}
-		return
 	}
+}
 
-	defer func() {
-		_ = response.Body.Close()
-	}()
+/*
+Downloading one part directly to pipe.
+If reading the body fails, the part is downloaded again
+and the data already written to pipe is skipped.
+*/
+func streamPartData(
+	s3Client *s3.S3,
+	input s3.GetObjectInput,
+	downloadSingle DownloadSingePart,
+) (int64, error) {
+	window := downloadSingle.window
+	partNumber := downloadSingle.downloadPart.partNumber
+	pipe := &partPipeWriter{window: window, partNumber: partNumber}
+
+	retryer := newRetryer()
+	for attempt := 1; ; attempt++ {
+		_, bodyErr, err := copyPartData(s3Client, input, downloadSingle, pipe)
+		if err == nil {
+			return pipe.written, nil
+		}
+		if errors.Is(err, errCouldNotWriteToPipe) ||
+			!retryPartData(retryer, downloadSingle, attempt, bodyErr, err) {
+			window.abort()
+			return pipe.written, err
+		}
+		pipe.skip = pipe.written
+	}
+}
 
-	buf := new(bytes.Buffer)
-	_, err = io.Copy(buf, response.Body)
+/*
+Getting the request for downloading one part.
+A request must not specify both a part number and a byte range,
+byte ranges are independent of the parts of the upload.
+*/
+func getDownloadPartInput(downloadPart DownloadPart) s3.GetObjectInput {
+	input := s3.GetObjectInput{
+		Bucket: aws.String(downloadPart.bucket),
+		Key:    aws.String(downloadPart.Key),
+	}
+	if downloadPart.ranged {
+		input.Range = aws.String(downloadPart.byteRange)
+	} else {
+		input.PartNumber = aws.Int64(downloadPart.partNumber)
+	}
+	if downloadPart.versionId != "" {
+		input.VersionId = aws.String(downloadPart.versionId)
+	}
+	return input
+}
 
-	if err != nil {
+/*
+Checking if downloading a part is retried and waiting for the next attempt.
+Only errors reading the body are retried, the SDK retries the request itself.
+*/
+func retryPartData(
+	retryer *backintRetryer,
+	downloadSingle DownloadSingePart,
+	attempt int,
+	bodyErr bool,
+	err error,
+) bool {
+	if !bodyErr || attempt >= retryer.maxAttempts || !retryer.shouldRetryError(err) {
+		return false
+	}
+	delay := retryer.delay(attempt - 1)
+	global.Logger.Info(fmt.Sprintf(
+		"'%s': Retrying part with number '%d' in %s (attempt %d of %d). Error: %s",
+		downloadSingle.downloadPart.Key,
+		downloadSingle.downloadPart.partNumber,
+		delay,
+		attempt+1,
+		retryer.maxAttempts,
+		err,
+	))
+	time.Sleep(delay)
+	return true
+}
+
+/*
+Downloading one part and copying its data to a writer.
+Falls back to the secondary storage if the part cannot be downloaded
+from the primary storage.
+Returns true as second value if the error occurred while reading the body.
+*/
+func copyPartData(
+	s3Client *s3.S3,
+	input s3.GetObjectInput,
+	downloadSingle DownloadSingePart,
+	w io.Writer,
+) (int64, bool, error) {
+	response, err := s3Client.GetObject(&input)
+
+	// Falling back to the secondary storage
+	if err != nil && SecondaryClient != nil && !downloadSingle.secondary {
 		global.Logger.Error(fmt.Sprintf(
-			"'%s': Could not copy data to buffer. Error: %s",
+			"'%s': Error downloading part with number '%d' from primary bucket,"+
+				" trying secondary bucket. Error: %s",
 			downloadSingle.downloadPart.Key,
+			downloadSingle.downloadPart.partNumber,
 			err,
 		))
-		results <- DownloadPartResult{
-			partNumber: downloadSingle.downloadPart.partNumber,
-			err:        err,
-		}
-		return
+		response, err = getObjectFromSecondary(input, downloadSingle.eTag)
 	}
-
-	if *(downloadSingle.nextIndex) > downloadSingle.downloadPart.numParts {
-		global.Logger.Debug(fmt.Sprintf(
-			"'%s': nextIndex higher than numParts.",
-			downloadSingle.downloadPart.Key,
-		))
-		results <- DownloadPartResult{
-			partNumber: downloadSingle.downloadPart.partNumber,
-			err:        err,
-			size:       *response.ContentLength,
-		}
-		return
+	if err != nil {
+		return 0, false, err
 	}
 
-	// Writing data to buffer or pipe
-	written := sendDataToHANA(
-		downloadSingle.fifo,
-		downloadSingle.nextIndex,
-		&(downloadSingle.partsNotYetWritten),
-		downloadSingle.downloadPart.partNumber,
-		downloadSingle.pipeBufferSize,
-		buf,
-	)
-
-	if !written {
-		var errCouldNotWriteToPipe = errors.New("could not write to pipe")
+	defer func() {
+		_ = response.Body.Close()
+	}()
 
-		results <- DownloadPartResult{
-			partNumber: downloadSingle.downloadPart.partNumber,
-			err:        errCouldNotWriteToPipe,
-			size:       *response.ContentLength,
-		}
-	} else {
-		results <- DownloadPartResult{
-			partNumber: downloadSingle.downloadPart.partNumber,
-			err:        nil,
-			size:       *response.ContentLength,
-		}
+	n, err := io.Copy(w, newThrottledReader(response.Body, downloadLimiter))
+	if err != nil {
+		global.Logger.Error(fmt.Sprintf(
+			"'%s': Could not copy data of part with number '%d'. Error: %s",
+			downloadSingle.downloadPart.Key,
+			downloadSingle.downloadPart.partNumber,
+			err,
+		))
+		return n, !errors.Is(err, errCouldNotWriteToPipe), err
 	}
+	return n, false, nil
 }
 
 /*
-Getting the numbers of parts uploaded of an object from IBM Cloud Object Storage
+Writer function passing the data of the next part directly to pipe.
+After a retry, the data already written is skipped.
 */
-func getPartsCount(s3Client *s3.S3, Key string) int64 {
-	global.Logger.Debug(fmt.Sprintf(
-		"Getting the PartsCount for key '%s'.", Key))
-	result := getHeadObject(s3Client, Key)
-
-	var partsCount int64 = 1
-	if result.PartsCount != nil {
-		partsCount = *result.PartsCount
+func (p *partPipeWriter) Write(data []byte) (int, error) {
+	n := len(data)
+	if p.skip > 0 {
+		skipped := min(p.skip, int64(len(data)))
+		p.skip -= skipped
+		data = data[skipped:]
 	}
-
-	global.Logger.Debug(fmt.Sprintf(
-		"PartsCount for key '%s' is '%d'.",
-		Key,
-		partsCount),
-	)
-	return partsCount
+	if len(data) == 0 {
+		return n, nil
+	}
+	if !writeDataToPipe(
+		p.window.target,
+		data,
+		&p.partNumber,
+		p.window.pipeBufferSize,
+	) {
+		return 0, errCouldNotWriteToPipe
+	}
+	p.written += int64(len(data))
+	return n, nil
 }
 
 /*
-Getting the size of an object from from IBM Cloud Object Storage
+Getting the numbers of parts uploaded of an object from its HeadObject
 */
-func getCosObjectSize(s3Client *s3.S3, Key string) int64 {
-	global.Logger.Debug(fmt.Sprintf(
-		"Getting the COS Object size for key '%s'.",
-		Key),
-	)
-	result := getHeadObject(s3Client, Key)
-	total_length := aws.Int64Value(result.ContentLength)
-	global.Logger.Debug(
-		fmt.Sprintf(
-			"COS Object size for key '%s' is '%d'.",
-			Key,
-			total_length,
-		),
-	)
-	return total_length
+func getPartsCount(head *s3.HeadObjectOutput) int64 {
+	var partsCount int64 = 1
+	if head.PartsCount != nil {
+		partsCount = *head.PartsCount
+	}
+	return partsCount
 }
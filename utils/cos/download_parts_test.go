@@ -0,0 +1,129 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"io"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/sirupsen/logrus"
+)
+
+/*
+Setting the download range size for one test
+*/
+func setupDownloadRangeSize(t *testing.T, rangeSize int64) {
+	t.Helper()
+	if global.Logger == nil {
+		global.Logger = logrus.New()
+		global.Logger.SetOutput(io.Discard)
+	}
+	previous := config.BackintConfig
+	config.BackintConfig = config.BackintConfigT{
+		"download_range_size": strconv.FormatInt(rangeSize, 10),
+	}
+	t.Cleanup(func() { config.BackintConfig = previous })
+}
+
+func TestGenerateDownloadParts(t *testing.T) {
+	tests := []struct {
+		name       string
+		rangeSize  int64
+		size       int64
+		partsCount int64
+		wantRanged bool
+		wantRanges []string
+		wantSizes  []int64
+	}{
+		{"ranges with a partial last range", 10, 25, 2, true,
+			[]string{"bytes=0-9", "bytes=10-19", "bytes=20-24"}, []int64{10, 10, 5}},
+		{"ranges of exactly the size", 10, 20, 1, true,
+			[]string{"bytes=0-9", "bytes=10-19"}, []int64{10, 10}},
+		{"range of one byte", 10, 1, 1, true,
+			[]string{"bytes=0-0"}, []int64{1}},
+		{"empty object with ranges", 10, 0, 1, false,
+			[]string{""}, []int64{0}},
+		{"parts with a partial last part", 0, 25, 3, false,
+			[]string{"bytes=0-8", "bytes=9-17", "bytes=18-24"}, []int64{9, 9, 7}},
+		{"object uploaded with a single PUT", 0, 25, 0, false,
+			[]string{"bytes=0-24"}, []int64{25}},
+		{"part of one byte", 0, 1, 1, false,
+			[]string{"bytes=0-0"}, []int64{1}},
+		{"empty object", 0, 0, 1, false,
+			[]string{""}, []int64{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupDownloadRangeSize(t, tt.rangeSize)
+			parts, numParts := generateDownloadParts("bucket", tt.size, "key", "v1", tt.partsCount)
+			if numParts != int64(len(tt.wantRanges)) || len(parts) != len(tt.wantRanges) {
+				t.Fatalf("generateDownloadParts() = %d parts, want %d", numParts, len(tt.wantRanges))
+			}
+
+			var ranges []string
+			var sizes []int64
+			var total int64
+			for i, part := range parts {
+				ranges = append(ranges, part.byteRange)
+				sizes = append(sizes, part.size)
+				total += part.size
+				if part.partNumber != int64(i+1) || part.numParts != numParts || part.ranged != tt.wantRanged {
+					t.Errorf("part %d = %+v, want number %d of %d, ranged %v", i, part, i+1, numParts, tt.wantRanged)
+				}
+			}
+			if !slices.Equal(ranges, tt.wantRanges) {
+				t.Errorf("ranges = %q, want %q", ranges, tt.wantRanges)
+			}
+			if !slices.Equal(sizes, tt.wantSizes) || total != tt.size {
+				t.Errorf("sizes = %v, want %v", sizes, tt.wantSizes)
+			}
+		})
+	}
+}
+
+func TestGetDownloadPartInput(t *testing.T) {
+	setupDownloadRangeSize(t, 0)
+	part := DownloadPart{Key: "key", bucket: "bucket", versionId: "v1", partNumber: 3, byteRange: "bytes=20-24", size: 5}
+
+	input := getDownloadPartInput(part)
+	if aws.Int64Value(input.PartNumber) != 3 || input.Range != nil {
+		t.Errorf("part: PartNumber %v, Range %v, want only part number 3",
+			aws.Int64Value(input.PartNumber), aws.StringValue(input.Range))
+	}
+	if aws.StringValue(input.Bucket) != "bucket" || aws.StringValue(input.Key) != "key" ||
+		aws.StringValue(input.VersionId) != "v1" {
+		t.Errorf("part: %s/%s version %s, want bucket/key version v1",
+			aws.StringValue(input.Bucket), aws.StringValue(input.Key), aws.StringValue(input.VersionId))
+	}
+
+	part.ranged = true
+	input = getDownloadPartInput(part)
+	if input.PartNumber != nil || aws.StringValue(input.Range) != "bytes=20-24" {
+		t.Errorf("range: PartNumber %v, Range %q, want only the range bytes=20-24",
+			aws.Int64Value(input.PartNumber), aws.StringValue(input.Range))
+	}
+
+	part.versionId = ""
+	if input = getDownloadPartInput(part); input.VersionId != nil {
+		t.Errorf("VersionId = %q without version, want nil", aws.StringValue(input.VersionId))
+	}
+}
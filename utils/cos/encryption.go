@@ -0,0 +1,341 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+)
+
+/*
+Setting up the client-side encryption of an upload stream.
+A new data key is generated for every object. The data key is wrapped
+with the configured master key and stored together with the key id
+in the object metadata, so that the object can be decrypted on restore.
+*/
+func newEncryptingReader(src io.Reader) (io.Reader, map[string]*string, error) {
+	dataKey := make([]byte, config.ENCRYPTION_KEY_LENGTH)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, err
+	}
+
+	noncePrefix := make([]byte, ENCRYPTION_NONCE_PREFIX_SIZE)
+	if _, err := rand.Read(noncePrefix); err != nil {
+		return nil, nil, err
+	}
+
+	keyId := config.BackintConfig.EncryptionKeyId()
+	wrappedKey, err := wrapDataKey(config.BackintConfig.EncryptionKey(), keyId, dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadata := map[string]*string{
+		METADATA_ENCRYPTION_ALGORITHM:   aws.String(ENCRYPTION_ALGORITHM),
+		METADATA_ENCRYPTION_KEY_ID:      aws.String(keyId),
+		METADATA_ENCRYPTION_WRAPPED_KEY: aws.String(wrappedKey),
+		METADATA_ENCRYPTION_NONCE:       aws.String(base64.StdEncoding.EncodeToString(noncePrefix)),
+	}
+
+	reader := &encryptingReader{
+		src:    src,
+		aead:   aead,
+		nonce:  streamNonce{prefix: noncePrefix},
+		plain:  make([]byte, ENCRYPTION_FRAME_SIZE+1),
+		sealed: make([]byte, 0, ENCRYPTION_FRAME_SIZE+aead.Overhead()),
+	}
+	return reader, metadata, nil
+}
+
+/*
+Setting up the decryption of a download stream
+based on the encryption information stored in the object metadata
+*/
+func newDecryptingReader(src io.Reader, metadata map[string]*string) (io.Reader, error) {
+	algorithm := getMetadataValue(metadata, METADATA_ENCRYPTION_ALGORITHM)
+	if algorithm != ENCRYPTION_ALGORITHM {
+		return nil, fmt.Errorf("unsupported encryption algorithm '%s'", algorithm)
+	}
+
+	keyId := getMetadataValue(metadata, METADATA_ENCRYPTION_KEY_ID)
+	if !config.BackintConfig.IsEncryptionEnabled() {
+		return nil, fmt.Errorf(
+			"object is encrypted with key '%s', but no encryption key is configured",
+			keyId,
+		)
+	}
+	if keyId != config.BackintConfig.EncryptionKeyId() {
+		return nil, fmt.Errorf(
+			"object is encrypted with key '%s', but the configured key is '%s'",
+			keyId,
+			config.BackintConfig.EncryptionKeyId(),
+		)
+	}
+
+	dataKey, err := unwrapDataKey(
+		config.BackintConfig.EncryptionKey(),
+		keyId,
+		getMetadataValue(metadata, METADATA_ENCRYPTION_WRAPPED_KEY),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	noncePrefix, err := base64.StdEncoding.DecodeString(
+		getMetadataValue(metadata, METADATA_ENCRYPTION_NONCE),
+	)
+	if err != nil || len(noncePrefix) != ENCRYPTION_NONCE_PREFIX_SIZE {
+		return nil, errors.New("invalid encryption nonce in object metadata")
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &decryptingReader{
+		src:    src,
+		aead:   aead,
+		nonce:  streamNonce{prefix: noncePrefix},
+		sealed: make([]byte, ENCRYPTION_FRAME_SIZE+aead.Overhead()+1),
+		plain:  make([]byte, 0, ENCRYPTION_FRAME_SIZE),
+	}
+	return reader, nil
+}
+
+/*
+Returns true if the object metadata describes a client-side encrypted object
+*/
+func isEncrypted(metadata map[string]*string) bool {
+	return getMetadataValue(metadata, METADATA_ENCRYPTION_ALGORITHM) != ""
+}
+
+/*
+Reader function for encrypting the upload stream frame by frame
+*/
+func (r *encryptingReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.sealNextFrame(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+/*
+Encrypting the next frame of the upload stream.
+One byte more than the frame size is read ahead to detect
+if the current frame is the last one of the stream.
+*/
+func (r *encryptingReader) sealNextFrame() error {
+	n, err := io.ReadFull(r.src, r.plain[r.carry:])
+	n += r.carry
+
+	final := false
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		final = true
+	default:
+		return err
+	}
+
+	frameLen := n
+	if !final {
+		frameLen = ENCRYPTION_FRAME_SIZE
+	}
+
+	nonce, err := r.nonce.next(final)
+	if err != nil {
+		return err
+	}
+	r.out = r.aead.Seal(r.sealed[:0], nonce, r.plain[:frameLen], nil)
+
+	if !final {
+		r.plain[0] = r.plain[ENCRYPTION_FRAME_SIZE]
+		r.carry = 1
+	}
+	r.done = final
+	return nil
+}
+
+/*
+Reader function for decrypting the download stream frame by frame
+*/
+func (r *decryptingReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.openNextFrame(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+/*
+Decrypting the next frame of the download stream
+*/
+func (r *decryptingReader) openNextFrame() error {
+	sealedFrameSize := ENCRYPTION_FRAME_SIZE + r.aead.Overhead()
+
+	n, err := io.ReadFull(r.src, r.sealed[r.carry:])
+	n += r.carry
+
+	final := false
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		final = true
+	default:
+		return err
+	}
+
+	frameLen := n
+	if !final {
+		frameLen = sealedFrameSize
+	}
+	if frameLen < r.aead.Overhead() {
+		return errors.New("encrypted data is truncated")
+	}
+
+	nonce, err := r.nonce.next(final)
+	if err != nil {
+		return err
+	}
+	r.out, err = r.aead.Open(r.plain[:0], nonce, r.sealed[:frameLen], nil)
+	if err != nil {
+		return errors.New("authentication of encrypted data failed")
+	}
+
+	if !final {
+		r.sealed[0] = r.sealed[sealedFrameSize]
+		r.carry = 1
+	}
+	r.done = final
+	return nil
+}
+
+/*
+Generating the nonce for the next frame:
+random prefix, frame counter and a flag marking the last frame
+*/
+func (s *streamNonce) next(final bool) ([]byte, error) {
+	if s.counter == ^uint32(0) {
+		return nil, errors.New("maximum number of encrypted frames exceeded")
+	}
+	nonce := make([]byte, 0, ENCRYPTION_NONCE_PREFIX_SIZE+5)
+	nonce = append(nonce, s.prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, s.counter)
+	if final {
+		nonce = append(nonce, 1)
+	} else {
+		nonce = append(nonce, 0)
+	}
+	s.counter++
+	return nonce, nil
+}
+
+/*
+Wrapping the data key with the master key
+*/
+func wrapDataKey(masterKey []byte, keyId string, dataKey []byte) (string, error) {
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	wrapped := aead.Seal(nonce, nonce, dataKey, []byte(keyId))
+	return base64.StdEncoding.EncodeToString(wrapped), nil
+}
+
+/*
+Unwrapping the data key with the master key
+*/
+func unwrapDataKey(masterKey []byte, keyId string, wrappedKey string) ([]byte, error) {
+	wrapped, err := base64.StdEncoding.DecodeString(wrappedKey)
+	if err != nil {
+		return nil, errors.New("invalid wrapped data key in object metadata")
+	}
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, errors.New("invalid wrapped data key in object metadata")
+	}
+	dataKey, err := aead.Open(
+		nil,
+		wrapped[:aead.NonceSize()],
+		wrapped[aead.NonceSize():],
+		[]byte(keyId),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not unwrap data key with key '%s'", keyId)
+	}
+	return dataKey, nil
+}
+
+/*
+Creating the AES-GCM cipher for a given key
+*/
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+/*
+Getting a value from the object metadata.
+The keys of the metadata returned by IBM Cloud Object Storage are
+canonicalized, therefore the keys are compared case insensitive.
+*/
+func getMetadataValue(metadata map[string]*string, key string) string {
+	for k, v := range metadata {
+		if strings.EqualFold(k, key) {
+			return aws.StringValue(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,224 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+)
+
+// Size of the authentication tag added to every encrypted frame
+const gcmTagSize = 16
+
+/*
+Switching on the encryption with a random master key for one test
+*/
+func setupEncryption(t *testing.T, keyId string) {
+	t.Helper()
+	masterKey := make([]byte, config.ENCRYPTION_KEY_LENGTH)
+	if _, err := rand.Read(masterKey); err != nil {
+		t.Fatal(err)
+	}
+	previous := config.BackintConfig
+	config.BackintConfig = config.BackintConfigT{
+		"encryption_algorithm": config.ENCRYPTION_AES256GCM,
+		"encryption_key":       base64.StdEncoding.EncodeToString(masterKey),
+		"encryption_key_id":    keyId,
+	}
+	t.Cleanup(func() { config.BackintConfig = previous })
+}
+
+/*
+Encrypting the given data, returns the ciphertext and the object metadata
+*/
+func encrypt(t *testing.T, data []byte) ([]byte, map[string]*string) {
+	t.Helper()
+	reader, metadata, err := newEncryptingReader(iotest.HalfReader(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sealed, metadata
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"empty stream", 0},
+		{"single byte", 1},
+		{"one byte less than a frame", ENCRYPTION_FRAME_SIZE - 1},
+		{"exactly one frame", ENCRYPTION_FRAME_SIZE},
+		{"one byte more than a frame", ENCRYPTION_FRAME_SIZE + 1},
+		{"several frames", 3*ENCRYPTION_FRAME_SIZE + 17},
+	}
+
+	setupEncryption(t, "key-1")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make([]byte, tt.size)
+			if _, err := rand.Read(data); err != nil {
+				t.Fatal(err)
+			}
+
+			sealed, metadata := encrypt(t, data)
+			if !isEncrypted(metadata) {
+				t.Fatal("isEncrypted() = false for the metadata of an encrypted stream")
+			}
+			// Every frame carries its own authentication tag,
+			// an empty stream is sealed into one empty frame
+			frames := max(1, (tt.size+ENCRYPTION_FRAME_SIZE-1)/ENCRYPTION_FRAME_SIZE)
+			if want := tt.size + frames*gcmTagSize; len(sealed) != want {
+				t.Errorf("encrypted size = %d, want %d", len(sealed), want)
+			}
+
+			reader, err := newDecryptingReader(iotest.HalfReader(bytes.NewReader(sealed)), metadata)
+			if err != nil {
+				t.Fatal(err)
+			}
+			plain, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(plain, data) {
+				t.Errorf("decrypted %d bytes differ from the %d original bytes", len(plain), len(data))
+			}
+		})
+	}
+}
+
+func TestDecryptionFailures(t *testing.T) {
+	setupEncryption(t, "key-1")
+	data := make([]byte, 2*ENCRYPTION_FRAME_SIZE+100)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	sealedFrameSize := ENCRYPTION_FRAME_SIZE + gcmTagSize
+
+	tests := []struct {
+		name     string
+		modify   func(sealed []byte) []byte
+		metadata func(metadata map[string]*string)
+	}{
+		{
+			name: "modified byte",
+			modify: func(sealed []byte) []byte {
+				sealed[10] ^= 0xff
+				return sealed
+			},
+		},
+		{
+			name: "missing last frame",
+			modify: func(sealed []byte) []byte {
+				return sealed[:2*sealedFrameSize]
+			},
+		},
+		{
+			name: "reordered frames",
+			modify: func(sealed []byte) []byte {
+				reordered := append([]byte{}, sealed[sealedFrameSize:2*sealedFrameSize]...)
+				reordered = append(reordered, sealed[:sealedFrameSize]...)
+				return append(reordered, sealed[2*sealedFrameSize:]...)
+			},
+		},
+		{
+			name: "truncated last frame",
+			modify: func(sealed []byte) []byte {
+				return sealed[:len(sealed)-1]
+			},
+		},
+		{
+			name: "wrong nonce",
+			metadata: func(metadata map[string]*string) {
+				nonce := make([]byte, ENCRYPTION_NONCE_PREFIX_SIZE)
+				encoded := base64.StdEncoding.EncodeToString(nonce)
+				metadata[METADATA_ENCRYPTION_NONCE] = &encoded
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, metadata := encrypt(t, data)
+			if tt.modify != nil {
+				sealed = tt.modify(sealed)
+			}
+			if tt.metadata != nil {
+				tt.metadata(metadata)
+			}
+
+			reader, err := newDecryptingReader(bytes.NewReader(sealed), metadata)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := io.ReadAll(reader); err == nil {
+				t.Error("decrypting the modified stream succeeded")
+			}
+		})
+	}
+}
+
+func TestDecryptionKeyMismatch(t *testing.T) {
+	setupEncryption(t, "key-1")
+	sealed, metadata := encrypt(t, []byte("backup data"))
+
+	tests := []struct {
+		name   string
+		config config.BackintConfigT
+	}{
+		{
+			name:   "encryption switched off",
+			config: config.BackintConfigT{"encryption_algorithm": config.ENCRYPTION_NONE},
+		},
+		{
+			name: "other key id",
+			config: config.BackintConfigT{
+				"encryption_algorithm": config.ENCRYPTION_AES256GCM,
+				"encryption_key":       config.BackintConfig.Get("encryption_key"),
+				"encryption_key_id":    "key-2",
+			},
+		},
+		{
+			name: "other master key with the same id",
+			config: config.BackintConfigT{
+				"encryption_algorithm": config.ENCRYPTION_AES256GCM,
+				"encryption_key":       base64.StdEncoding.EncodeToString(make([]byte, config.ENCRYPTION_KEY_LENGTH)),
+				"encryption_key_id":    "key-1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := config.BackintConfig
+			config.BackintConfig = tt.config
+			defer func() { config.BackintConfig = previous }()
+
+			if _, err := newDecryptingReader(bytes.NewReader(sealed), metadata); err == nil {
+				t.Error("setting up the decryption succeeded")
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+)
+
+// Stub of an HTTP proxy answering for the IAM token service and the storage
+type recordingProxy struct {
+	mu            sync.Mutex
+	hosts         []string
+	authorization string
+}
+
+func (p *recordingProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.hosts = append(p.hosts, r.URL.Host)
+	switch r.URL.Host {
+	case "iam.invalid":
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w,
+			`{"access_token": "proxied-token", "refresh_token": "", "token_type": "Bearer", "expires_in": 3600, "expiration": %d}`,
+			time.Now().Unix()+3600,
+		)
+	case "cos.invalid":
+		p.authorization = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusBadGateway)
+	}
+}
+
+/*
+Setting up the network configuration with a proxy, HTTP/1.1 and TLS 1.3
+*/
+func setupProxiedNetwork(t *testing.T, proxyUrl string) {
+	t.Helper()
+	if global.Logger == nil {
+		global.Logger = logrus.New()
+		global.Logger.SetOutput(io.Discard)
+	}
+	previous := config.BackintConfig
+	config.BackintConfig = config.BackintConfigT{
+		"proxy_url":          proxyUrl,
+		"force_http1":        "true",
+		"tls_min_version":    config.TLS_VERSION_13,
+		"retry_max_attempts": "1",
+	}
+	t.Cleanup(func() { config.BackintConfig = previous })
+}
+
+func TestNewHTTPClientWithSettings(t *testing.T) {
+	proxyUrl, _ := url.Parse("http://proxy.invalid:3128")
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS13}
+
+	tests := []struct {
+		name       string
+		forceHTTP1 bool
+		proxy      func(*http.Request) (*url.URL, error)
+		wantProxy  *url.URL
+		wantHTTP2  bool
+	}{
+		{
+			name:       "HTTP/1.1 forced with a proxy",
+			forceHTTP1: true,
+			proxy:      http.ProxyURL(proxyUrl),
+			wantProxy:  proxyUrl,
+		},
+		{
+			name:      "HTTP/2 negotiated with a proxy",
+			proxy:     http.ProxyURL(proxyUrl),
+			wantProxy: proxyUrl,
+			wantHTTP2: true,
+		},
+		{
+			name:       "proxy from the environment",
+			forceHTTP1: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, err := NewHTTPClientWithSettings(HTTPClientSettings{
+				TLSConfig:  tlsConfig,
+				Proxy:      tt.proxy,
+				ForceHTTP1: tt.forceHTTP1,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tr, ok := httpClient.Transport.(*http.Transport)
+			if !ok {
+				t.Fatalf("Transport = %T, want *http.Transport", httpClient.Transport)
+			}
+
+			if tr.TLSNextProto == nil {
+				t.Error("TLSNextProto = nil, HTTP/2 is not configured explicitly")
+			}
+			if _, http2 := tr.TLSNextProto["h2"]; http2 != tt.wantHTTP2 {
+				t.Errorf("TLSNextProto has h2 = %v, want %v", http2, tt.wantHTTP2)
+			}
+			if tr.TLSClientConfig == nil || tr.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+				t.Errorf("TLSClientConfig = %+v, want the given TLS configuration", tr.TLSClientConfig)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "https://s3.eu-de.cloud-object-storage.appdomain.cloud/backups", nil)
+			proxy, err := tr.Proxy(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantProxy := tt.wantProxy
+			if tt.proxy == nil {
+				wantProxy, _ = http.ProxyFromEnvironment(req)
+			}
+			if fmt.Sprint(proxy) != fmt.Sprint(wantProxy) {
+				t.Errorf("proxy = %v, want %v", proxy, wantProxy)
+			}
+		})
+	}
+}
+
+func TestCosConfigSharesHTTPClient(t *testing.T) {
+	proxy := &recordingProxy{}
+	server := httptest.NewServer(proxy)
+	t.Cleanup(server.Close)
+	setupProxiedNetwork(t, server.URL)
+
+	crTokenPath := filepath.Join(t.TempDir(), "cr-token")
+	if err := os.WriteFile(crTokenPath, []byte("compute-resource-token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := newCosConfig(
+		authCredentials{
+			method:           config.AUTH_TRUSTED_PROFILE,
+			authEndpoint:     "http://iam.invalid",
+			trustedProfileId: "Profile-0000",
+			crTokenPath:      crTokenPath,
+		},
+		"us-east-1",
+		"http://cos.invalid",
+	)
+
+	tr, ok := cfg.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Transport = %T, want *http.Transport", cfg.HTTPClient.Transport)
+	}
+	if tr.TLSNextProto == nil || len(tr.TLSNextProto) != 0 {
+		t.Errorf("TLSNextProto = %v, want an empty map forcing HTTP/1.1", tr.TLSNextProto)
+	}
+	if tr.TLSClientConfig == nil || tr.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Errorf("TLSClientConfig = %+v, want TLS 1.3", tr.TLSClientConfig)
+	}
+
+	// The token exchange and the storage request both pass the proxy
+	client := s3.New(newSession(cfg))
+	_, err := client.HeadBucket(&s3.HeadBucketInput{Bucket: aws.String("backups")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxy.mu.Lock()
+	defer proxy.mu.Unlock()
+	if len(proxy.hosts) != 2 || proxy.hosts[0] != "iam.invalid" || proxy.hosts[1] != "cos.invalid" {
+		t.Errorf("proxied hosts = %q, want %q", proxy.hosts, []string{"iam.invalid", "cos.invalid"})
+	}
+	if proxy.authorization != "Bearer proxied-token" {
+		t.Errorf("Authorization = %q, want %q", proxy.authorization, "Bearer proxied-token")
+	}
+}
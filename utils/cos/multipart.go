@@ -0,0 +1,357 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/awsutil"
+	"github.com/IBM/ibm-cos-sdk-go/aws/request"
+	"github.com/IBM/ibm-cos-sdk-go/aws/session"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+)
+
+/*
+Getting the size of a given part.
+Growing parts are doubled every PART_SIZE_GROWTH_INTERVAL parts
+up to the limit of the schedule or the maximum part size.
+*/
+func (s partSchedule) size(partNumber int64) int64 {
+	size := s.partSize
+	if !s.growing {
+		return size
+	}
+	maxPartSize := MAX_UPLOAD_PART_SIZE
+	if s.maxPartSize > 0 {
+		maxPartSize = min(max(s.maxPartSize, size), MAX_UPLOAD_PART_SIZE)
+	}
+	for range (partNumber - 1) / PART_SIZE_GROWTH_INTERVAL {
+		size = min(size*2, maxPartSize)
+	}
+	return size
+}
+
+/*
+Uploading a stream with growing part sizes.
+The s3manager uses the same size for all parts, therefore
+the parts are read and uploaded here with the same concurrency.
+A stream fitting into the first part is uploaded with a single PUT.
+*/
+func uploadGrowingParts(
+	s3Session *session.Session,
+	input *s3manager.UploadInput,
+	budget *uploadBudget,
+) (*s3manager.UploadOutput, error) {
+	client := s3.New(s3Session)
+	options := []request.Option{budget.requestOption()}
+
+	data, err := readUploadPart(input.Body, budget.schedule.size(1))
+	if err == io.EOF {
+		params := &s3.PutObjectInput{}
+		awsutil.Copy(params, input)
+		params.Body = bytes.NewReader(data)
+		output, err := client.PutObjectWithContext(aws.BackgroundContext(), params, options...)
+		if err != nil {
+			return nil, err
+		}
+		releaseUploadPartBuffer(data)
+		return &s3manager.UploadOutput{
+			ETag:      output.ETag,
+			VersionID: output.VersionId,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	createParams := &s3.CreateMultipartUploadInput{}
+	awsutil.Copy(createParams, input)
+	created, err := client.CreateMultipartUploadWithContext(aws.BackgroundContext(), createParams)
+	if err != nil {
+		return nil, err
+	}
+
+	completed, err := uploadParts(client, input, created.UploadId, data, budget.schedule, options)
+	if err != nil {
+		// Removing the parts already uploaded
+		_, abortErr := client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+			Bucket:   input.Bucket,
+			Key:      input.Key,
+			UploadId: created.UploadId,
+		})
+		if abortErr != nil {
+			err = fmt.Errorf("%w, aborting the upload failed: %s", err, abortErr)
+		}
+		return nil, err
+	}
+
+	output, err := client.CompleteMultipartUploadWithContext(
+		aws.BackgroundContext(),
+		&s3.CompleteMultipartUploadInput{
+			Bucket:          input.Bucket,
+			Key:             input.Key,
+			UploadId:        created.UploadId,
+			MultipartUpload: &s3.CompletedMultipartUpload{Parts: completed},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s3manager.UploadOutput{
+		ETag:      output.ETag,
+		VersionID: output.VersionId,
+		UploadID:  aws.StringValue(created.UploadId),
+	}, nil
+}
+
+/*
+Uploading the parts of a multipart upload concurrently,
+starting with the first part already read.
+Returns the completed parts in the order of their numbers.
+*/
+func uploadParts(
+	client *s3.S3,
+	input *s3manager.UploadInput,
+	uploadId *string,
+	first []byte,
+	schedule partSchedule,
+	options []request.Option,
+) ([]*s3.CompletedPart, error) {
+	ctx, cancel := context.WithCancel(aws.BackgroundContext())
+	defer cancel()
+
+	var mu sync.Mutex
+	var completed []*s3.CompletedPart
+	var uploadErr error
+	failed := func(err error) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil && uploadErr == nil {
+			uploadErr = err
+			// Stopping the parts in flight
+			cancel()
+		}
+		return uploadErr != nil
+	}
+
+	parts := make(chan uploadPart)
+	var wg sync.WaitGroup
+	for range Scheduler.StreamConcurrency(schedule.partSize) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for part := range parts {
+				if failed(nil) {
+					releaseUploadPartBuffer(part.data)
+					continue
+				}
+				output, err := client.UploadPartWithContext(ctx, &s3.UploadPartInput{
+					Bucket:     input.Bucket,
+					Key:        input.Key,
+					UploadId:   uploadId,
+					PartNumber: aws.Int64(part.number),
+					Body:       bytes.NewReader(part.data),
+				}, options...)
+				if failed(err) {
+					// The transport may still read the body of a failed request
+					continue
+				}
+				releaseUploadPartBuffer(part.data)
+				mu.Lock()
+				completed = append(completed, &s3.CompletedPart{
+					ETag:       output.ETag,
+					PartNumber: aws.Int64(part.number),
+				})
+				mu.Unlock()
+			}
+		}()
+	}
+
+	parts <- uploadPart{number: 1, data: first}
+	for partNumber := int64(2); !failed(nil); partNumber++ {
+		data, err := readUploadPart(input.Body, schedule.size(partNumber))
+		if len(data) > 0 {
+			if partNumber > s3manager.MaxUploadParts {
+				releaseUploadPartBuffer(data)
+				failed(fmt.Errorf(
+					"the stream exceeds the maximum of %d parts",
+					s3manager.MaxUploadParts,
+				))
+				break
+			}
+			parts <- uploadPart{number: partNumber, data: data}
+		} else {
+			releaseUploadPartBuffer(data)
+		}
+		if err == io.EOF {
+			break
+		}
+		if failed(err) {
+			break
+		}
+	}
+	close(parts)
+	wg.Wait()
+
+	if uploadErr != nil {
+		return nil, uploadErr
+	}
+	sort.Slice(completed, func(i, j int) bool {
+		return *completed[i].PartNumber < *completed[j].PartNumber
+	})
+	return completed, nil
+}
+
+/*
+Reading one part of the given size from the upload stream.
+Returns io.EOF with the remaining data at the end of the stream.
+The data is held in a buffer of the pool until it is released.
+*/
+func readUploadPart(body io.Reader, size int64) ([]byte, error) {
+	data := getUploadPartBuffer(size)
+	n, err := io.ReadFull(body, data)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return data[:n], err
+}
+
+/*
+Getting a buffer for one part of an upload.
+A buffer of the pool is reused if it can hold the part.
+*/
+func getUploadPartBuffer(size int64) []byte {
+	if buffer, ok := uploadPartBufferPool.Get().(*[]byte); ok {
+		if int64(cap(*buffer)) >= size {
+			return (*buffer)[:size]
+		}
+		// Dropping buffers of smaller parts, the part size only grows
+	}
+	return make([]byte, size)
+}
+
+/*
+Returning the buffer of a part to the pool once the part is sent
+*/
+func releaseUploadPartBuffer(data []byte) {
+	if cap(data) == 0 {
+		return
+	}
+	data = data[:0]
+	uploadPartBufferPool.Put(&data)
+}
+
+/*
+Getting the byte ranges of the parts of a multipart copy
+*/
+func getCopyPartRanges(size int64) []string {
+	partSize := max(COPY_PART_SIZE, size/s3manager.MaxUploadParts+1)
+	var ranges []string
+	for first := int64(0); first < size; first += partSize {
+		ranges = append(ranges, fmt.Sprintf("bytes=%d-%d", first, min(first+partSize, size)-1))
+	}
+	return ranges
+}
+
+/*
+Copying an object with a multipart copy.
+The parts are copied by the storage concurrently.
+*/
+func copyObjectParts(
+	client *s3.S3,
+	input *s3.CreateMultipartUploadInput,
+	copySource string,
+	size int64,
+) (uploadedObject, error) {
+	created, err := client.CreateMultipartUpload(input)
+	if err != nil {
+		return uploadedObject{}, err
+	}
+
+	ranges := getCopyPartRanges(size)
+	completed := make([]*s3.CompletedPart, len(ranges))
+	var mu sync.Mutex
+	var copyErr error
+	sem := make(chan struct{}, max(config.BackintConfig.MaxConcurrency(), 1))
+	var wg sync.WaitGroup
+	for i, byteRange := range ranges {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			partNumber := aws.Int64(int64(i + 1))
+			output, err := client.UploadPartCopy(&s3.UploadPartCopyInput{
+				Bucket:          input.Bucket,
+				Key:             input.Key,
+				UploadId:        created.UploadId,
+				PartNumber:      partNumber,
+				CopySource:      aws.String(copySource),
+				CopySourceRange: aws.String(byteRange),
+			})
+			if err == nil && output.CopyPartResult == nil {
+				err = fmt.Errorf("no result for the copy of part %d", i+1)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				copyErr = errors.Join(copyErr, err)
+				return
+			}
+			completed[i] = &s3.CompletedPart{
+				ETag:       output.CopyPartResult.ETag,
+				PartNumber: partNumber,
+			}
+		}()
+	}
+	wg.Wait()
+
+	if copyErr != nil {
+		// Removing the parts already copied
+		_, abortErr := client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+			Bucket:   input.Bucket,
+			Key:      input.Key,
+			UploadId: created.UploadId,
+		})
+		if abortErr != nil {
+			copyErr = fmt.Errorf("%w, aborting the copy failed: %s", copyErr, abortErr)
+		}
+		return uploadedObject{}, copyErr
+	}
+
+	output, err := client.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          input.Bucket,
+		Key:             input.Key,
+		UploadId:        created.UploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return uploadedObject{}, err
+	}
+	return uploadedObject{
+		ETag:      aws.StringValue(output.ETag),
+		VersionId: aws.StringValue(output.VersionId),
+	}, nil
+}
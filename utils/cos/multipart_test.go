@@ -0,0 +1,175 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/sirupsen/logrus"
+)
+
+const partTestMiB int64 = 1024 * 1024
+
+/*
+Setting the configuration of growing parts for one test
+*/
+func setupGrowingParts(t *testing.T, chunksize int64, concurrency int, memory int64) {
+	t.Helper()
+	if global.Logger == nil {
+		global.Logger = logrus.New()
+		global.Logger.SetOutput(io.Discard)
+	}
+	previous := config.BackintConfig
+	config.BackintConfig = config.BackintConfigT{
+		"multipart_chunksize": strconv.FormatInt(chunksize, 10),
+		"max_concurrency":     strconv.Itoa(concurrency),
+		"max_inflight_memory": strconv.FormatInt(memory, 10),
+	}
+	t.Cleanup(func() { config.BackintConfig = previous })
+}
+
+/*
+Getting the total size of all parts of a schedule
+*/
+func scheduleCapacity(schedule partSchedule) int64 {
+	var total int64
+	for partNumber := int64(1); partNumber <= s3manager.MaxUploadParts; partNumber++ {
+		total += schedule.size(partNumber)
+	}
+	return total
+}
+
+func TestGrowingPartSizeLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		chunksize   int64
+		concurrency int
+		memory      int64
+		want        int64
+	}{
+		{"default memory", 64 * partTestMiB, 8, 0, DEFAULT_GROWING_PART_MEMORY / 8},
+		{"memory divided by the concurrency", 64 * partTestMiB, 10, 5120 * partTestMiB, 512 * partTestMiB},
+		{"at least the chunksize", 64 * partTestMiB, 10, 128 * partTestMiB, 64 * partTestMiB},
+		{"at most the maximum part size", 64 * partTestMiB, 1, 4 * MAX_UPLOAD_PART_SIZE, MAX_UPLOAD_PART_SIZE},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupGrowingParts(t, tt.chunksize, tt.concurrency, tt.memory)
+			if got := getGrowingPartSizeLimit(); got != tt.want {
+				t.Errorf("getGrowingPartSizeLimit() = %d, want %d", got, tt.want)
+			}
+			schedule := getPipePartSchedule("/tmp/pipe", 0)
+			if !schedule.growing || schedule.maxPartSize != tt.want {
+				t.Errorf("schedule = %+v, want growing parts up to %d bytes", schedule, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrowingPartsWithinMemory(t *testing.T) {
+	setupGrowingParts(t, 64*partTestMiB, 10, 5120*partTestMiB)
+	schedule := getPipePartSchedule("/tmp/pipe", 0)
+
+	want := []struct {
+		partNumber int64
+		size       int64
+	}{
+		{1, 64 * partTestMiB},
+		{PART_SIZE_GROWTH_INTERVAL + 1, 128 * partTestMiB},
+		{2*PART_SIZE_GROWTH_INTERVAL + 1, 256 * partTestMiB},
+		{3*PART_SIZE_GROWTH_INTERVAL + 1, 512 * partTestMiB},
+		{4*PART_SIZE_GROWTH_INTERVAL + 1, 512 * partTestMiB},
+		{s3manager.MaxUploadParts, 512 * partTestMiB},
+	}
+	for _, w := range want {
+		if got := schedule.size(w.partNumber); got != w.size {
+			t.Errorf("size(%d) = %d, want %d", w.partNumber, got, w.size)
+		}
+	}
+
+	// The concurrent parts of the pipe fit into the memory
+	if parts := schedule.size(s3manager.MaxUploadParts) * 10; parts > 5120*partTestMiB {
+		t.Errorf("concurrent parts need %d bytes", parts)
+	}
+}
+
+func TestPipePartsFromPreviousSize(t *testing.T) {
+	setupGrowingParts(t, 64*partTestMiB, 10, 0)
+
+	// Size of the pipe in the manifest of the previous backup
+	const previousSize = 2 * 1024 * 1024 * partTestMiB
+	schedule := getPipePartSchedule("/tmp/pipe", previousSize)
+	if schedule.growing {
+		t.Fatalf("schedule = %+v, want fixed parts for a known size", schedule)
+	}
+
+	first := schedule.size(1)
+	if first <= 64*partTestMiB {
+		t.Errorf("part size %d for %d bytes, want more than the chunksize", first, int64(previousSize))
+	}
+	for _, partNumber := range []int64{2, PART_SIZE_GROWTH_INTERVAL + 1, s3manager.MaxUploadParts} {
+		if got := schedule.size(partNumber); got != first {
+			t.Errorf("size(%d) = %d, want %d for all parts", partNumber, got, first)
+		}
+	}
+
+	// The previous size plus the margin fits into the parts
+	margin := int64(previousSize) / 100 * EXPECTED_PIPE_SIZE_MARGIN
+	if capacity := scheduleCapacity(schedule); capacity < previousSize+margin {
+		t.Errorf("parts hold %d bytes, want at least %d", capacity, previousSize+margin)
+	}
+}
+
+func TestUploadPartBufferReuse(t *testing.T) {
+	data, err := readUploadPart(strings.NewReader(strings.Repeat("x", 64)), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	releaseUploadPartBuffer(data)
+
+	// A smaller part reuses the buffer, as long as the pool keeps it
+	reused := false
+	for range 10 {
+		data, err = readUploadPart(strings.NewReader("0123456789"), 16)
+		if err != io.EOF || string(data) != "0123456789" {
+			t.Fatalf("readUploadPart() = %q, %v, want the stream and io.EOF", data, err)
+		}
+		if cap(data) == 64 {
+			reused = true
+		}
+		releaseUploadPartBuffer(data)
+		if reused {
+			break
+		}
+	}
+	if !reused {
+		t.Log("buffer not reused, the pool was cleared")
+	}
+
+	// A larger part does not use the smaller buffer
+	data, err = readUploadPart(strings.NewReader(strings.Repeat("y", 128)), 128)
+	if err != nil || len(data) != 128 || cap(data) < 128 {
+		t.Errorf("readUploadPart() = %d bytes of capacity %d, %v, want 128 bytes", len(data), cap(data), err)
+	}
+	releaseUploadPartBuffer(data)
+}
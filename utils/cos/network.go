@@ -0,0 +1,108 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+)
+
+var errNoCertificates = errors.New("CA bundle does not contain any certificate")
+
+/*
+Setting up the TLS configuration from the network section.
+The CA bundle is added to the system certificates.
+Returns nil if the defaults of the HTTP transport are used.
+*/
+func newTLSConfig() (*tls.Config, error) {
+	if config.BackintConfig == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if config.BackintConfig.TLSMinVersion() == config.TLS_VERSION_13 {
+		tlsConfig.MinVersion = tls.VersionTLS13
+	}
+
+	if caBundle := config.BackintConfig.CaBundle(); caBundle != "" {
+		pem, err := os.ReadFile(caBundle)
+		if err != nil {
+			return nil, err
+		}
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(pem) {
+			return nil, errNoCertificates
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if clientCert := config.BackintConfig.ClientCert(); clientCert != "" {
+		cert, err := tls.LoadX509KeyPair(clientCert, config.BackintConfig.ClientKey())
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+/*
+Getting the proxy used for all requests.
+Without proxy_url the proxy is taken from the environment
+(HTTPS_PROXY, HTTP_PROXY and NO_PROXY).
+*/
+func newProxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	if config.BackintConfig == nil || config.BackintConfig.ProxyUrl() == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	proxy, err := url.Parse(config.BackintConfig.ProxyUrl())
+	if err != nil {
+		return nil, err
+	}
+	return http.ProxyURL(proxy), nil
+}
+
+/*
+Getting the root certificates configured for an HTTP client
+*/
+func getRootCAs(httpClient *http.Client) *x509.CertPool {
+	tr, ok := httpClient.Transport.(*http.Transport)
+	if !ok || tr.TLSClientConfig == nil {
+		return nil
+	}
+	return tr.TLSClientConfig.RootCAs
+}
+
+/*
+Setting the root certificates of an HTTP client
+*/
+func setRootCAs(httpClient *http.Client, rootCAs *x509.CertPool) {
+	if tr, ok := httpClient.Transport.(*http.Transport); ok && tr.TLSClientConfig != nil {
+		tr.TLSClientConfig.RootCAs = rootCAs
+	}
+}
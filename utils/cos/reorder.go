@@ -0,0 +1,232 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+)
+
+var errCouldNotWriteToPipe = errors.New("could not write to pipe")
+var errRestoreAborted = errors.New("restore of object aborted")
+
+/*
+Setting up the reorder window of one object.
+Parts are only downloaded if they are less than the window size
+ahead of the part written next to pipe.
+The window size is taken from restore_buffer_memory,
+if not set it is a multiple of the concurrent downloads.
+The memory of the buffered parts of all objects is bounded by the scheduler.
+*/
+func newReorderWindow(
+	target restoreTarget,
+	nextIndex *int64,
+	pipeBufferSize int,
+	partSize int64,
+	concurrency int,
+) *reorderWindow {
+	size := int64(concurrency * REORDER_WINDOW_FACTOR)
+	if memory := config.BackintConfig.RestoreBufferMemory(); memory > 0 && partSize > 0 {
+		size = max(memory/partSize, 1)
+	}
+
+	global.Logger.Debug(fmt.Sprintf(
+		"'%s': Reorder window of %d parts.",
+		target.Name(),
+		size,
+	))
+
+	window := &reorderWindow{
+		target:         target,
+		nextIndex:      nextIndex,
+		size:           size,
+		parts:          make(map[int64]*bytes.Buffer),
+		partSize:       partSize,
+		pipeBufferSize: pipeBufferSize,
+	}
+	window.cond = sync.NewCond(&window.mu)
+	return window
+}
+
+/*
+Waiting until a part is inside the window.
+Returns false if the restore of the object is aborted.
+*/
+func (w *reorderWindow) wait(partNumber int64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for !w.aborted && partNumber >= *w.nextIndex+w.size {
+		w.cond.Wait()
+	}
+	return !w.aborted
+}
+
+/*
+Reserving the memory for buffering a part which is not the next one.
+Waits until the memory of all restored objects allows another part.
+Returns false if no buffer is needed, because the part became
+the next one in the meantime, or the error if the restore is aborted.
+*/
+func (w *reorderWindow) reserveBuffer(partNumber int64) (bool, error) {
+	aborted := false
+	reserved := Scheduler.acquireBuffer(w.partSize, func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		aborted = w.aborted
+		return w.aborted || partNumber == *w.nextIndex
+	})
+	if aborted {
+		return false, errRestoreAborted
+	}
+	return reserved, nil
+}
+
+/*
+Releasing a buffered part and the memory reserved for it
+*/
+func (w *reorderWindow) releaseBuffer(buf *bytes.Buffer) {
+	releasePartBuffer(buf)
+	Scheduler.releaseBuffer(w.partSize)
+}
+
+/*
+Passing a downloaded part to the window.
+The part is written to pipe if it is the next one,
+otherwise it is kept until all previous parts are written.
+*/
+func (w *reorderWindow) store(partNumber int64, buf *bytes.Buffer) error {
+	w.mu.Lock()
+	if w.aborted {
+		w.mu.Unlock()
+		w.releaseBuffer(buf)
+		return errRestoreAborted
+	}
+	if partNumber != *w.nextIndex {
+		global.Logger.Debug(fmt.Sprintf(
+			"'%s': Keeping part #%d in buffer, nextIndex = %d.",
+			w.target.Name(),
+			partNumber,
+			*w.nextIndex,
+		))
+		w.parts[partNumber] = buf
+		w.mu.Unlock()
+		return nil
+	}
+	w.mu.Unlock()
+
+	written := w.write(partNumber, buf)
+	w.releaseBuffer(buf)
+	if !written {
+		return errCouldNotWriteToPipe
+	}
+	return w.finish()
+}
+
+/*
+Advancing the window after the next part is written to pipe
+and writing all parts following it which are already downloaded
+*/
+func (w *reorderWindow) finish() error {
+	for {
+		w.mu.Lock()
+		if w.aborted {
+			w.mu.Unlock()
+			return errRestoreAborted
+		}
+		*w.nextIndex++
+		w.cond.Broadcast()
+		index := *w.nextIndex
+		buf, available := w.parts[index]
+		delete(w.parts, index)
+		w.mu.Unlock()
+
+		// The new next part may wait for memory it does not need anymore
+		Scheduler.wake()
+		if !available {
+			return nil
+		}
+		written := w.write(index, buf)
+		w.releaseBuffer(buf)
+		if !written {
+			return errCouldNotWriteToPipe
+		}
+	}
+}
+
+/*
+Writing one buffered part to pipe
+The restore is aborted if writing fails.
+*/
+func (w *reorderWindow) write(partNumber int64, buf *bytes.Buffer) bool {
+	global.Logger.Debug(fmt.Sprintf(
+		"'%s': Writing part #%d to pipe",
+		w.target.Name(),
+		partNumber,
+	))
+	if !writeDataToPipe(w.target, buf.Bytes(), &partNumber, w.pipeBufferSize) {
+		w.abort()
+		return false
+	}
+	return true
+}
+
+/*
+Aborting the restore of the object.
+Waiting parts are woken up and all buffered parts are released.
+*/
+func (w *reorderWindow) abort() {
+	w.mu.Lock()
+	w.aborted = true
+	buffers := make([]*bytes.Buffer, 0, len(w.parts))
+	for index, buf := range w.parts {
+		buffers = append(buffers, buf)
+		delete(w.parts, index)
+	}
+	w.cond.Broadcast()
+	w.mu.Unlock()
+
+	// The scheduler is locked after the window,
+	// as it checks the windows of waiting parts
+	for _, buf := range buffers {
+		w.releaseBuffer(buf)
+	}
+	Scheduler.wake()
+}
+
+/*
+Getting an empty buffer for downloading one part
+*/
+func getPartBuffer(size int64) *bytes.Buffer {
+	buf := partBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	if size > 0 {
+		buf.Grow(int(size))
+	}
+	return buf
+}
+
+/*
+Returning a buffer to the pool after its part is written to pipe
+*/
+func releasePartBuffer(buf *bytes.Buffer) {
+	if buf != nil {
+		partBufferPool.Put(buf)
+	}
+}
@@ -0,0 +1,320 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Pipe recording the data written to it
+type recordingTarget struct {
+	mu   sync.Mutex
+	data bytes.Buffer
+	fail bool
+}
+
+func (r *recordingTarget) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fail {
+		return 0, errors.New("broken pipe")
+	}
+	return r.data.Write(p)
+}
+
+func (r *recordingTarget) Name() string {
+	return "test-pipe"
+}
+
+func (r *recordingTarget) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.data.String()
+}
+
+/*
+Setting the restore buffer memory and a scheduler bounding it for one test
+*/
+func setupReorder(t *testing.T, restoreBufferMemory int64) {
+	t.Helper()
+	if global.Logger == nil {
+		global.Logger = logrus.New()
+		global.Logger.SetOutput(io.Discard)
+	}
+	previousConfig := config.BackintConfig
+	previousScheduler := Scheduler
+	config.BackintConfig = config.BackintConfigT{
+		"restore_buffer_memory": strconv.FormatInt(restoreBufferMemory, 10),
+		"max_concurrency":       "4",
+	}
+	InitializeTransferScheduler(1)
+	t.Cleanup(func() {
+		config.BackintConfig = previousConfig
+		Scheduler = previousScheduler
+	})
+}
+
+/*
+Creating the buffer of a downloaded part
+*/
+func partData(partNumber int64) *bytes.Buffer {
+	buf := getPartBuffer(0)
+	fmt.Fprintf(buf, "<part %d>", partNumber)
+	return buf
+}
+
+/*
+Passing a downloaded part to the window like the download of a part.
+The next part is written to pipe directly, other parts are buffered
+with the memory reserved before.
+*/
+func deliver(window *reorderWindow, partNumber int64, buffered bool) error {
+	if buffered {
+		return window.store(partNumber, partData(partNumber))
+	}
+	data := partData(partNumber)
+	defer releasePartBuffer(data)
+	if !window.write(partNumber, data) {
+		return errCouldNotWriteToPipe
+	}
+	return window.finish()
+}
+
+/*
+Returns true if the function does not return within a short time
+*/
+func blocks(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return false
+	case <-time.After(50 * time.Millisecond):
+		return true
+	}
+}
+
+/*
+Getting the memory of all buffered parts
+*/
+func bufferedBytes() int64 {
+	Scheduler.mu.Lock()
+	defer Scheduler.mu.Unlock()
+	return Scheduler.bufferBytes
+}
+
+func TestReorderWindowSize(t *testing.T) {
+	tests := []struct {
+		name        string
+		memory      int64
+		partSize    int64
+		concurrency int
+		want        int64
+	}{
+		{"default multiple of concurrency", 0, 8 << 20, 4, 4 * REORDER_WINDOW_FACTOR},
+		{"memory for several parts", 20 << 20, 8 << 20, 4, 2},
+		{"memory smaller than one part", 1 << 20, 8 << 20, 4, 1},
+		{"memory larger than the default window", 1 << 30, 8 << 20, 4, 128},
+		{"unknown part size", 20 << 20, 0, 3, 3 * REORDER_WINDOW_FACTOR},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupReorder(t, tt.memory)
+			var nextIndex int64
+			window := newReorderWindow(&recordingTarget{}, &nextIndex, 1024, tt.partSize, tt.concurrency)
+			if window.size != tt.want {
+				t.Errorf("window size = %d, want %d", window.size, tt.want)
+			}
+		})
+	}
+}
+
+func TestReorderWindowOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		order []int64
+	}{
+		{"in order", []int64{0, 1, 2, 3, 4}},
+		{"reversed", []int64{4, 3, 2, 1, 0}},
+		{"mixed", []int64{2, 0, 3, 1, 4}},
+		{"next part last", []int64{1, 2, 3, 4, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupReorder(t, 0)
+			target := &recordingTarget{}
+			var nextIndex int64
+			window := newReorderWindow(target, &nextIndex, 4, 16, 4)
+
+			for _, partNumber := range tt.order {
+				if !window.wait(partNumber) {
+					t.Fatalf("wait(%d) reports an aborted restore", partNumber)
+				}
+				buffered, err := window.reserveBuffer(partNumber)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := deliver(window, partNumber, buffered); err != nil {
+					t.Fatalf("deliver(%d) = %v", partNumber, err)
+				}
+			}
+
+			want := "<part 0><part 1><part 2><part 3><part 4>"
+			if got := target.String(); got != want {
+				t.Errorf("written %q, want %q", got, want)
+			}
+			if nextIndex != 5 {
+				t.Errorf("nextIndex = %d, want 5", nextIndex)
+			}
+			if len(window.parts) != 0 {
+				t.Errorf("%d parts left in the window", len(window.parts))
+			}
+			if buffered := bufferedBytes(); buffered != 0 {
+				t.Errorf("%d bytes still reserved after all parts are written", buffered)
+			}
+		})
+	}
+}
+
+func TestReorderWindowBackpressure(t *testing.T) {
+	setupReorder(t, 0)
+	target := &recordingTarget{}
+	var nextIndex int64
+	window := newReorderWindow(target, &nextIndex, 1024, 16, 1)
+
+	// Parts 0 and 1 are inside the window of two parts, part 2 is not
+	done := make(chan struct{})
+	go func() {
+		window.wait(2)
+		close(done)
+	}()
+	if !window.wait(1) {
+		t.Fatal("wait(1) reports an aborted restore")
+	}
+	if !blocks(done) {
+		t.Fatal("part 2 is downloaded before part 0 is written")
+	}
+
+	if err := deliver(window, 0, false); err != nil {
+		t.Fatal(err)
+	}
+	if blocks(done) {
+		t.Fatal("part 2 still waits after part 0 is written")
+	}
+}
+
+func TestReorderWindowMemory(t *testing.T) {
+	const partSize = 16
+	setupReorder(t, partSize)
+	target := &recordingTarget{}
+	var nextIndex int64
+	window := newReorderWindow(target, &nextIndex, 1024, partSize, 4)
+
+	// The memory allows to buffer one part ahead of the next one
+	reserved, err := window.reserveBuffer(2)
+	if !reserved || err != nil {
+		t.Fatalf("reserveBuffer(2) = %v, %v, want the memory reserved", reserved, err)
+	}
+	if err := deliver(window, 2, true); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		reserved, err = window.reserveBuffer(1)
+		close(done)
+	}()
+	if !blocks(done) {
+		t.Fatal("part 1 is buffered beyond the restore buffer memory")
+	}
+
+	// Part 1 becomes the next part and is written without a buffer
+	if err := deliver(window, 0, false); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if reserved || err != nil {
+		t.Fatalf("reserveBuffer(1) = %v, %v, want no memory reserved", reserved, err)
+	}
+	if err := deliver(window, 1, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "<part 0><part 1><part 2>"; target.String() != want {
+		t.Errorf("written %q, want %q", target.String(), want)
+	}
+	if buffered := bufferedBytes(); buffered != 0 {
+		t.Errorf("%d bytes still reserved after all parts are written", buffered)
+	}
+}
+
+func TestReorderWindowAbort(t *testing.T) {
+	const partSize = 16
+	setupReorder(t, partSize)
+	target := &recordingTarget{fail: true}
+	var nextIndex int64
+	window := newReorderWindow(target, &nextIndex, 1024, partSize, 1)
+
+	if _, err := window.reserveBuffer(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := deliver(window, 1, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Part 2 waits for the window, part 3 for memory
+	waited := make(chan struct{})
+	go func() {
+		if window.wait(2) {
+			t.Error("wait(2) does not report the aborted restore")
+		}
+		close(waited)
+	}()
+	reserved := make(chan struct{})
+	go func() {
+		if _, err := window.reserveBuffer(3); !errors.Is(err, errRestoreAborted) {
+			t.Errorf("reserveBuffer(3) = %v, want %v", err, errRestoreAborted)
+		}
+		close(reserved)
+	}()
+	if !blocks(waited) || !blocks(reserved) {
+		t.Fatal("parts do not wait for the window and the memory")
+	}
+
+	if err := deliver(window, 0, false); !errors.Is(err, errCouldNotWriteToPipe) {
+		t.Fatalf("deliver(0) = %v, want %v", err, errCouldNotWriteToPipe)
+	}
+	<-waited
+	<-reserved
+
+	if window.wait(1) {
+		t.Error("wait(1) does not report the aborted restore")
+	}
+	if buffered := bufferedBytes(); buffered != 0 {
+		t.Errorf("%d bytes still reserved after the abort", buffered)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
+	"github.com/IBM/ibm-cos-sdk-go/aws/request"
+)
+
+/*
+Creating the retry policy from the retry section of the configuration
+*/
+func newRetryer() *backintRetryer {
+	return &backintRetryer{
+		maxAttempts: config.BackintConfig.RetryMaxAttempts(),
+		baseDelay:   config.BackintConfig.RetryBaseDelay(),
+		maxDelay:    config.BackintConfig.RetryMaxDelay(),
+		jitter:      config.BackintConfig.RetryJitter(),
+		classes:     config.BackintConfig.RetryErrorClasses(),
+	}
+}
+
+/*
+Returning the maximum number of retries of one request
+*/
+func (r *backintRetryer) MaxRetries() int {
+	return r.maxAttempts - 1
+}
+
+/*
+Returning true if a failed request is retried.
+Only the error classes enabled in retry_error_classes are retried.
+*/
+func (r *backintRetryer) ShouldRetry(req *request.Request) bool {
+	if req.Retryable != nil && !*req.Retryable {
+		return false
+	}
+	statusCode := 0
+	if req.HTTPResponse != nil {
+		statusCode = req.HTTPResponse.StatusCode
+	}
+	class := getRetryClass(
+		req.Error,
+		statusCode,
+		req.IsErrorThrottle(),
+		req.IsErrorRetryable(),
+	)
+	return r.isClassEnabled(class)
+}
+
+/*
+Returning the delay before the next attempt of a request.
+The SDK only calls this function if the request is retried.
+*/
+func (r *backintRetryer) RetryRules(req *request.Request) time.Duration {
+	delay := r.delay(req.RetryCount)
+	global.Logger.Info(fmt.Sprintf(
+		"Retrying '%s' of '%s' in %s (attempt %d of %d). Error: %s",
+		req.Operation.Name,
+		req.HTTPRequest.URL.Path,
+		delay,
+		req.RetryCount+2,
+		r.maxAttempts,
+		req.Error,
+	))
+	return delay
+}
+
+/*
+Returning true if an error returned outside of the SDK,
+e.g. while reading a response body, is retried
+*/
+func (r *backintRetryer) shouldRetryError(err error) bool {
+	retryable := request.IsErrorRetryable(err) || errors.Is(err, io.ErrUnexpectedEOF)
+	class := getRetryClass(err, 0, request.IsErrorThrottle(err), retryable)
+	return r.isClassEnabled(class)
+}
+
+/*
+Returning true if retries are enabled for the given error class
+*/
+func (r *backintRetryer) isClassEnabled(class string) bool {
+	return class != "" && slices.Contains(r.classes, class)
+}
+
+/*
+Calculating the exponential backoff for a given retry count.
+The delay is doubled for every retry up to retry_max_delay,
+the jitter spreads the retries of concurrent parts.
+*/
+func (r *backintRetryer) delay(retryCount int) time.Duration {
+	backoff := r.baseDelay
+	for i := 0; i < retryCount && backoff < r.maxDelay; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, r.maxDelay)
+	if backoff <= 0 {
+		return 0
+	}
+
+	switch r.jitter {
+	case config.JITTER_FULL:
+		return rand.N(backoff + 1)
+	case config.JITTER_EQUAL:
+		return backoff/2 + rand.N(backoff/2+1)
+	default:
+		return backoff
+	}
+}
+
+/*
+Classifying an error into one of the retryable error classes
+Returns an empty string if the error is not retryable
+*/
+func getRetryClass(err error, statusCode int, throttle bool, retryable bool) string {
+	switch {
+	case err == nil:
+		return ""
+	case throttle:
+		return config.RETRY_CLASS_THROTTLING
+	case isTimeoutError(err):
+		return config.RETRY_CLASS_TIMEOUT
+	case statusCode >= 500 && statusCode != 501:
+		return config.RETRY_CLASS_SERVER
+	case retryable:
+		return config.RETRY_CLASS_NETWORK
+	}
+	return ""
+}
+
+/*
+Returning true if the error or one of its causes is a timeout
+*/
+func isTimeoutError(err error) bool {
+	for err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case "RequestTimeout", "RequestTimeoutException", request.ErrCodeResponseTimeout:
+				return true
+			}
+			err = aerr.OrigErr()
+			continue
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout()
+	}
+	return false
+}
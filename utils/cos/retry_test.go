@@ -0,0 +1,233 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
+	"github.com/IBM/ibm-cos-sdk-go/aws/request"
+	"github.com/sirupsen/logrus"
+)
+
+// All error classes of retry_error_classes
+var allRetryClasses = []string{
+	config.RETRY_CLASS_THROTTLING,
+	config.RETRY_CLASS_TIMEOUT,
+	config.RETRY_CLASS_SERVER,
+	config.RETRY_CLASS_NETWORK,
+}
+
+// Network error reported as timeout
+type retryTimeoutError struct{}
+
+func (retryTimeoutError) Error() string   { return "i/o timeout" }
+func (retryTimeoutError) Timeout() bool   { return true }
+func (retryTimeoutError) Temporary() bool { return true }
+
+/*
+Creating a retry policy with all error classes for one test
+*/
+func newTestRetryer(t *testing.T, baseDelay time.Duration, maxDelay time.Duration, jitter string, classes ...string) *backintRetryer {
+	t.Helper()
+	if global.Logger == nil {
+		global.Logger = logrus.New()
+		global.Logger.SetOutput(io.Discard)
+	}
+	if classes == nil {
+		classes = allRetryClasses
+	}
+	return &backintRetryer{
+		maxAttempts: 5,
+		baseDelay:   baseDelay,
+		maxDelay:    maxDelay,
+		jitter:      jitter,
+		classes:     classes,
+	}
+}
+
+/*
+Creating a failed request with the given error and status code
+*/
+func newFailedRetryRequest(err error, statusCode int) *request.Request {
+	req := &request.Request{
+		Operation:   &request.Operation{Name: "PutObject"},
+		HTTPRequest: &http.Request{URL: &url.URL{Path: "/bucket/key"}},
+		Error:       err,
+	}
+	if statusCode != 0 {
+		req.HTTPResponse = &http.Response{StatusCode: statusCode}
+	}
+	return req
+}
+
+func TestShouldRetry(t *testing.T) {
+	brokenPipe := awserr.New(request.ErrCodeRequestError, "send request failed",
+		&url.Error{Op: "Put", URL: "https://s3/bucket/key", Err: &net.OpError{Op: "write", Err: syscall.EPIPE}})
+
+	tests := []struct {
+		name       string
+		err        error
+		statusCode int
+		retryable  *bool
+		wantClass  string
+	}{
+		{"throttling code", awserr.New("ThrottlingException", "slow down", nil), 400, nil, config.RETRY_CLASS_THROTTLING},
+		{"too many requests", awserr.New("TooManyRequests", "slow down", nil), 429, nil, config.RETRY_CLASS_THROTTLING},
+		{"service unavailable", awserr.New("SlowDown", "slow down", nil), 503, nil, config.RETRY_CLASS_THROTTLING},
+		{"internal error", awserr.New("InternalError", "internal error", nil), 500, nil, config.RETRY_CLASS_SERVER},
+		{"other server error", awserr.New("Unknown", "insufficient storage", nil), 507, nil, config.RETRY_CLASS_SERVER},
+		{"not implemented", awserr.New("NotImplemented", "not implemented", nil), 501, nil, ""},
+		{"request timeout code", awserr.New("RequestTimeout", "timeout", nil), 400, nil, config.RETRY_CLASS_TIMEOUT},
+		{"response timeout", awserr.New(request.ErrCodeResponseTimeout, "read timeout", nil), 0, nil, config.RETRY_CLASS_TIMEOUT},
+		{"network timeout", awserr.New(request.ErrCodeRequestError, "send request failed", retryTimeoutError{}), 0, nil, config.RETRY_CLASS_TIMEOUT},
+		{"broken pipe", brokenPipe, 0, nil, config.RETRY_CLASS_NETWORK},
+		{"canceled", awserr.New(request.CanceledErrorCode, "request canceled", nil), 0, nil, ""},
+		{"access denied", awserr.New("AccessDenied", "access denied", nil), 403, nil, ""},
+		{"no such key", awserr.New("NoSuchKey", "not found", nil), 404, nil, ""},
+		{"not retryable request", awserr.New("InternalError", "internal error", nil), 500, aws.Bool(false), ""},
+		{"no error", nil, 200, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newFailedRetryRequest(tt.err, tt.statusCode)
+			req.Retryable = tt.retryable
+
+			if tt.retryable == nil {
+				got := getRetryClass(req.Error, tt.statusCode, req.IsErrorThrottle(), req.IsErrorRetryable())
+				if got != tt.wantClass {
+					t.Errorf("getRetryClass() = %q, want %q", got, tt.wantClass)
+				}
+			}
+
+			// Retried only if the class is enabled
+			if got := newTestRetryer(t, 0, 0, config.JITTER_NONE).ShouldRetry(req); got != (tt.wantClass != "") {
+				t.Errorf("ShouldRetry() with all classes = %v, want %v", got, tt.wantClass != "")
+			}
+			others := []string{}
+			for _, class := range allRetryClasses {
+				if class != tt.wantClass {
+					others = append(others, class)
+				}
+			}
+			if newTestRetryer(t, 0, 0, config.JITTER_NONE, others...).ShouldRetry(req) {
+				t.Errorf("ShouldRetry() without class %q = true", tt.wantClass)
+			}
+		})
+	}
+}
+
+func TestShouldRetryError(t *testing.T) {
+	retryer := newTestRetryer(t, 0, 0, config.JITTER_NONE)
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"body cut off", fmt.Errorf("reading part 3: %w", io.ErrUnexpectedEOF), true},
+		{"read timeout", awserr.New(request.ErrCodeResponseTimeout, "read timeout", nil), true},
+		{"connection reset", awserr.New(request.ErrCodeRequestError, "read failed", syscall.ECONNRESET), true},
+		{"canceled", awserr.New(request.CanceledErrorCode, "request canceled", errors.New("context canceled")), false},
+		{"no error", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryer.shouldRetryError(tt.err); got != tt.want {
+				t.Errorf("shouldRetryError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	network := newTestRetryer(t, 0, 0, config.JITTER_NONE, config.RETRY_CLASS_THROTTLING)
+	if network.shouldRetryError(io.ErrUnexpectedEOF) {
+		t.Error("shouldRetryError() retries network errors without the network class")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	const (
+		baseDelay = 100 * time.Millisecond
+		maxDelay  = time.Second
+	)
+	backoffs := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i := range backoffs {
+		backoffs[i] *= time.Millisecond
+	}
+
+	t.Run("without jitter", func(t *testing.T) {
+		retryer := newTestRetryer(t, baseDelay, maxDelay, config.JITTER_NONE)
+		for retryCount, want := range backoffs {
+			if got := retryer.delay(retryCount); got != want {
+				t.Errorf("delay(%d) = %s, want %s", retryCount, got, want)
+			}
+		}
+		if got := retryer.delay(1000); got != maxDelay {
+			t.Errorf("delay(1000) = %s, want %s", got, maxDelay)
+		}
+	})
+
+	for _, tt := range []struct {
+		jitter string
+		lower  func(time.Duration) time.Duration
+	}{
+		{config.JITTER_FULL, func(time.Duration) time.Duration { return 0 }},
+		{config.JITTER_EQUAL, func(backoff time.Duration) time.Duration { return backoff / 2 }},
+	} {
+		t.Run(tt.jitter+" jitter", func(t *testing.T) {
+			retryer := newTestRetryer(t, baseDelay, maxDelay, tt.jitter)
+			for retryCount, backoff := range backoffs {
+				for range 100 {
+					got := retryer.delay(retryCount)
+					if got < tt.lower(backoff) || got > backoff {
+						t.Fatalf("delay(%d) = %s, want between %s and %s", retryCount, got, tt.lower(backoff), backoff)
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("without base delay", func(t *testing.T) {
+		retryer := newTestRetryer(t, 0, maxDelay, config.JITTER_FULL)
+		if got := retryer.delay(3); got != 0 {
+			t.Errorf("delay(3) = %s, want 0", got)
+		}
+	})
+
+	t.Run("retry rules", func(t *testing.T) {
+		retryer := newTestRetryer(t, baseDelay, maxDelay, config.JITTER_NONE)
+		req := newFailedRetryRequest(awserr.New("InternalError", "internal error", nil), 500)
+		req.RetryCount = 2
+		if got := retryer.RetryRules(req); got != 400*time.Millisecond {
+			t.Errorf("RetryRules() = %s, want 400ms", got)
+		}
+		if got := retryer.MaxRetries(); got != 4 {
+			t.Errorf("MaxRetries() = %d, want 4", got)
+		}
+	})
+}
@@ -0,0 +1,312 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/request"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+)
+
+/*
+Setting up the scheduler bounding the parts transferred concurrently
+by all pipes of one hdbbackint run.
+The limits are taken from max_inflight_parts and max_inflight_memory,
+a value of 0 means no limit.
+*/
+func InitializeTransferScheduler(streams int) {
+	scheduler := &TransferScheduler{
+		maxParts:       config.BackintConfig.MaxInflightParts(),
+		maxBytes:       config.BackintConfig.MaxInflightMemory(),
+		streams:        max(streams, 1),
+		maxBufferBytes: config.BackintConfig.RestoreBufferMemory(),
+	}
+	scheduler.cond = sync.NewCond(&scheduler.mu)
+
+	global.Logger.Info(fmt.Sprintf(
+		"Transfer scheduler: %d streams, max. %d parts and %d bytes in flight (0 = unlimited).",
+		scheduler.streams,
+		scheduler.maxParts,
+		scheduler.maxBytes,
+	))
+	Scheduler = scheduler
+}
+
+/*
+Getting the number of parts one stream may transfer concurrently.
+The process-wide budget is divided equally between all streams,
+but every stream gets at least one part.
+*/
+func (t *TransferScheduler) StreamConcurrency(partSize int64) int {
+	concurrency := config.BackintConfig.MaxConcurrency()
+	if t == nil {
+		return concurrency
+	}
+
+	limit := t.partLimit(partSize)
+	if limit == 0 {
+		return concurrency
+	}
+	return min(concurrency, max(limit/t.streams, 1))
+}
+
+/*
+Getting the maximum number of parts in flight for a given part size
+Returns 0 if no limit is set
+*/
+func (t *TransferScheduler) partLimit(partSize int64) int {
+	limit := t.maxParts
+	if t.maxBytes > 0 && partSize > 0 {
+		byteLimit := int(max(t.maxBytes/partSize, 1))
+		if limit == 0 || byteLimit < limit {
+			limit = byteLimit
+		}
+	}
+	return limit
+}
+
+/*
+Waiting until one more part of the given size may be transferred.
+A part larger than the memory limit is only transferred
+if no other part is in flight.
+*/
+func (t *TransferScheduler) acquire(size int64) {
+	t.acquireParts(1, size)
+}
+
+/*
+Waiting until the given number of parts may be transferred at once.
+The parts are acquired together, so that uploads of the same data
+to several storages do not wait for each other.
+*/
+func (t *TransferScheduler) acquireParts(count int, size int64) {
+	if t == nil || count == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for (t.maxParts > 0 && t.parts > 0 && t.parts+count > t.maxParts) ||
+		(t.maxBytes > 0 && t.bytes > 0 && t.bytes+int64(count)*size > t.maxBytes) {
+		t.cond.Wait()
+	}
+	t.parts += count
+	t.bytes += int64(count) * size
+}
+
+/*
+Releasing a part after its transfer is finished
+*/
+func (t *TransferScheduler) release(size int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.parts--
+	t.bytes -= size
+	t.mu.Unlock()
+	t.cond.Broadcast()
+}
+
+/*
+Waiting until a part of the given size may be buffered for reordering.
+The memory of all restored objects is bounded by restore_buffer_memory.
+Waiting stops without reserving memory if the part does not need
+a buffer anymore, because it is the next one written to pipe
+or the restore of the object is aborted.
+A part larger than the memory limit is only buffered
+if no other part is buffered.
+Returns true if the memory is reserved.
+*/
+func (t *TransferScheduler) acquireBuffer(size int64, needless func() bool) bool {
+	if t == nil {
+		return !needless()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for {
+		if needless() {
+			return false
+		}
+		if t.maxBufferBytes <= 0 || t.bufferBytes == 0 ||
+			t.bufferBytes+size <= t.maxBufferBytes {
+			t.bufferBytes += size
+			return true
+		}
+		t.cond.Wait()
+	}
+}
+
+/*
+Releasing the memory of a buffered part after it is written to pipe
+*/
+func (t *TransferScheduler) releaseBuffer(size int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.bufferBytes -= size
+	t.mu.Unlock()
+	t.cond.Broadcast()
+}
+
+/*
+Waking up all parts waiting for memory,
+e.g. after a part became the next one written to pipe
+*/
+func (t *TransferScheduler) wake() {
+	if t == nil {
+		return
+	}
+	// Locking makes sure that no waiting part misses the wake up
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cond.Broadcast()
+}
+
+/*
+Creating the budget of one upload.
+The parts are acquired by the budget reader before the uploader
+reads them and released when the request of the part is completed.
+*/
+func (t *TransferScheduler) newUploadBudget(schedule partSchedule) *uploadBudget {
+	return &uploadBudget{
+		scheduler: t,
+		schedule:  schedule,
+		held:      make(map[int64]bool),
+	}
+}
+
+/*
+Adding a part acquired for the upload.
+Returns false if the upload is already finished.
+*/
+func (b *uploadBudget) add(partNumber int64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return false
+	}
+	b.held[partNumber] = true
+	return true
+}
+
+/*
+Releasing one part of the upload after its request is completed
+*/
+func (b *uploadBudget) releasePart(partNumber int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.held[partNumber] {
+		delete(b.held, partNumber)
+		b.scheduler.release(b.schedule.size(partNumber))
+	}
+}
+
+/*
+Releasing all parts still held after the upload is finished,
+e.g. parts read but not sent because the upload failed
+*/
+func (b *uploadBudget) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for partNumber := range b.held {
+		delete(b.held, partNumber)
+		b.scheduler.release(b.schedule.size(partNumber))
+	}
+}
+
+/*
+Getting the request option releasing the parts of the upload
+when the request is completed, regardless of its success.
+*/
+func (b *uploadBudget) requestOption() request.Option {
+	return func(r *request.Request) {
+		var partNumber int64
+		switch params := r.Params.(type) {
+		case *s3.UploadPartInput:
+			partNumber = aws.Int64Value(params.PartNumber)
+		case *s3.PutObjectInput:
+			partNumber = 1
+		default:
+			return
+		}
+		r.Handlers.Complete.PushBack(func(r *request.Request) {
+			b.releasePart(partNumber)
+		})
+	}
+}
+
+/*
+Creating the reader acquiring the parts of the given uploads.
+All uploads read the same data, so a part is acquired
+for every upload not yet finished before its first byte is read.
+*/
+func newBudgetReader(src io.Reader, budgets []*uploadBudget, schedule partSchedule) *budgetReader {
+	return &budgetReader{
+		src:      src,
+		budgets:  budgets,
+		schedule: schedule,
+	}
+}
+
+/*
+Reader function acquiring the parts at the part boundaries
+*/
+func (r *budgetReader) Read(p []byte) (int, error) {
+	if r.left == 0 {
+		r.partNumber++
+		r.left = r.schedule.size(r.partNumber)
+		r.acquire()
+	}
+	if int64(len(p)) > r.left {
+		p = p[:r.left]
+	}
+	n, err := r.src.Read(p)
+	r.left -= int64(n)
+	return n, err
+}
+
+/*
+Acquiring the next part for all uploads still running
+*/
+func (r *budgetReader) acquire() {
+	active := make([]*uploadBudget, 0, len(r.budgets))
+	for _, budget := range r.budgets {
+		budget.mu.Lock()
+		if !budget.closed {
+			active = append(active, budget)
+		}
+		budget.mu.Unlock()
+	}
+	if len(active) == 0 {
+		return
+	}
+	scheduler := active[0].scheduler
+	scheduler.acquireParts(len(active), r.left)
+	for _, budget := range active {
+		// The upload finished while waiting for the scheduler
+		if !budget.add(r.partNumber) {
+			scheduler.release(r.left)
+		}
+	}
+}
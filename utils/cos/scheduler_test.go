@@ -0,0 +1,300 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/sirupsen/logrus"
+)
+
+/*
+Setting up a scheduler with the given limits for one test
+*/
+func setupTransferScheduler(t *testing.T, streams int, maxParts int, maxBytes int64) *TransferScheduler {
+	t.Helper()
+	if global.Logger == nil {
+		global.Logger = logrus.New()
+		global.Logger.SetOutput(io.Discard)
+	}
+	previousConfig := config.BackintConfig
+	previousScheduler := Scheduler
+	config.BackintConfig = config.BackintConfigT{
+		"max_concurrency":     "10",
+		"max_inflight_parts":  strconv.Itoa(maxParts),
+		"max_inflight_memory": strconv.FormatInt(maxBytes, 10),
+	}
+	InitializeTransferScheduler(streams)
+	t.Cleanup(func() {
+		config.BackintConfig = previousConfig
+		Scheduler = previousScheduler
+	})
+	return Scheduler
+}
+
+/*
+Getting the parts and the memory in flight
+*/
+func inflight(scheduler *TransferScheduler) (int, int64) {
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+	return scheduler.parts, scheduler.bytes
+}
+
+/*
+Returns true if the channel is not closed within a short time
+*/
+func waitsForScheduler(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return false
+	case <-time.After(50 * time.Millisecond):
+		return true
+	}
+}
+
+/*
+Uploading a stream like the uploader of every target.
+The request of a part completes after the next part is read,
+the remaining parts are released when the uploads are finished.
+*/
+func uploadThroughBudget(scheduler *TransferScheduler, data []byte, targets int, schedule partSchedule) error {
+	budgets := make([]*uploadBudget, targets)
+	for i := range budgets {
+		budgets[i] = scheduler.newUploadBudget(schedule)
+	}
+	reader := newBudgetReader(bytes.NewReader(data), budgets, schedule)
+
+	var requests sync.WaitGroup
+	var err error
+	for partNumber := int64(1); ; partNumber++ {
+		part := make([]byte, schedule.size(partNumber))
+		n, readErr := io.ReadFull(reader, part)
+		if n > 0 {
+			requests.Add(1)
+			go func() {
+				defer requests.Done()
+				time.Sleep(time.Millisecond)
+				for _, budget := range budgets {
+					budget.releasePart(partNumber)
+				}
+			}()
+		}
+		if readErr != nil {
+			if readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+				err = readErr
+			}
+			break
+		}
+	}
+	requests.Wait()
+	for _, budget := range budgets {
+		budget.close()
+	}
+	return err
+}
+
+func TestStreamConcurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		streams  int
+		maxParts int
+		maxBytes int64
+		partSize int64
+		want     int
+	}{
+		{"no limit", 4, 0, 0, 64 * MiB, 10},
+		{"parts divided between streams", 4, 8, 0, 64 * MiB, 2},
+		{"memory divided between streams", 2, 0, 512 * MiB, 64 * MiB, 4},
+		{"smaller limit of parts and memory", 1, 3, 512 * MiB, 64 * MiB, 3},
+		{"at least one part per stream", 8, 4, 0, 64 * MiB, 1},
+		{"part larger than the memory", 2, 0, 32 * MiB, 64 * MiB, 1},
+		{"limit above max_concurrency", 1, 100, 0, 64 * MiB, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduler := setupTransferScheduler(t, tt.streams, tt.maxParts, tt.maxBytes)
+			if got := scheduler.StreamConcurrency(tt.partSize); got != tt.want {
+				t.Errorf("StreamConcurrency(%d) = %d, want %d", tt.partSize, got, tt.want)
+			}
+		})
+	}
+
+	setupTransferScheduler(t, 1, 1, 0)
+	var scheduler *TransferScheduler
+	if got := scheduler.StreamConcurrency(64 * MiB); got != 10 {
+		t.Errorf("StreamConcurrency() without scheduler = %d, want 10", got)
+	}
+}
+
+func TestAcquirePartsLargerThanMemory(t *testing.T) {
+	const partSize = 16
+	scheduler := setupTransferScheduler(t, 2, 0, partSize)
+
+	// The parts of all targets exceed the memory, but nothing is in flight
+	first := make(chan struct{})
+	go func() {
+		scheduler.acquireParts(2, partSize)
+		close(first)
+	}()
+	if waitsForScheduler(first) {
+		t.Fatal("parts exceeding the memory wait although nothing is in flight")
+	}
+
+	second := make(chan struct{})
+	go func() {
+		scheduler.acquireParts(2, partSize)
+		close(second)
+	}()
+	if !waitsForScheduler(second) {
+		t.Fatal("parts are acquired beyond the memory")
+	}
+
+	// The parts wait until all parts in flight are released
+	scheduler.release(partSize)
+	if !waitsForScheduler(second) {
+		t.Fatal("parts are acquired before the memory is free")
+	}
+	scheduler.release(partSize)
+	<-second
+
+	if parts, bytes := inflight(scheduler); parts != 2 || bytes != 2*partSize {
+		t.Errorf("in flight %d parts, %d bytes, want 2 parts, %d bytes", parts, bytes, 2*partSize)
+	}
+}
+
+func TestBudgetReaderParallelStreams(t *testing.T) {
+	const (
+		streams  = 4
+		targets  = 2
+		partSize = 16
+	)
+	// The memory holds fewer parts than the streams and targets need
+	scheduler := setupTransferScheduler(t, streams, 0, partSize)
+	schedule := partSchedule{partSize: partSize}
+
+	errs := make(chan error, streams)
+	for range streams {
+		go func() {
+			errs <- uploadThroughBudget(scheduler, bytes.Repeat([]byte("x"), 10*partSize+3), targets, schedule)
+		}()
+	}
+
+	timeout := time.After(10 * time.Second)
+	for range streams {
+		select {
+		case err := <-errs:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-timeout:
+			t.Fatal("parallel streams do not finish")
+		}
+	}
+	if parts, bytes := inflight(scheduler); parts != 0 || bytes != 0 {
+		t.Errorf("in flight %d parts, %d bytes after all uploads, want none", parts, bytes)
+	}
+}
+
+func TestUploadBudgetRelease(t *testing.T) {
+	const partSize = 16
+	schedule := partSchedule{partSize: partSize}
+
+	t.Run("parts read but not sent", func(t *testing.T) {
+		scheduler := setupTransferScheduler(t, 1, 0, 0)
+		budgets := []*uploadBudget{scheduler.newUploadBudget(schedule), scheduler.newUploadBudget(schedule)}
+		reader := newBudgetReader(bytes.NewReader(make([]byte, 3*partSize)), budgets, schedule)
+		if _, err := io.ReadFull(reader, make([]byte, 2*partSize+1)); err != nil {
+			t.Fatal(err)
+		}
+		if parts, _ := inflight(scheduler); parts != 6 {
+			t.Fatalf("in flight %d parts, want 6", parts)
+		}
+
+		// The upload fails and releases all parts it still holds
+		for _, budget := range budgets {
+			budget.close()
+		}
+		if parts, bytes := inflight(scheduler); parts != 0 || bytes != 0 {
+			t.Errorf("in flight %d parts, %d bytes after the failed upload, want none", parts, bytes)
+		}
+	})
+
+	t.Run("part completed after the upload is finished", func(t *testing.T) {
+		scheduler := setupTransferScheduler(t, 1, 0, 0)
+		budget := scheduler.newUploadBudget(schedule)
+		reader := newBudgetReader(bytes.NewReader(make([]byte, partSize)), []*uploadBudget{budget}, schedule)
+		if _, err := io.ReadFull(reader, make([]byte, partSize)); err != nil {
+			t.Fatal(err)
+		}
+		budget.close()
+		// The request of the part completes after the budget released it
+		budget.releasePart(1)
+		if parts, bytes := inflight(scheduler); parts != 0 || bytes != 0 {
+			t.Errorf("in flight %d parts, %d bytes, want none", parts, bytes)
+		}
+	})
+
+	t.Run("upload finished while waiting for the scheduler", func(t *testing.T) {
+		scheduler := setupTransferScheduler(t, 1, 1, 0)
+		scheduler.acquire(partSize)
+
+		failed := scheduler.newUploadBudget(schedule)
+		running := scheduler.newUploadBudget(schedule)
+		reader := newBudgetReader(bytes.NewReader(make([]byte, partSize)), []*uploadBudget{failed, running}, schedule)
+		done := make(chan struct{})
+		go func() {
+			_, _ = reader.Read(make([]byte, partSize))
+			close(done)
+		}()
+		if !waitsForScheduler(done) {
+			t.Fatal("parts are acquired beyond max_inflight_parts")
+		}
+
+		// The cancelled upload gives back its part as soon as it is acquired
+		failed.close()
+		scheduler.release(partSize)
+		<-done
+		if parts, bytes := inflight(scheduler); parts != 1 || bytes != partSize {
+			t.Errorf("in flight %d parts, %d bytes, want only the part of the running upload", parts, bytes)
+		}
+		running.close()
+		if parts, bytes := inflight(scheduler); parts != 0 || bytes != 0 {
+			t.Errorf("in flight %d parts, %d bytes after all uploads, want none", parts, bytes)
+		}
+	})
+
+	t.Run("no uploads running", func(t *testing.T) {
+		scheduler := setupTransferScheduler(t, 1, 1, 0)
+		budget := scheduler.newUploadBudget(schedule)
+		budget.close()
+		reader := newBudgetReader(bytes.NewReader(make([]byte, partSize)), []*uploadBudget{budget}, schedule)
+		if _, err := io.ReadFull(reader, make([]byte, partSize)); err != nil {
+			t.Fatal(err)
+		}
+		if parts, _ := inflight(scheduler); parts != 0 {
+			t.Errorf("in flight %d parts for finished uploads, want none", parts)
+		}
+	})
+}
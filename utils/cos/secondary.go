@@ -0,0 +1,333 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/session"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+)
+
+// Error returned by the tee if none of the uploads accepts data anymore
+var errAllUploadsFailed = errors.New("all uploads failed")
+
+/*
+Setting up the connection to the secondary storage.
+Returns false if the bucket of the secondary storage
+does not exist or versioning is not enabled.
+*/
+func InitializeSecondaryStorage() bool {
+	bucket := config.BackintConfig.SecondaryBucketName()
+	s3Session, s3Client := GenerateSecondaryCOSSession()
+
+	exists, err := RunBucketExists(s3Client, bucket)
+	if !exists {
+		global.Logger.Error(fmt.Sprintf(
+			"Secondary bucket '%s' is not available. Error: %v",
+			bucket,
+			err,
+		))
+		return false
+	}
+
+	status, err := RunIsBucketVersioning(s3Client, bucket)
+	if err != nil || status != "Enabled" {
+		global.Logger.Error(fmt.Sprintf(
+			"Versioning must be enabled for secondary bucket '%s'. Error: %v",
+			bucket,
+			err,
+		))
+		return false
+	}
+
+	global.Logger.Info(fmt.Sprintf(
+		"Using secondary bucket '%s' in region '%s', write quorum is %d.",
+		bucket,
+		config.BackintConfig.SecondaryRegion(),
+		config.BackintConfig.WriteQuorum(),
+	))
+	SecondarySession = s3Session
+	SecondaryClient = s3Client
+	return true
+}
+
+/*
+Getting the storages a backup is written to
+*/
+func getUploadTargets(s3Session *session.Session, s3Client *s3.S3) []uploadTarget {
+	targets := []uploadTarget{{
+		session: s3Session,
+		client:  s3Client,
+		bucket:  config.BackintConfig.BucketName(),
+	}}
+	if SecondaryClient != nil {
+		targets = append(targets, uploadTarget{
+			session: SecondarySession,
+			client:  SecondaryClient,
+			bucket:  config.BackintConfig.SecondaryBucketName(),
+		})
+	}
+	return targets
+}
+
+/*
+Getting the number of storages a backup must be written to successfully
+*/
+func getWriteQuorum(numTargets int) int {
+	return min(config.BackintConfig.WriteQuorum(), numTargets)
+}
+
+/*
+Uploading the data to all storages concurrently.
+With more than one storage the upload stream is split by a tee,
+so that all storages get identical data and the same ETag.
+*/
+func uploadToTargets(
+	targets []uploadTarget,
+	input s3manager.UploadInput,
+	schedule partSchedule,
+) ([]*s3manager.UploadOutput, []error) {
+	outputs := make([]*s3manager.UploadOutput, len(targets))
+	errs := make([]error, len(targets))
+
+	// The parts are acquired before they are read by the uploaders
+	budgets := make([]*uploadBudget, len(targets))
+	for i := range targets {
+		budgets[i] = Scheduler.newUploadBudget(schedule)
+	}
+	body := newBudgetReader(input.Body, budgets, schedule)
+
+	if len(targets) == 1 {
+		input.Body = body
+		outputs[0], errs[0] = uploadToTarget(targets[0].session, &input, budgets[0])
+		budgets[0].close()
+		return outputs, errs
+	}
+
+	readers := newUploadTee(body, len(targets))
+
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		targetInput := input
+		targetInput.Bucket = aws.String(target.bucket)
+		targetInput.Body = readers[i]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outputs[i], errs[i] = uploadToTarget(target.session, &targetInput, budgets[i])
+			budgets[i].close()
+			// Releasing the tee in case the upload stopped reading
+			_ = readers[i].CloseWithError(errs[i])
+		}()
+	}
+	wg.Wait()
+	return outputs, errs
+}
+
+/*
+Removing an object uploaded to one storage which is not reported as saved,
+e.g. because its checksum could not be stored or the write quorum is not met
+*/
+func removeUploadedObject(target uploadTarget, Key string, versionId string) {
+	err := deleteObjectVersion(target.client, target.bucket, Key, versionId)
+	if err != nil {
+		global.Logger.Warn(fmt.Sprintf(
+			"'%s': Error removing version '%s' from bucket '%s'. Error: %s",
+			Key,
+			versionId,
+			target.bucket,
+			err,
+		))
+		return
+	}
+	global.Logger.Info(fmt.Sprintf(
+		"'%s': Version '%s' removed from bucket '%s'.",
+		Key,
+		versionId,
+		target.bucket,
+	))
+}
+
+/*
+Splitting one upload stream into a given number of streams.
+A stream which is not read anymore is dropped,
+the remaining streams continue.
+*/
+func newUploadTee(src io.Reader, n int) []*io.PipeReader {
+	readers := make([]*io.PipeReader, n)
+	tee := &uploadTee{
+		writers: make([]*io.PipeWriter, n),
+		failed:  make([]bool, n),
+	}
+	for i := range n {
+		readers[i], tee.writers[i] = io.Pipe()
+	}
+
+	go func() {
+		_, err := io.Copy(tee, src)
+		for _, w := range tee.writers {
+			_ = w.CloseWithError(err)
+		}
+	}()
+	return readers
+}
+
+/*
+Writer function passing the data to all streams still being read
+*/
+func (t *uploadTee) Write(p []byte) (int, error) {
+	active := 0
+	for i, w := range t.writers {
+		if t.failed[i] {
+			continue
+		}
+		if _, err := w.Write(p); err != nil {
+			t.failed[i] = true
+			continue
+		}
+		active++
+	}
+	if active == 0 {
+		return 0, errAllUploadsFailed
+	}
+	return len(p), nil
+}
+
+/*
+Downloading a part from the secondary storage.
+The ETag makes sure that the same backup is restored
+as requested from the primary storage.
+*/
+func getObjectFromSecondary(input s3.GetObjectInput, ETag string) (*s3.GetObjectOutput, error) {
+	input.Bucket = aws.String(config.BackintConfig.SecondaryBucketName())
+	// The version ids differ between the buckets, the ETag identifies the data
+	input.VersionId = nil
+	if ETag != "" {
+		input.IfMatch = aws.String("\"" + strings.Trim(ETag, "\"") + "\"")
+	}
+	return SecondaryClient.GetObject(&input)
+}
+
+/*
+Resolving the version of an object to be restored.
+If the version cannot be resolved in the primary storage,
+because the storage is not available or the object does not exist,
+it is resolved in the secondary storage.
+The VersionId stays empty if the object is not found in any storage.
+*/
+func ResolveRestoreVersion(s3Client *s3.S3, element *CosObject) error {
+	ETag, versionId, err := getObjectVersionInBucket(
+		s3Client,
+		config.BackintConfig.BucketName(),
+		element.Key,
+		element.ETag,
+	)
+	if versionId == "" && SecondaryClient != nil {
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf(
+				"'%s': Error getting the version from primary bucket,"+
+					" trying secondary bucket. Error: %s",
+				element.Key,
+				err,
+			))
+		} else {
+			global.Logger.Info(fmt.Sprintf(
+				"'%s': Version not found in primary bucket, trying secondary bucket.",
+				element.Key,
+			))
+		}
+		secondaryETag, secondaryVersionId, secondaryErr := getObjectVersionInBucket(
+			SecondaryClient,
+			config.BackintConfig.SecondaryBucketName(),
+			element.Key,
+			element.ETag,
+		)
+		if secondaryVersionId != "" {
+			ETag, versionId, err = secondaryETag, secondaryVersionId, nil
+			element.Secondary = true
+		} else if err == nil {
+			err = secondaryErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if element.ETag == "" {
+		element.ETag = ETag
+	}
+	element.VersionId = versionId
+	return nil
+}
+
+/*
+Getting the HeadObject of an object to be restored.
+If the primary storage fails, the version with the same ETag
+is resolved in the secondary storage and restored from there.
+*/
+func getRestoreHeadObject(s3Client *s3.S3, element *CosObject) (*s3.HeadObjectOutput, error) {
+	client, bucket := getRestoreSource(s3Client, *element)
+	head, err := getHeadObjectInBucket(client, bucket, element.Key, element.VersionId)
+	if err == nil || element.Secondary || SecondaryClient == nil {
+		return head, err
+	}
+
+	global.Logger.Error(fmt.Sprintf(
+		"'%s': Error getting the object from primary bucket, trying secondary bucket. Error: %s",
+		element.Key,
+		err,
+	))
+	_, versionId, secondaryErr := getObjectVersionInBucket(
+		SecondaryClient,
+		config.BackintConfig.SecondaryBucketName(),
+		element.Key,
+		element.ETag,
+	)
+	if secondaryErr != nil || versionId == "" {
+		return nil, err
+	}
+	head, secondaryErr = getHeadObjectInBucket(
+		SecondaryClient,
+		config.BackintConfig.SecondaryBucketName(),
+		element.Key,
+		versionId,
+	)
+	if secondaryErr != nil {
+		return nil, err
+	}
+	element.VersionId = versionId
+	element.Secondary = true
+	return head, nil
+}
+
+/*
+Getting the client and the bucket an object is restored from
+*/
+func getRestoreSource(s3Client *s3.S3, element CosObject) (*s3.S3, string) {
+	if element.Secondary {
+		return SecondaryClient, config.BackintConfig.SecondaryBucketName()
+	}
+	return s3Client, config.BackintConfig.BucketName()
+}
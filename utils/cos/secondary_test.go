@@ -0,0 +1,323 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/credentials"
+	"github.com/IBM/ibm-cos-sdk-go/aws/session"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/sirupsen/logrus"
+)
+
+// One version stored in a stub bucket
+type quorumVersion struct {
+	data     []byte
+	metadata http.Header
+}
+
+// Stub of a versioned bucket of the primary or secondary storage
+type quorumBucket struct {
+	mu       sync.Mutex
+	name     string
+	versions map[string]quorumVersion
+	deleted  []string
+	failPut  bool
+	failHead bool
+}
+
+func (b *quorumBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	versionId := r.URL.Query().Get("versionId")
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil || b.failPut {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		metadata := make(http.Header)
+		for name, values := range r.Header {
+			if strings.HasPrefix(strings.ToLower(name), "x-amz-meta-") {
+				metadata[name] = values
+			}
+		}
+		versionId = fmt.Sprintf("%s-v%d", b.name, len(b.versions)+1)
+		b.versions[versionId] = quorumVersion{data: data, metadata: metadata}
+		w.Header().Set("ETag", quorumETag(data))
+		w.Header().Set("X-Amz-Version-Id", versionId)
+	case http.MethodHead:
+		version, found := b.versions[versionId]
+		if b.failHead || !found {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		for name, values := range version.metadata {
+			w.Header()[name] = values
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(version.data)))
+		w.Header().Set("ETag", quorumETag(version.data))
+		w.Header().Set("X-Amz-Version-Id", versionId)
+	case http.MethodDelete:
+		b.deleted = append(b.deleted, versionId)
+		delete(b.versions, versionId)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+/*
+Getting the ETag of data uploaded with one PUT
+*/
+func quorumETag(data []byte) string {
+	sum := md5.Sum(data)
+	return "\"" + hex.EncodeToString(sum[:]) + "\""
+}
+
+/*
+Getting the versions of the stub bucket and the versions deleted
+*/
+func (b *quorumBucket) state() (map[string]quorumVersion, []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	versions := make(map[string]quorumVersion, len(b.versions))
+	for versionId, version := range b.versions {
+		versions[versionId] = version
+	}
+	return versions, slices.Clone(b.deleted)
+}
+
+/*
+Creating a session of a stub bucket
+*/
+func newQuorumSession(t *testing.T, bucket *quorumBucket) *session.Session {
+	t.Helper()
+	server := httptest.NewServer(bucket)
+	t.Cleanup(server.Close)
+	return session.Must(session.NewSession(aws.NewConfig().
+		WithEndpoint(server.URL).
+		WithRegion("us-east-1").
+		WithCredentials(credentials.NewStaticCredentials("key-id", "secret", "")).
+		WithS3ForcePathStyle(true).
+		WithMaxRetries(0),
+	))
+}
+
+/*
+Setting up a primary and a secondary stub storage with a write quorum
+*/
+func setupQuorum(t *testing.T, writeQuorum int) (*quorumBucket, *quorumBucket, *session.Session) {
+	t.Helper()
+	if global.Logger == nil {
+		global.Logger = logrus.New()
+		global.Logger.SetOutput(io.Discard)
+	}
+	previousConfig := config.BackintConfig
+	previousSession, previousClient := SecondarySession, SecondaryClient
+	config.BackintConfig = config.BackintConfigT{
+		"bucket":           "primary",
+		"secondary_bucket": "secondary",
+		"write_quorum":     strconv.Itoa(writeQuorum),
+		"max_concurrency":  "2",
+	}
+	t.Cleanup(func() {
+		config.BackintConfig = previousConfig
+		SecondarySession, SecondaryClient = previousSession, previousClient
+	})
+
+	primary := &quorumBucket{name: "primary", versions: make(map[string]quorumVersion)}
+	secondary := &quorumBucket{name: "secondary", versions: make(map[string]quorumVersion)}
+	SecondarySession = newQuorumSession(t, secondary)
+	SecondaryClient = s3.New(SecondarySession)
+	return primary, secondary, newQuorumSession(t, primary)
+}
+
+func TestUploadWriteQuorum(t *testing.T) {
+	data := bytes.Repeat([]byte("backup data "), 1000)
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name                 string
+		writeQuorum          int
+		failPrimaryPut       bool
+		failSecondaryPut     bool
+		failSecondaryHead    bool
+		wantErr              bool
+		wantVersion          string
+		wantPrimaryVersions  []string
+		wantPrimaryDeleted   []string
+		wantSecondaryVersion []string
+		wantSecondaryDeleted []string
+	}{
+		{
+			name:                 "both storages",
+			writeQuorum:          2,
+			wantVersion:          "primary-v1",
+			wantPrimaryVersions:  []string{"primary-v1"},
+			wantSecondaryVersion: []string{"secondary-v1"},
+		},
+		{
+			name:                "secondary failing, quorum met",
+			writeQuorum:         1,
+			failSecondaryPut:    true,
+			wantVersion:         "primary-v1",
+			wantPrimaryVersions: []string{"primary-v1"},
+		},
+		{
+			name:               "secondary failing, quorum not met",
+			writeQuorum:        2,
+			failSecondaryPut:   true,
+			wantErr:            true,
+			wantPrimaryDeleted: []string{"primary-v1"},
+		},
+		{
+			name:                 "primary failing, quorum met",
+			writeQuorum:          1,
+			failPrimaryPut:       true,
+			wantVersion:          "secondary-v1",
+			wantSecondaryVersion: []string{"secondary-v1"},
+		},
+		{
+			name:                 "checksum of the secondary failing, quorum met",
+			writeQuorum:          1,
+			failSecondaryHead:    true,
+			wantVersion:          "primary-v1",
+			wantPrimaryVersions:  []string{"primary-v1"},
+			wantSecondaryDeleted: []string{"secondary-v1"},
+		},
+		{
+			name:                 "checksum of the secondary failing, quorum not met",
+			writeQuorum:          2,
+			failSecondaryHead:    true,
+			wantErr:              true,
+			wantPrimaryDeleted:   []string{"primary-v1"},
+			wantSecondaryDeleted: []string{"secondary-v1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary, secondary, primarySession := setupQuorum(t, tt.writeQuorum)
+			primary.failPut = tt.failPrimaryPut
+			secondary.failPut = tt.failSecondaryPut
+			secondary.failHead = tt.failSecondaryHead
+
+			result := upload(
+				primarySession,
+				s3.New(primarySession),
+				"/tmp/backup/file",
+				"backup/file",
+				bytes.NewReader(data),
+				partSchedule{partSize: s3manager.MinUploadPartSize},
+				int64(len(data)),
+				checksum,
+			)
+			if (result.Err != nil) != tt.wantErr {
+				t.Fatalf("upload() error = %v, wantErr %v", result.Err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				if result.VersionId != tt.wantVersion || result.ETag != quorumETag(data) ||
+					result.Checksum != checksum || result.SourceSize != int64(len(data)) {
+					t.Errorf("upload() = %+v, want version %q with ETag %s and checksum %s",
+						result, tt.wantVersion, quorumETag(data), checksum)
+				}
+			}
+
+			for _, bucket := range []struct {
+				stub         *quorumBucket
+				wantVersions []string
+				wantDeleted  []string
+			}{
+				{primary, tt.wantPrimaryVersions, tt.wantPrimaryDeleted},
+				{secondary, tt.wantSecondaryVersion, tt.wantSecondaryDeleted},
+			} {
+				versions, deleted := bucket.stub.state()
+				var versionIds []string
+				for versionId, version := range versions {
+					versionIds = append(versionIds, versionId)
+					// Every version kept holds the data and its checksum
+					if !bytes.Equal(version.data, data) {
+						t.Errorf("%s: version %s holds %d bytes, want %d", bucket.stub.name, versionId, len(version.data), len(data))
+					}
+					if got := version.metadata.Get("X-Amz-Meta-" + METADATA_CHECKSUM_SHA256); got != checksum {
+						t.Errorf("%s: checksum of version %s = %q, want %q", bucket.stub.name, versionId, got, checksum)
+					}
+				}
+				if !slices.Equal(versionIds, bucket.wantVersions) {
+					t.Errorf("%s: versions %v, want %v", bucket.stub.name, versionIds, bucket.wantVersions)
+				}
+				if !slices.Equal(deleted, bucket.wantDeleted) {
+					t.Errorf("%s: deleted versions %v, want %v", bucket.stub.name, deleted, bucket.wantDeleted)
+				}
+			}
+		})
+	}
+}
+
+func TestUploadDataToAllTargets(t *testing.T) {
+	manifest := []byte(`{"backupId": 1}`)
+
+	t.Run("both storages", func(t *testing.T) {
+		primary, secondary, primarySession := setupQuorum(t, 1)
+		if err := UploadData(s3.New(primarySession), "manifests/1.json", manifest); err != nil {
+			t.Fatal(err)
+		}
+		for _, bucket := range []*quorumBucket{primary, secondary} {
+			versions, _ := bucket.state()
+			if len(versions) != 1 {
+				t.Fatalf("%s: %d versions of the manifest, want 1", bucket.name, len(versions))
+			}
+			for _, version := range versions {
+				if !bytes.Equal(version.data, manifest) {
+					t.Errorf("%s: manifest %q, want %q", bucket.name, version.data, manifest)
+				}
+			}
+		}
+	})
+
+	t.Run("secondary failing", func(t *testing.T) {
+		primary, secondary, primarySession := setupQuorum(t, 1)
+		secondary.failPut = true
+		err := UploadData(s3.New(primarySession), "manifests/1.json", manifest)
+		if err == nil || !strings.Contains(err.Error(), "bucket 'secondary'") {
+			t.Errorf("UploadData() error = %v, want the error of the secondary bucket", err)
+		}
+		if versions, _ := primary.state(); len(versions) != 1 {
+			t.Errorf("primary: %d versions of the manifest, want 1", len(versions))
+		}
+	})
+}
@@ -15,6 +15,7 @@
 package cos
 
 import (
+	"crypto/tls"
 	"net"
 	"net/http"
 	"time"
@@ -24,8 +25,7 @@ import (
 	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/logging"
 
 	"github.com/IBM/ibm-cos-sdk-go/aws"
-	"github.com/IBM/ibm-cos-sdk-go/aws/credentials"
-	"github.com/IBM/ibm-cos-sdk-go/aws/credentials/ibmiam"
+	"github.com/IBM/ibm-cos-sdk-go/aws/request"
 	"github.com/IBM/ibm-cos-sdk-go/aws/session"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3"
 	"github.com/sirupsen/logrus"
@@ -37,70 +37,134 @@ Generating the session and the client to access the IBM Cloud Object Storage
 */
 func GenerateCOSSession() (*session.Session, *s3.S3) {
 	cfg := setupCosConfig()
-	s3Session := session.Must(session.NewSession(cfg))
+	s3Session := newSession(cfg)
 	s3Client := s3.New(s3Session)
 	return s3Session, s3Client
 }
 
 /*
-Setting up the Cloud Object Storage Configuration
+Generating the session and the client to access the secondary storage
 */
-func setupCosConfig() *aws.Config {
-	httpClient, err := NewHTTPClientWithSettings(HTTPClientSettings{
-		Connect:          60 * time.Second,
-		ExpectContinue:   1 * time.Second,
-		IdleConn:         60 * time.Second,
-		ConnKeepAlive:    60 * time.Second,
-		MaxHostIdleConns: 10,
-		ResponseHeader:   50 * time.Second,
-		TLSHandshake:     50 * time.Second,
-	})
-
-	global.CheckForError(
-		err,
-		"Error creating the customized HTTP client",
-		global.FAILURE,
+func GenerateSecondaryCOSSession() (*session.Session, *s3.S3) {
+	cfg := newCosConfig(
+		authCredentials{
+			method:          config.BackintConfig.AuthMethod(),
+			apikey:          config.BackintConfig.SecondaryApikey(),
+			accessKeyId:     config.BackintConfig.SecondaryHmacAccessKeyId(),
+			secretAccessKey: config.BackintConfig.SecondaryHmacSecretAccessKey(),
+			authEndpoint:    config.BackintConfig.SecondaryIBMAuthEndpoint(),
+			// auth_mode applies to both storages, a trusted profile
+			// is exchanged at the secondary endpoint
+			trustedProfileId: config.BackintConfig.TrustedProfileId(),
+			crTokenPath:      config.BackintConfig.CrTokenPath(),
+		},
+		config.BackintConfig.SecondaryRegion(),
+		config.BackintConfig.SecondaryEndpointUrl(),
 	)
+	s3Session := newSession(cfg)
+	s3Client := s3.New(s3Session)
+	return s3Session, s3Client
+}
+
+/*
+Generating a session for a given configuration.
+A CA bundle of the environment (AWS_CA_BUNDLE) replaces the root certificates
+of the HTTP client, the CA bundle of the network section takes precedence.
+*/
+func newSession(cfg *aws.Config) *session.Session {
+	rootCAs := getRootCAs(cfg.HTTPClient)
+	s3Session := session.Must(session.NewSession(cfg))
+	if rootCAs != nil {
+		setRootCAs(cfg.HTTPClient, rootCAs)
+	}
+	return s3Session
+}
 
-	var apikey string
+/*
+Setting up the Cloud Object Storage Configuration
+*/
+func setupCosConfig() *aws.Config {
+	var auth authCredentials
 	var region string
 	var endpoint string
-	var authEndpoint string
-	var authMethod string
 
 	if config.BackintConfig != nil {
-		apikey = config.BackintConfig.Apikey()
+		auth = authCredentials{
+			method:          config.BackintConfig.AuthMethod(),
+			apikey:          config.BackintConfig.Apikey(),
+			accessKeyId:     config.BackintConfig.HmacAccessKeyId(),
+			secretAccessKey: config.BackintConfig.HmacSecretAccessKey(),
+			authEndpoint:    config.BackintConfig.IBMAuthEndpoint(),
+
+			trustedProfileId: config.BackintConfig.TrustedProfileId(),
+			crTokenPath:      config.BackintConfig.CrTokenPath(),
+		}
 		region = config.BackintConfig.Region()
 		endpoint = config.BackintConfig.EndpointUrl()
-		authEndpoint = config.BackintConfig.IBMAuthEndpoint()
-		authMethod = config.BackintConfig.AuthMethod()
 
 	} else {
-		apikey, _ = global.ReadApikeyFromFile(global.Args.AuthKeypath)
+		auth = authCredentials{
+			method:       global.Args.AuthMode,
+			authEndpoint: global.Args.AuthEndpoint,
+		}
+		if auth.method == config.AUTH_HMAC {
+			auth.accessKeyId, auth.secretAccessKey, _ =
+				global.ReadHmacKeysFromFile(global.Args.AuthKeypath)
+		} else {
+			auth.apikey, _ = global.ReadApikeyFromFile(global.Args.AuthKeypath)
+		}
 		region = global.Args.Region
 		endpoint = global.Args.EndpointUrl
-		authEndpoint = global.Args.AuthEndpoint
-		authMethod = config.AUTH_APIKEY
 	}
 
-	var creds *credentials.Credentials
+	return newCosConfig(auth, region, endpoint)
+}
 
-	switch authMethod {
-	case config.AUTH_APIKEY:
-		creds = ibmiam.NewStaticCredentials(aws.NewConfig(),
-			authEndpoint,
-			apikey,
-			"",
-		)
-	default:
-		break
-	}
+/*
+Setting up the Cloud Object Storage Configuration
+for a given endpoint and credentials
+*/
+func newCosConfig(
+	auth authCredentials,
+	region string,
+	endpoint string,
+) *aws.Config {
+	tlsConfig, err := newTLSConfig()
+	global.CheckForError(
+		err,
+		"Error setting up the TLS configuration",
+		global.FAILURE,
+	)
+	proxy, err := newProxyFunc()
+	global.CheckForError(
+		err,
+		"Error setting up the proxy",
+		global.FAILURE,
+	)
+
+	httpSettings := newHTTPClientSettings()
+	httpSettings.TLSConfig = tlsConfig
+	httpSettings.Proxy = proxy
+	httpClient, err := NewHTTPClientWithSettings(httpSettings)
+
+	global.CheckForError(
+		err,
+		"Error creating the customized HTTP client",
+		global.FAILURE,
+	)
+
+	// The token exchange uses the same TLS and proxy settings
+	creds := newCredentials(auth, httpClient)
 
 	cfg := aws.NewConfig()
 	cfg = cfg.WithRegion(region)
 	cfg = cfg.WithEndpoint(endpoint)
 	cfg = cfg.WithCredentials(creds)
-	cfg = cfg.WithMaxRetries(5)
+	if config.BackintConfig != nil {
+		cfg = request.WithRetryer(cfg, newRetryer())
+	} else {
+		cfg = cfg.WithMaxRetries(5)
+	}
 	cfg = cfg.WithS3ForcePathStyle(true)
 	cfg = cfg.WithHTTPClient(httpClient)
 	cfg = cfg.WithDisableRestProtocolURICleaning(true) // do not delete first '/'
@@ -110,13 +174,49 @@ func setupCosConfig() *aws.Config {
 }
 
 /*
-Setting up the HTTP Client
+Getting the settings of the HTTP client from the network section.
+Without configuration, e.g. when called by snappy, the defaults are used.
+*/
+func newHTTPClientSettings() HTTPClientSettings {
+	if config.BackintConfig == nil {
+		return HTTPClientSettings{
+			Connect:          60 * time.Second,
+			ExpectContinue:   1 * time.Second,
+			IdleConn:         60 * time.Second,
+			ConnKeepAlive:    60 * time.Second,
+			MaxHostIdleConns: 10,
+			ResponseHeader:   50 * time.Second,
+			TLSHandshake:     50 * time.Second,
+		}
+	}
+
+	return HTTPClientSettings{
+		Connect:          config.BackintConfig.ConnectTimeout(),
+		ExpectContinue:   config.BackintConfig.ExpectContinueTimeout(),
+		IdleConn:         config.BackintConfig.IdleConnTimeout(),
+		ConnKeepAlive:    config.BackintConfig.KeepaliveInterval(),
+		MaxHostIdleConns: config.BackintConfig.MaxIdleConnsPerHost(),
+		MaxConnsPerHost:  config.BackintConfig.MaxConnsPerHost(),
+		ResponseHeader:   config.BackintConfig.ResponseHeaderTimeout(),
+		TLSHandshake:     config.BackintConfig.TLSHandshakeTimeout(),
+		ForceHTTP1:       config.BackintConfig.ForceHTTP1(),
+	}
+}
+
+/*
+Setting up the HTTP Client.
+HTTP/2 is negotiated unless HTTP/1.1 is forced.
 */
 func NewHTTPClientWithSettings(httpSettings HTTPClientSettings) (*http.Client, error) {
 	var httpClient http.Client
+	proxy := httpSettings.Proxy
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
 	tr := &http.Transport{
 		ResponseHeaderTimeout: httpSettings.ResponseHeader,
-		Proxy:                 http.ProxyFromEnvironment,
+		Proxy:                 proxy,
+		TLSClientConfig:       httpSettings.TLSConfig,
 		DialContext: (&net.Dialer{
 			KeepAlive: httpSettings.ConnKeepAlive,
 			DualStack: true,
@@ -130,9 +230,14 @@ func NewHTTPClientWithSettings(httpSettings HTTPClientSettings) (*http.Client, e
 		MaxConnsPerHost:       httpSettings.MaxConnsPerHost,
 	}
 
-	err := http2.ConfigureTransport(tr)
-	if err != nil {
-		return &httpClient, err
+	if httpSettings.ForceHTTP1 {
+		// A non-nil map disables HTTP/2
+		tr.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	} else {
+		err := http2.ConfigureTransport(tr)
+		if err != nil {
+			return &httpClient, err
+		}
 	}
 
 	return &http.Client{
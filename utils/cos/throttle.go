@@ -0,0 +1,145 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+)
+
+/*
+Setting up the bandwidth limiters shared by all pipes and parts of one run.
+The limits are taken from max_upload_bandwidth and max_download_bandwidth,
+a value of 0 means no limit.
+*/
+func InitializeBandwidthLimiters() {
+	start, end, hasSchedule := time.Duration(0), time.Duration(0), false
+	if config.BackintConfig.BandwidthSchedule() != "" {
+		var err error
+		start, end, err = config.ParseTimerange(config.BackintConfig.BandwidthSchedule())
+		hasSchedule = err == nil
+	}
+
+	uploadLimiter = newBandwidthLimiter(
+		config.BackintConfig.MaxUploadBandwidth(), hasSchedule, start, end,
+	)
+	downloadLimiter = newBandwidthLimiter(
+		config.BackintConfig.MaxDownloadBandwidth(), hasSchedule, start, end,
+	)
+
+	global.Logger.Info(fmt.Sprintf(
+		"Bandwidth limits: upload %d bytes/s, download %d bytes/s (0 = unlimited), schedule '%s'.",
+		config.BackintConfig.MaxUploadBandwidth(),
+		config.BackintConfig.MaxDownloadBandwidth(),
+		config.BackintConfig.BandwidthSchedule(),
+	))
+}
+
+/*
+Creating a token bucket limiter for a given rate in bytes per second
+Returns nil if no limit is set
+*/
+func newBandwidthLimiter(
+	rate int64,
+	hasSchedule bool,
+	start time.Duration,
+	end time.Duration,
+) *bandwidthLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &bandwidthLimiter{
+		rate:        float64(rate),
+		tokens:      float64(rate),
+		last:        time.Now(),
+		hasSchedule: hasSchedule,
+		start:       start,
+		end:         end,
+		now:         time.Now,
+		sleep:       time.Sleep,
+	}
+}
+
+/*
+Waiting until the given number of bytes may be transferred.
+The bytes are taken from the bucket even if not enough tokens are available,
+the caller sleeps until the deficit is refilled.
+*/
+func (l *bandwidthLimiter) wait(n int) {
+	if l == nil || n <= 0 {
+		return
+	}
+
+	now := l.now()
+	if !l.isActive(now) {
+		return
+	}
+
+	l.mu.Lock()
+	// Refilling the bucket, the burst size is one second of transfer
+	l.tokens = min(l.rate, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens -= float64(n)
+	deficit := -l.tokens
+	l.mu.Unlock()
+
+	if deficit > 0 {
+		l.sleep(time.Duration(deficit / l.rate * float64(time.Second)))
+	}
+}
+
+/*
+Returns true if the limit applies at the given time.
+Without schedule the limit applies all the time,
+a schedule may span midnight, e.g. 22:00-06:00.
+*/
+func (l *bandwidthLimiter) isActive(now time.Time) bool {
+	if !l.hasSchedule {
+		return true
+	}
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	offset := now.Sub(midnight)
+	if l.start <= l.end {
+		return offset >= l.start && offset < l.end
+	}
+	return offset >= l.start || offset < l.end
+}
+
+/*
+Wrapping a reader with a bandwidth limiter
+*/
+func newThrottledReader(r io.Reader, limiter *bandwidthLimiter) io.Reader {
+	if limiter == nil {
+		return r
+	}
+	return &throttledReader{r: r, limiter: limiter}
+}
+
+/*
+Reader function limiting the bandwidth.
+Large reads are split, so that the limit is applied smoothly.
+*/
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > THROTTLE_READ_SIZE {
+		p = p[:THROTTLE_READ_SIZE]
+	}
+	n, err := t.r.Read(p)
+	t.limiter.wait(n)
+	return n, err
+}
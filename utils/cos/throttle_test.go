@@ -0,0 +1,166 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+)
+
+// Clock advanced by the test and by the sleeps of the limiter
+type fakeThrottleClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeThrottleClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeThrottleClock) Sleep(d time.Duration) {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+}
+
+/*
+Creating a limiter using a fake clock starting at the given time of day
+*/
+func newFakeClockLimiter(t *testing.T, rate int64, schedule string, hour int, minute int) (*bandwidthLimiter, *fakeThrottleClock) {
+	t.Helper()
+	start, end, hasSchedule := time.Duration(0), time.Duration(0), false
+	if schedule != "" {
+		var err error
+		start, end, err = config.ParseTimerange(schedule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hasSchedule = true
+	}
+	clock := &fakeThrottleClock{now: time.Date(2026, 3, 1, hour, minute, 0, 0, time.UTC)}
+	limiter := newBandwidthLimiter(rate, hasSchedule, start, end)
+	limiter.now = clock.Now
+	limiter.sleep = clock.Sleep
+	limiter.last = clock.now
+	return limiter, clock
+}
+
+func TestBandwidthLimiterIsActive(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		hour     int
+		minute   int
+		want     bool
+	}{
+		{"without schedule", "", 12, 0, true},
+		{"within daytime window", "08:00-18:00", 12, 0, true},
+		{"start of daytime window", "08:00-18:00", 8, 0, true},
+		{"end of daytime window", "08:00-18:00", 18, 0, false},
+		{"before daytime window", "08:00-18:00", 7, 59, false},
+		{"window crossing midnight before midnight", "22:00-06:00", 23, 30, true},
+		{"window crossing midnight at midnight", "22:00-06:00", 0, 0, true},
+		{"window crossing midnight after midnight", "22:00-06:00", 5, 59, true},
+		{"end of window crossing midnight", "22:00-06:00", 6, 0, false},
+		{"outside window crossing midnight", "22:00-06:00", 12, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, clock := newFakeClockLimiter(t, 100, tt.schedule, tt.hour, tt.minute)
+			if got := limiter.isActive(clock.now); got != tt.want {
+				t.Errorf("isActive(%s) = %v, want %v", clock.now.Format("15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBandwidthLimiterRefill(t *testing.T) {
+	limiter, clock := newFakeClockLimiter(t, 100, "", 12, 0)
+
+	// The bucket starts with one second of transfer
+	limiter.wait(100)
+	if len(clock.sleeps) != 0 {
+		t.Fatalf("sleeps %v within the burst, want none", clock.sleeps)
+	}
+
+	// The deficit is refilled at the rate
+	limiter.wait(50)
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != 500*time.Millisecond {
+		t.Fatalf("sleeps %v after exceeding the burst, want [500ms]", clock.sleeps)
+	}
+
+	// Half a second refills half of the rate
+	clock.now = clock.now.Add(500 * time.Millisecond)
+	limiter.wait(50)
+	if len(clock.sleeps) != 1 {
+		t.Fatalf("sleeps %v after the refill, want no further sleep", clock.sleeps)
+	}
+
+	// An idle period does not refill more than one second of transfer
+	clock.now = clock.now.Add(time.Hour)
+	limiter.wait(150)
+	if len(clock.sleeps) != 2 || clock.sleeps[1] != 500*time.Millisecond {
+		t.Fatalf("sleeps %v after an idle period, want the burst limited to one second", clock.sleeps)
+	}
+}
+
+func TestBandwidthLimiterSchedule(t *testing.T) {
+	limiter, clock := newFakeClockLimiter(t, 100, "22:00-06:00", 12, 0)
+
+	// Outside the schedule no tokens are taken
+	limiter.wait(1000)
+	if len(clock.sleeps) != 0 {
+		t.Fatalf("sleeps %v outside the schedule, want none", clock.sleeps)
+	}
+
+	clock.now = clock.now.Add(11 * time.Hour)
+	limiter.wait(100)
+	limiter.wait(100)
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != time.Second {
+		t.Errorf("sleeps %v within the schedule, want [1s]", clock.sleeps)
+	}
+}
+
+func TestThrottledReader(t *testing.T) {
+	if reader := newThrottledReader(bytes.NewReader(nil), nil); reader == nil {
+		t.Fatal("newThrottledReader() without limiter = nil")
+	} else if _, throttled := reader.(*throttledReader); throttled {
+		t.Error("reader without limiter is throttled")
+	}
+
+	const size = 3*THROTTLE_READ_SIZE + 10
+	limiter, clock := newFakeClockLimiter(t, THROTTLE_READ_SIZE, "", 12, 0)
+	data, err := io.ReadAll(newThrottledReader(bytes.NewReader(make([]byte, size)), limiter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != size {
+		t.Errorf("read %d bytes, want %d", len(data), size)
+	}
+
+	// One second of burst, the remaining data takes two seconds and some
+	var slept time.Duration
+	for _, d := range clock.sleeps {
+		slept += d
+	}
+	want := time.Duration(size-THROTTLE_READ_SIZE) * time.Second / THROTTLE_READ_SIZE
+	if slept < want-time.Millisecond || slept > want+time.Millisecond {
+		t.Errorf("slept %v, want %v", slept, want)
+	}
+}
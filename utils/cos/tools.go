@@ -15,10 +15,13 @@
 package cos
 
 import (
-	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"time"
 
@@ -37,59 +40,161 @@ Reader function for uploading data
 func (r *backintReader) Read(p []byte) (int, error) {
 	readFromPipe, err := r.r.Read(p)
 	r.noOfbytes += int64(readFromPipe)
+	r.hash.Write(p[:readFromPipe])
 	return readFromPipe, err
 	// }
 }
 
 /*
-Setting up the information for uploading data to IBM Cloud Object Storage
+Reader function failing if more or less data is read than expected,
+so that the upload is aborted before it completes
+*/
+func (r *sizeCheckingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.read += int64(n)
+	if r.read > r.expected || (err == io.EOF && r.read != r.expected) {
+		return n, fmt.Errorf(
+			"size of '%s' changed during backup, expected %d bytes, read %d bytes",
+			r.sourcePath,
+			r.expected,
+			r.read,
+		)
+	}
+	return n, err
+}
+
+/*
+Getting the checksum of the data read from pipe
+*/
+func (r *backintReader) checksum() string {
+	return hex.EncodeToString(r.hash.Sum(nil))
+}
+
+/*
+Setting up the information for uploading data to IBM Cloud Object Storage.
+A checksum known in advance is stored in the object metadata.
+The returned function stops the asynchronous stages of the upload stream
+and must be called after the upload, regardless of its success.
 */
 func setupUploadInputInfo(
 	Key string,
 	sourcePath string,
-) (s3manager.UploadInput, *backintReader) {
-	global.Logger.Debug("Opening the input pipe for reading.")
-	rPipe, err := os.OpenFile(sourcePath, os.O_CREATE, os.ModeNamedPipe)
-	global.CheckForError(
-		err,
-		fmt.Sprintf("Error opening named pipe '%s'", sourcePath),
-		global.FAILURE,
-	)
-
+	source io.Reader,
+	checksum string,
+) (s3manager.UploadInput, *backintReader, func(error)) {
 	readerFromPipe := backintReader{
-		r:         rPipe,
+		r:         source,
 		noOfbytes: 0,
+		hash:      sha256.New(),
 	}
 
-	tags := config.BackintConfig.Tags()
-	var pLockMode *string
-	var pLockDate *time.Time
-	if config.BackintConfig.ObjectLockRetentionMode() == "cmp" {
-		lockMode := global.OBJECTLOCKMODE
-		lockDate := config.BackintConfig.ObjectLockRetentionDate()
-		pLockMode = &lockMode
-		pLockDate = &lockDate
+	// Compressing and encrypting the data before it leaves the host
+	var err error
+	var body io.Reader = &readerFromPipe
+	metadata := make(map[string]*string)
+	closeBody := func(error) {}
+	if config.BackintConfig.IsCompressionEnabled() {
+		var compressionMetadata map[string]*string
+		var compressed *io.PipeReader
+		compressed, compressionMetadata, err = newCompressingReader(body)
+		global.CheckForError(
+			err,
+			fmt.Sprintf("Error setting up the compression for '%s'", sourcePath),
+			global.FAILURE,
+		)
+		maps.Copy(metadata, compressionMetadata)
+		body = compressed
+		// Unblocking the compressor if the upload stopped reading
+		closeBody = func(err error) {
+			_ = compressed.CloseWithError(err)
+		}
+	}
+	if config.BackintConfig.IsEncryptionEnabled() {
+		var encryptionMetadata map[string]*string
+		body, encryptionMetadata, err = newEncryptingReader(body)
+		global.CheckForError(
+			err,
+			fmt.Sprintf("Error setting up the encryption for '%s'", sourcePath),
+			global.FAILURE,
+		)
+		maps.Copy(metadata, encryptionMetadata)
+	}
+
+	// Limiting the upload bandwidth of all pipes
+	body = newThrottledReader(body, uploadLimiter)
+
+	// Deleting an object deletes the manifest of its backup
+	if ManifestKey != "" {
+		metadata[METADATA_MANIFEST_KEY] = aws.String(ManifestKey)
 	}
-	lockLegalHold := config.BackintConfig.ObjectLockLegalHoldStatus()
+	// The checksum of a file is known before the upload
+	if checksum != "" {
+		maps.Copy(metadata, getChecksumMetadata(checksum))
+	}
+
+	tags := config.BackintConfig.TagsForPipe(sourcePath)
+	pLockMode, pLockDate, pLockLegalHold := getObjectLockSettings()
 
 	input := s3manager.UploadInput{
 		Bucket:                    aws.String(config.BackintConfig.BucketName()),
 		Key:                       aws.String(Key),
-		Body:                      &readerFromPipe,
-		ObjectLockLegalHoldStatus: &lockLegalHold,
+		Body:                      body,
+		Metadata:                  metadata,
+		ObjectLockLegalHoldStatus: pLockLegalHold,
 		ObjectLockMode:            pLockMode,
 		ObjectLockRetainUntilDate: pLockDate,
 		Tagging:                   &tags,
 	}
 
-	return input, &readerFromPipe
+	return input, &readerFromPipe, closeBody
+}
+
+/*
+Getting the object lock mode, retention date and legal hold
+of new object versions
+*/
+func getObjectLockSettings() (*string, *time.Time, *string) {
+	var pLockMode *string
+	var pLockDate *time.Time
+	if config.BackintConfig.ObjectLockRetentionMode() == "cmp" {
+		lockMode := global.OBJECTLOCKMODE
+		lockDate := config.BackintConfig.ObjectLockRetentionDate()
+		pLockMode = &lockMode
+		pLockDate = &lockDate
+	}
+	// The legal hold is only sent if set, storages without
+	// object lock reject the header
+	var pLockLegalHold *string
+	if config.BackintConfig.ObjectLockLegalHoldStatus() == s3.ObjectLockLegalHoldStatusOn {
+		lockLegalHold := s3.ObjectLockLegalHoldStatusOn
+		pLockLegalHold = &lockLegalHold
+	}
+	return pLockMode, pLockDate, pLockLegalHold
 }
 
 /*
-Getting the number of parts from Cloud
+Getting the number of parts and the chunksize for downloading an object
+If download_range_size is set, the object is split into byte ranges
+of this size regardless of the parts it was uploaded with.
+Returns true as third value if the parts are byte ranges.
 */
-func calculateNumberOfParts(s3Client *s3.S3, size int64, Key string) (int64, int64) {
-	noOfParts := getPartsCount(s3Client, Key)
+func calculateNumberOfParts(
+	size int64,
+	Key string,
+	partsCount int64,
+) (int64, int64, bool) {
+	if rangeSize := config.BackintConfig.DownloadRangeSize(); rangeSize > 0 && size > 0 {
+		noOfRanges := (size + rangeSize - 1) / rangeSize
+		global.Logger.Info(fmt.Sprintf(
+			"Downloading '%s' with '%d' byte ranges of '%d' bytes.",
+			Key,
+			noOfRanges,
+			rangeSize),
+		)
+		return noOfRanges, rangeSize, true
+	}
+
+	noOfParts := max(partsCount, 1)
 	chunksize := size / noOfParts
 	if size%noOfParts != 0 {
 		chunksize++
@@ -101,7 +206,7 @@ func calculateNumberOfParts(s3Client *s3.S3, size int64, Key string) (int64, int
 		noOfParts,
 		chunksize),
 	)
-	return noOfParts, chunksize
+	return noOfParts, chunksize, false
 }
 
 /*
@@ -113,12 +218,14 @@ Generating the infos for downloading the parts from COS
 	Number of parts to be downloaded
 */
 func generateDownloadParts(
-	s3Client *s3.S3,
+	bucket string,
 	size int64,
 	Key string,
+	versionId string,
+	partsCount int64,
 ) ([]DownloadPart, int64) {
 	var downloadParts []DownloadPart
-	noOfParts, chunksize := calculateNumberOfParts(s3Client, size, Key)
+	noOfParts, chunksize, ranged := calculateNumberOfParts(size, Key, partsCount)
 
 	for p := range noOfParts {
 		start := p * chunksize
@@ -127,92 +234,24 @@ func generateDownloadParts(
 			end = size - 1
 		}
 
-		byteRange := fmt.Sprintf("bytes %d-%d", start, end)
-
 		dp := DownloadPart{
 			Key:        Key,
+			bucket:     bucket,
+			versionId:  versionId,
 			numParts:   noOfParts,
 			partNumber: p + 1,
-			byteRange:  byteRange,
+			ranged:     ranged,
+			size:       max(end-start+1, 0),
+		}
+		// An empty object has no byte range
+		if end >= start {
+			dp.byteRange = fmt.Sprintf("bytes=%d-%d", start, end)
 		}
 		downloadParts = append(downloadParts, dp)
 	}
 	return downloadParts, int64(len(downloadParts))
 }
 
-/*
-Trying to write downloaded part to pipe.
-If the next part in the row is already stored in downloadedParts, write it to pipe,
-increase the nextIndex counter and
-delete the part from downloadedParts
-
-!!Caution:!!
-This function must be handled carefully.
-All writes and reads to the map (downloadedParts) and to the pipe
-must be locked directly before and after the action!
-*/
-func sendDataToHANA(fifo *os.File,
-	nextIndex *int64,
-	downloadedParts *ByteMap,
-	index int64,
-	pipeBufferSize int,
-	buffer *bytes.Buffer,
-) bool {
-
-	writeToPipeLock.Lock()
-
-	// Storing data into buffer
-	(*downloadedParts)[index] = buffer.Bytes()
-
-	global.Logger.Debug(fmt.Sprintf(
-		"'%s': Writing part #%d to buffer, nextIndex = %d.",
-		fifo.Name(),
-		index,
-		*nextIndex,
-	))
-
-	global.Logger.Debug(fmt.Sprintf(
-		"'%s': downloadedParts length: %d",
-		fifo.Name(),
-		len(*downloadedParts),
-	))
-
-	writeToPipeLock.Unlock()
-
-	for {
-		writeToPipeLock.Lock()
-
-		data := getBufferedDataForIndex(downloadedParts, nextIndex, fifo.Name())
-		if data == nil {
-			writeToPipeLock.Unlock()
-			break
-		}
-
-		global.Logger.Debug(fmt.Sprintf(
-			"'%s': Writing part #%d to pipe",
-			fifo.Name(),
-			*nextIndex,
-		))
-
-		if !writeDataToPipe(fifo, data, nextIndex, pipeBufferSize) {
-			writeToPipeLock.Unlock()
-			return false
-		}
-
-		deleteBufferedDataForIndex(downloadedParts, nextIndex, fifo.Name())
-
-		*nextIndex++
-
-		global.Logger.Debug(fmt.Sprintf(
-			"'%s': Increased nextIndex to #%d",
-			fifo.Name(),
-			*nextIndex,
-		))
-		writeToPipeLock.Unlock()
-	}
-	return true
-}
-
 /*
 Writing the data to pipe
 Due to hang problems (looks like it is caused by HANA processing itself) the
@@ -220,7 +259,12 @@ data must be splitted into smaller portions so that HANA can process the data.
 The size of the portion is set by config parameter pipe_chunksize_KB.
 In addition, writing to pipe stops after 30 seconds, if not successful
 */
-func writeDataToPipe(fifo *os.File, data []byte, nextIndex *int64, pipeBufferSize int) bool {
+func writeDataToPipe(fifo restoreTarget, data []byte, nextIndex *int64, pipeBufferSize int) bool {
+	// Discarded data cannot hang, no portions and timeouts are needed
+	if isDiscarded(fifo) {
+		_, err := fifo.Write(data)
+		return err == nil
+	}
 
 	// Processing the data portions
 	for i := 0; i < len(data); i += pipeBufferSize {
@@ -268,43 +312,183 @@ func writeDataToPipe(fifo *os.File, data []byte, nextIndex *int64, pipeBufferSiz
 	return true
 }
 
-func getBufferedDataForIndex(
-	downloadedParts *ByteMap,
-	index *int64,
-	pipeName string) []byte {
-	data, available := (*downloadedParts)[*index]
-
-	if !available {
-		global.Logger.Debug(fmt.Sprintf(
-			"'%s': part with %d not found in buffer.",
-			pipeName,
-			*index,
-		))
+/*
+Getting the decoder for the downloaded data of an object.
+The data is decrypted first and decompressed afterwards,
+in reverse order of the encoding during upload.
+Returns nil if the object data can be written to pipe unchanged.
+*/
+func getRestoreDecoder(metadata map[string]*string) func(io.Reader) (io.ReadCloser, error) {
+	encrypted := isEncrypted(metadata)
+	compressed := isCompressed(metadata)
+	if !encrypted && !compressed {
 		return nil
 	}
-	return data
+
+	return func(r io.Reader) (io.ReadCloser, error) {
+		var err error
+		if encrypted {
+			r, err = newDecryptingReader(r, metadata)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if compressed {
+			return newDecompressingReader(r, metadata)
+		}
+		return io.NopCloser(r), nil
+	}
 }
 
-func deleteBufferedDataForIndex(
-	downloadedParts *ByteMap,
-	index *int64,
-	pipeName string) {
-	global.Logger.Debug(fmt.Sprintf(
-		"'%s': Deleting part #%d from buffer",
-		pipeName,
-		*index,
-	))
-	delete(*downloadedParts, *index)
+/*
+Setting up a stream decoding the downloaded data before it is written to pipe.
+The downloaded parts are written to the stream in the correct order,
+the decoded data is written to the pipe asynchronously.
+*/
+func newRestoreStream(
+	fifo restoreTarget,
+	pipeBufferSize int,
+	decode func(io.Reader) (io.ReadCloser, error),
+) *restoreStream {
+	pr, pw := io.Pipe()
+	stream := &restoreStream{
+		name:      fifo.Name(),
+		pw:        pw,
+		done:      make(chan error, 1),
+		discarded: isDiscarded(fifo),
+	}
+
+	go func() {
+		err := forwardDecodedData(fifo, pr, pipeBufferSize, decode)
+		_ = pr.CloseWithError(err)
+		stream.done <- err
+	}()
+	return stream
+}
+
+/*
+Writer function for passing the downloaded data to the decoder
+*/
+func (s *restoreStream) Write(p []byte) (int, error) {
+	return s.pw.Write(p)
+}
+
+/*
+Getting the name of the pipe the decoded data is written to
+*/
+func (s *restoreStream) Name() string {
+	return s.name
 }
 
-func openPipeForWriting(pipeName string) *os.File {
-	// Opening destination pipe for writing
-	fifo, err := os.OpenFile(pipeName, os.O_WRONLY, os.ModeNamedPipe)
+/*
+Closing the stream after all parts are written
+and waiting until the decoded data is written to pipe
+*/
+func (s *restoreStream) Close() error {
+	_ = s.pw.Close()
+	return <-s.done
+}
+
+/*
+Aborting the stream in case downloading a part failed
+*/
+func (s *restoreStream) abort(err error) error {
+	_ = s.pw.CloseWithError(err)
+	return <-s.done
+}
+
+/*
+Reading the decoded data and writing it to pipe
+*/
+func forwardDecodedData(
+	fifo restoreTarget,
+	pr *io.PipeReader,
+	pipeBufferSize int,
+	decode func(io.Reader) (io.ReadCloser, error),
+) error {
+	decoded, err := decode(pr)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = decoded.Close()
+	}()
+
+	buffer := make([]byte, RESTORE_STREAM_BUFFER_SIZE)
+	index := int64(1)
+	for {
+		n, err := decoded.Read(buffer)
+		if n > 0 {
+			if !writeDataToPipe(fifo, buffer[:n], &index, pipeBufferSize) {
+				return errors.New("could not write to pipe")
+			}
+			index++
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+/*
+Opening the restore destination for writing.
+The destination is either a named pipe created by SAP HANA
+or a regular file, which is created or truncated.
+*/
+func openRestoreDestination(destination string) *os.File {
+	info, err := os.Stat(destination)
+	if err == nil && info.Mode()&os.ModeNamedPipe != 0 {
+		// Opening destination pipe for writing
+		fifo, err := os.OpenFile(destination, os.O_WRONLY, os.ModeNamedPipe)
+		global.CheckForError(err,
+			fmt.Sprintf("Error opening named pipe '%s'", destination),
+			global.FAILURE,
+		)
+		return fifo
+	}
+
+	global.Logger.Info(fmt.Sprintf(
+		"'%s' is not a named pipe, restoring to a regular file.", destination,
+	))
+	file, err := os.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
 	global.CheckForError(err,
-		fmt.Sprintf("Error opening named pipe '%s'", pipeName),
+		fmt.Sprintf("Error opening file '%s'", destination),
 		global.FAILURE,
 	)
-	return fifo
+	return file
+}
+
+/*
+Writer function discarding the data of a verified object
+*/
+func (discardTarget) Write(p []byte) (int, error) {
+	return io.Discard.Write(p)
+}
+
+/*
+Getting the name shown for the destination of a verified object
+*/
+func (discardTarget) Name() string {
+	return VERIFY_DESTINATION_NAME
+}
+
+/*
+Returns true if the data written to the target is discarded,
+because the object is only verified
+*/
+func isDiscarded(target restoreTarget) bool {
+	switch t := target.(type) {
+	case discardTarget:
+		return true
+	case *checksumTarget:
+		return isDiscarded(t.target)
+	case *restoreStream:
+		return t.discarded
+	}
+	return false
 }
 
 func getPipeBufferSize(fifo *os.File) int {
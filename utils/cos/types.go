@@ -15,9 +15,20 @@
 package cos
 
 import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/tls"
+	"hash"
 	"io"
-	"os"
+	"net/http"
+	"net/url"
+	"sync"
 	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/IBM/ibm-cos-sdk-go/aws/credentials"
+	"github.com/IBM/ibm-cos-sdk-go/aws/session"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
 )
 
 // Datatype representing the HTTP settings
@@ -31,6 +42,9 @@ type HTTPClientSettings struct {
 	ResponseHeader   time.Duration
 	TLSHandshake     time.Duration
 	MaxConnsPerHost  int
+	TLSConfig        *tls.Config
+	Proxy            func(*http.Request) (*url.URL, error)
+	ForceHTTP1       bool
 }
 
 // Datatype representing the result for one Cloud Object Storage action (Upload/Download/Delete)
@@ -42,24 +56,41 @@ type Result struct {
 	SourcePath string
 	Key        string
 	ETag       string
+	VersionId  string
+	Checksum   string
+}
+
+// Datatype representing the object version holding the uploaded data
+type uploadedObject struct {
+	ETag      string
+	VersionId string
+	size      int64
 }
 
 // Datatype representing information of one IBM Cloud Object Storage Object
 type CosObject struct {
 	ETag        string
+	VersionId   string
 	Key         string
 	Destination string
+	Verify      bool
 	Found       bool
+	Secondary   bool
 	Status      string
+	Err         error
 	NextIndex   *int64
 }
 
 // Datatype representing the information of one part for downloading an object
 type DownloadPart struct {
 	Key        string
+	bucket     string
+	versionId  string
 	numParts   int64
 	partNumber int64
 	byteRange  string
+	ranged     bool
+	size       int64
 }
 
 // Datatype representing the result of downloading one part of an object
@@ -71,18 +102,209 @@ type DownloadPartResult struct {
 
 // Datatype representing the parameters for the runDownloadSinglePart call
 type DownloadSingePart struct {
-	fifo               *os.File
-	partsNotYetWritten ByteMap
-	downloadPart       DownloadPart
-	nextIndex          *int64
-	pipeBufferSize     int
+	window       *reorderWindow
+	downloadPart DownloadPart
+	eTag         string
+	secondary    bool
 }
 
-// Representation for easier handling of a Bytemap
-type ByteMap map[int64][]byte
+// Type for writing the data of the next part directly to pipe
+type partPipeWriter struct {
+	window     *reorderWindow
+	partNumber int64
+	written    int64
+	skip       int64
+}
+
+// Datatype representing the parts of one object downloaded ahead
+// of the part written to pipe
+type reorderWindow struct {
+	mu             sync.Mutex
+	cond           *sync.Cond
+	target         restoreTarget
+	nextIndex      *int64
+	size           int64
+	parts          map[int64]*bytes.Buffer
+	partSize       int64
+	pipeBufferSize int
+	aborted        bool
+}
 
 // Type for reading from pipe directly to upload data
 type backintReader struct {
 	r         io.Reader
 	noOfbytes int64
+	hash      hash.Hash
+}
+
+// Type for reading a source of known size,
+// reading fails as soon as the size differs
+type sizeCheckingReader struct {
+	r          io.Reader
+	sourcePath string
+	expected   int64
+	read       int64
+}
+
+// Destination the downloaded data is written to in the correct order
+type restoreTarget interface {
+	io.Writer
+	Name() string
+}
+
+// Type for calculating the checksum of the data written to pipe
+type checksumTarget struct {
+	target restoreTarget
+	hash   hash.Hash
+}
+
+// Type for decoding the downloaded data before it is written to the pipe
+type restoreStream struct {
+	name      string
+	pw        *io.PipeWriter
+	done      chan error
+	discarded bool
+}
+
+// Destination of a verified object, the data is discarded
+type discardTarget struct{}
+
+// Nonce generator for the frames of one encrypted stream
+type streamNonce struct {
+	prefix  []byte
+	counter uint32
+}
+
+// Type for encrypting the data read from pipe before uploading
+type encryptingReader struct {
+	src    io.Reader
+	aead   cipher.AEAD
+	nonce  streamNonce
+	plain  []byte
+	carry  int
+	sealed []byte
+	out    []byte
+	done   bool
+}
+
+// Type for decrypting the downloaded data before writing to pipe
+type decryptingReader struct {
+	src    io.Reader
+	aead   cipher.AEAD
+	nonce  streamNonce
+	sealed []byte
+	carry  int
+	plain  []byte
+	out    []byte
+	done   bool
+}
+
+// Datatype bounding the parts transferred concurrently by all pipes
+type TransferScheduler struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	maxParts int
+	maxBytes int64
+	streams  int
+	parts    int
+	bytes    int64
+	// Memory of the parts of all restored objects
+	// downloaded ahead of the part written to pipe
+	maxBufferBytes int64
+	bufferBytes    int64
+}
+
+// Sizes of the parts of an upload
+type partSchedule struct {
+	partSize int64
+	// The part size grows if the size of the stream is not known
+	growing bool
+	// Limit of the growing part size, MAX_UPLOAD_PART_SIZE if 0
+	maxPartSize int64
+}
+
+// Datatype tracking the parts of one upload acquired from the scheduler
+type uploadBudget struct {
+	mu        sync.Mutex
+	scheduler *TransferScheduler
+	schedule  partSchedule
+	held      map[int64]bool
+	closed    bool
+}
+
+// Reader acquiring the parts of all uploads before the data is read
+type budgetReader struct {
+	src        io.Reader
+	budgets    []*uploadBudget
+	schedule   partSchedule
+	partNumber int64
+	left       int64
+}
+
+// Datatype representing one part of an upload with growing part sizes
+type uploadPart struct {
+	number int64
+	data   []byte
+}
+
+// Datatype representing a token bucket shared by all pipes and parts
+type bandwidthLimiter struct {
+	mu          sync.Mutex
+	rate        float64
+	tokens      float64
+	last        time.Time
+	hasSchedule bool
+	start       time.Duration
+	end         time.Duration
+	// Clock of the limiter, replaced in tests
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// Type for reading data with limited bandwidth
+type throttledReader struct {
+	r       io.Reader
+	limiter *bandwidthLimiter
+}
+
+// Datatype representing one storage a backup is written to
+type uploadTarget struct {
+	session *session.Session
+	client  *s3.S3
+	bucket  string
+}
+
+// Type for splitting one upload stream into several streams
+type uploadTee struct {
+	writers []*io.PipeWriter
+	failed  []bool
+}
+
+// Datatype representing the retry policy of all requests
+type backintRetryer struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	jitter      string
+	classes     []string
+}
+
+// Datatype representing the authentication of one storage
+type authCredentials struct {
+	method          string
+	apikey          string
+	accessKeyId     string
+	secretAccessKey string
+	authEndpoint    string
+
+	// Used by the secondary storage as well
+	trustedProfileId string
+	crTokenPath      string
+}
+
+// Datatype representing the credentials of a trusted profile,
+// expiring before the retrieved access token does
+type trustedProfileProvider struct {
+	credentials.Expiry
+	authenticator *core.ContainerAuthenticator
 }
@@ -15,12 +15,32 @@
 package cos
 
 import (
+	"bytes"
 	"sync"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws/session"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
 )
 
-/*
-Lock for single execution
-Used for avoid concurrent writes/reads to/from the temporary memory holding
-the parts read from Cloud Object Storage
-*/
-var writeToPipeLock sync.Mutex
+// Buffers holding downloaded parts, reused by all objects of one run
+var partBufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// Buffers holding parts read for an upload, reused by all objects of one run
+var uploadPartBufferPool sync.Pool
+
+// Scheduler bounding the transfers of all pipes of one run
+var Scheduler *TransferScheduler
+
+// Bandwidth limiters shared by all pipes and parts of one run
+var uploadLimiter *bandwidthLimiter
+var downloadLimiter *bandwidthLimiter
+
+// Connection to the secondary storage, nil if not configured
+var SecondarySession *session.Session
+var SecondaryClient *s3.S3
+
+// Key of the manifest of the current backup, stored in the metadata
+// of its objects, empty if no manifest is written
+var ManifestKey string
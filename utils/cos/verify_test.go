@@ -0,0 +1,189 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/credentials"
+	"github.com/IBM/ibm-cos-sdk-go/aws/session"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+)
+
+// Stub of a bucket holding one object version to be verified
+type verifiedObjectBucket struct {
+	data     []byte
+	metadata map[string]*string
+}
+
+func (b *verifiedObjectBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for name, value := range b.metadata {
+		w.Header().Set("X-Amz-Meta-"+name, aws.StringValue(value))
+	}
+	w.Header().Set("ETag", "\"verified-etag\"")
+	w.Header().Set("X-Amz-Version-Id", r.URL.Query().Get("versionId"))
+	switch r.Method {
+	case http.MethodHead:
+		w.Header().Set("Content-Length", strconv.Itoa(len(b.data)))
+	case http.MethodGet:
+		w.Header().Set("Content-Length", strconv.Itoa(len(b.data)))
+		_, _ = w.Write(b.data)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+/*
+Setting up the configuration with an encryption key and
+a client of a stub bucket holding the given object for one test
+*/
+func setupVerifiedObject(t *testing.T, masterKey []byte, object func() ([]byte, map[string]*string)) *s3.S3 {
+	t.Helper()
+	if global.Logger == nil {
+		global.Logger = logrus.New()
+		global.Logger.SetOutput(io.Discard)
+	}
+	previousConfig := config.BackintConfig
+	previousScheduler := Scheduler
+	config.BackintConfig = config.BackintConfigT{
+		"bucket":               "backups",
+		"max_concurrency":      "2",
+		"encryption_algorithm": config.ENCRYPTION_AES256GCM,
+		"encryption_key":       base64.StdEncoding.EncodeToString(masterKey),
+		"encryption_key_id":    "verify-key",
+	}
+	Scheduler = nil
+	t.Cleanup(func() {
+		config.BackintConfig = previousConfig
+		Scheduler = previousScheduler
+	})
+
+	data, metadata := object()
+	server := httptest.NewServer(&verifiedObjectBucket{data: data, metadata: metadata})
+	t.Cleanup(server.Close)
+	return s3.New(session.Must(session.NewSession(aws.NewConfig().
+		WithEndpoint(server.URL).
+		WithRegion("us-east-1").
+		WithCredentials(credentials.NewStaticCredentials("key-id", "secret", "")).
+		WithS3ForcePathStyle(true).
+		WithMaxRetries(0),
+	)))
+}
+
+func TestVerifyDownload(t *testing.T) {
+	masterKey := make([]byte, config.ENCRYPTION_KEY_LENGTH)
+	if _, err := rand.Read(masterKey); err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("verified backup "), 4096)
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+
+	// Encrypting the data like an upload with the stored checksum
+	encrypted := func(tamper bool, checksum string) func() ([]byte, map[string]*string) {
+		return func() ([]byte, map[string]*string) {
+			reader, metadata, err := newEncryptingReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sealed, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tamper {
+				sealed[len(sealed)/2] ^= 0xff
+			}
+			metadata[METADATA_CHECKSUM_SHA256] = aws.String(checksum)
+			return sealed, metadata
+		}
+	}
+
+	tests := []struct {
+		name    string
+		object  func() ([]byte, map[string]*string)
+		wantErr string
+	}{
+		{
+			name: "plain object",
+			object: func() ([]byte, map[string]*string) {
+				return data, map[string]*string{METADATA_CHECKSUM_SHA256: aws.String(checksum)}
+			},
+		},
+		{
+			name:   "encrypted object",
+			object: encrypted(false, checksum),
+		},
+		{
+			name: "checksum mismatch",
+			object: func() ([]byte, map[string]*string) {
+				return data, map[string]*string{METADATA_CHECKSUM_SHA256: aws.String(strings.Repeat("0", 64))}
+			},
+			wantErr: errChecksumMismatch.Error(),
+		},
+		{
+			name:    "encrypted object with a wrong checksum",
+			object:  encrypted(false, strings.Repeat("0", 64)),
+			wantErr: errChecksumMismatch.Error(),
+		},
+		{
+			name:    "tampered encrypted object",
+			object:  encrypted(true, checksum),
+			wantErr: "authentication of encrypted data failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s3Client := setupVerifiedObject(t, masterKey, tt.object)
+			nextIndex := int64(1)
+			result := Download(s3Client, CosObject{
+				Key:         "HDB/TEN/databackup_0_1",
+				ETag:        "verified-etag",
+				VersionId:   "v1",
+				Destination: "/tmp/backint/databackup_0_1",
+				Verify:      true,
+				NextIndex:   &nextIndex,
+			})
+
+			if tt.wantErr != "" {
+				if result.Err == nil || !strings.Contains(result.Err.Error(), tt.wantErr) {
+					t.Fatalf("Download() error = %v, want %q", result.Err, tt.wantErr)
+				}
+				return
+			}
+			if result.Err != nil {
+				t.Fatalf("Download() error = %v", result.Err)
+			}
+			if result.TargetSize == 0 || result.Checksum != checksum || result.ETag != "verified-etag" {
+				t.Errorf("Download() = %+v, want the checksum %s of the verified data", result, checksum)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package cos
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/config"
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/global"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/credentials"
+	"github.com/IBM/ibm-cos-sdk-go/aws/session"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+)
+
+// One version or delete marker listed by the stub bucket
+type listedVersion struct {
+	Key          string
+	VersionId    string
+	IsLatest     bool
+	LastModified string
+	ETag         string `xml:",omitempty"`
+}
+
+// One page of ListObjectVersions returned by the stub bucket
+type listedVersionsPage struct {
+	XMLName             xml.Name        `xml:"ListVersionsResult"`
+	IsTruncated         bool            `xml:"IsTruncated"`
+	NextKeyMarker       string          `xml:",omitempty"`
+	NextVersionIdMarker string          `xml:",omitempty"`
+	Versions            []listedVersion `xml:"Version"`
+	DeleteMarkers       []listedVersion `xml:"DeleteMarker"`
+}
+
+// Stub of a bucket returning the versions in pages
+type versionPagesBucket struct {
+	mu      sync.Mutex
+	pages   []listedVersionsPage
+	markers []string
+	fail    bool
+}
+
+func (b *versionPagesBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.fail || r.Method != http.MethodGet || !r.URL.Query().Has("versions") {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// The markers of the previous page select the next page
+	marker := r.URL.Query().Get("key-marker") + "/" + r.URL.Query().Get("version-id-marker")
+	b.markers = append(b.markers, marker)
+	page := 0
+	for i := range b.pages[:len(b.pages)-1] {
+		if b.pages[i].NextKeyMarker+"/"+b.pages[i].NextVersionIdMarker == marker {
+			page = i + 1
+		}
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(b.pages[page])
+}
+
+/*
+Creating a version listed by the stub bucket
+*/
+func newListedVersion(key string, versionId string, eTag string, day int, latest bool) listedVersion {
+	return listedVersion{
+		Key:          key,
+		VersionId:    versionId,
+		IsLatest:     latest,
+		LastModified: time.Date(2026, 3, day, 22, 0, 0, 0, time.UTC).Format(time.RFC3339),
+		ETag:         strconv.Quote(eTag),
+	}
+}
+
+/*
+Splitting the versions into pages of the given size,
+the delete markers are returned with the first page
+*/
+func newVersionPages(size int, deleteMarkers []listedVersion, versions ...listedVersion) []listedVersionsPage {
+	var pages []listedVersionsPage
+	for first := 0; first < len(versions) || first == 0; first += size {
+		page := listedVersionsPage{Versions: versions[first:min(first+size, len(versions))]}
+		if first+size < len(versions) {
+			last := versions[first+size-1]
+			page.IsTruncated = true
+			page.NextKeyMarker = last.Key
+			page.NextVersionIdMarker = last.VersionId
+		}
+		pages = append(pages, page)
+	}
+	pages[0].DeleteMarkers = deleteMarkers
+	return pages
+}
+
+/*
+Setting up a primary and optionally a secondary stub bucket for one test
+*/
+func setupVersionPages(t *testing.T, primary *versionPagesBucket, secondary *versionPagesBucket) *s3.S3 {
+	t.Helper()
+	if global.Logger == nil {
+		global.Logger = logrus.New()
+		global.Logger.SetOutput(io.Discard)
+	}
+	previousConfig := config.BackintConfig
+	previousSession, previousClient := SecondarySession, SecondaryClient
+	config.BackintConfig = config.BackintConfigT{
+		"bucket":           "primary",
+		"secondary_bucket": "secondary",
+	}
+	t.Cleanup(func() {
+		config.BackintConfig = previousConfig
+		SecondarySession, SecondaryClient = previousSession, previousClient
+	})
+
+	newClient := func(bucket *versionPagesBucket) (*session.Session, *s3.S3) {
+		server := httptest.NewServer(bucket)
+		t.Cleanup(server.Close)
+		s3Session := session.Must(session.NewSession(aws.NewConfig().
+			WithEndpoint(server.URL).
+			WithRegion("us-east-1").
+			WithCredentials(credentials.NewStaticCredentials("key-id", "secret", "")).
+			WithS3ForcePathStyle(true).
+			WithMaxRetries(0),
+		))
+		return s3Session, s3.New(s3Session)
+	}
+	SecondarySession, SecondaryClient = nil, nil
+	if secondary != nil {
+		SecondarySession, SecondaryClient = newClient(secondary)
+	}
+	_, client := newClient(primary)
+	return client
+}
+
+func TestResolveRestoreVersion(t *testing.T) {
+	const key = "HDB/TEN/databackup_0_1"
+	// Versions are listed newest first, other keys share the prefix
+	versions := []listedVersion{
+		newListedVersion(key, "v4", "etag-b", 4, true),
+		newListedVersion(key, "v3", "etag-a", 3, false),
+		newListedVersion(key, "v2", "etag-b", 2, false),
+		newListedVersion(key, "v1", "etag-c", 1, false),
+		newListedVersion(key+"0", "v9", "etag-a", 9, true),
+	}
+	deleted := []listedVersion{{Key: key, VersionId: "d5", IsLatest: true,
+		LastModified: time.Date(2026, 3, 5, 22, 0, 0, 0, time.UTC).Format(time.RFC3339)}}
+	latestDeleted := []listedVersion{
+		{Key: key, VersionId: "v4", IsLatest: false, LastModified: versions[0].LastModified, ETag: versions[0].ETag},
+		versions[1], versions[2], versions[3],
+	}
+
+	tests := []struct {
+		name          string
+		eTag          string
+		primary       []listedVersionsPage
+		primaryFails  bool
+		secondary     []listedVersionsPage
+		wantETag      string
+		wantVersion   string
+		wantSecondary bool
+		wantErr       bool
+	}{
+		{
+			name:        "latest version without ETag",
+			primary:     newVersionPages(2, nil, versions...),
+			wantETag:    "etag-b",
+			wantVersion: "v4",
+		},
+		{
+			name:        "older version by ETag on a later page",
+			eTag:        "etag-c",
+			primary:     newVersionPages(2, nil, versions...),
+			wantETag:    "etag-c",
+			wantVersion: "v1",
+		},
+		{
+			name:        "quoted ETag",
+			eTag:        "\"etag-a\"",
+			primary:     newVersionPages(2, nil, versions...),
+			wantETag:    "\"etag-a\"",
+			wantVersion: "v3",
+		},
+		{
+			name:        "newest of versions with the same ETag",
+			eTag:        "etag-b",
+			primary:     newVersionPages(1, nil, versions[3], versions[2], versions[0]),
+			wantETag:    "etag-b",
+			wantVersion: "v4",
+		},
+		{
+			name:     "version of another key with the same prefix",
+			eTag:     "etag-x",
+			primary:  newVersionPages(2, nil, newListedVersion(key+"0", "v9", "etag-x", 9, true)),
+			wantETag: "etag-x",
+		},
+		{
+			name:    "latest version deleted",
+			primary: newVersionPages(2, deleted, latestDeleted...),
+		},
+		{
+			name:        "ETag behind a delete marker",
+			eTag:        "etag-a",
+			primary:     newVersionPages(2, deleted, latestDeleted...),
+			wantETag:    "etag-a",
+			wantVersion: "v3",
+		},
+		{
+			name:          "version only in the secondary bucket",
+			eTag:          "etag-c",
+			primary:       newVersionPages(2, nil, versions[0]),
+			secondary:     newVersionPages(2, nil, newListedVersion(key, "s1", "etag-c", 1, true)),
+			wantETag:      "etag-c",
+			wantVersion:   "s1",
+			wantSecondary: true,
+		},
+		{
+			name:          "primary bucket failing",
+			primaryFails:  true,
+			secondary:     newVersionPages(2, nil, newListedVersion(key, "s1", "etag-c", 1, true)),
+			wantETag:      "etag-c",
+			wantVersion:   "s1",
+			wantSecondary: true,
+		},
+		{
+			name:         "primary bucket failing, not in the secondary bucket",
+			eTag:         "etag-a",
+			primaryFails: true,
+			secondary:    newVersionPages(2, nil),
+			wantETag:     "etag-a",
+			wantErr:      true,
+		},
+		{
+			name:         "primary bucket failing without secondary bucket",
+			eTag:         "etag-a",
+			primaryFails: true,
+			wantETag:     "etag-a",
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &versionPagesBucket{pages: tt.primary, fail: tt.primaryFails}
+			var secondary *versionPagesBucket
+			if tt.secondary != nil {
+				secondary = &versionPagesBucket{pages: tt.secondary}
+			}
+			s3Client := setupVersionPages(t, primary, secondary)
+
+			element := CosObject{Key: key, ETag: tt.eTag}
+			err := ResolveRestoreVersion(s3Client, &element)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveRestoreVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if element.ETag != tt.wantETag || element.VersionId != tt.wantVersion || element.Secondary != tt.wantSecondary {
+				t.Errorf("ResolveRestoreVersion() = ETag %q, version %q, secondary %v, want %q, %q, %v",
+					element.ETag, element.VersionId, element.Secondary, tt.wantETag, tt.wantVersion, tt.wantSecondary)
+			}
+			if !tt.primaryFails && len(primary.markers) != len(tt.primary) {
+				t.Errorf("%d pages listed, want %d", len(primary.markers), len(tt.primary))
+			}
+		})
+	}
+}
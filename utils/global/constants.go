@@ -20,6 +20,7 @@ const (
 	DELETE        = "DELETE"
 	INQUIRE       = "INQUIRE"
 	RESTORE       = "RESTORE"
+	VERIFY        = "VERIFY"
 	INTERNAL_TEST = "TEST"
 
 	// Functions used for calls from dbbackup tool
@@ -34,6 +35,7 @@ var FUNCTIONLIST = []string{
 	DELETE,
 	INQUIRE,
 	RESTORE,
+	VERIFY,
 	INTERNAL_TEST,
 	BUCKET_VERIFY,
 	BUCKET_GET_LIST,
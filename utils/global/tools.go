@@ -16,9 +16,12 @@ package global
 
 import (
 	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 /*
@@ -40,13 +43,6 @@ func ToString(val int64) string {
 	return fmt.Sprintf("%d", val)
 }
 
-/*
-Compress TODO -> Issue #7
-*/
-// func Compress(src []byte) []byte {
-// 	return encoder.EncodeAll(src, make([]byte, 0, len(src)))
-// }
-
 /*
 Check error and set OS Exit code
 */
@@ -61,17 +57,55 @@ func CheckForError(err error, message string, rc int) {
 	}
 }
 
+/*
+Reading the apikey from a given file
+*/
 func ReadApikeyFromFile(authKeypath string) (string, error) {
-	authFile, err := os.Open(authKeypath)
+	content, err := os.ReadFile(authKeypath)
+	if err != nil {
+		return "", err
+	}
+	return ParseApikey(string(content))
+}
+
+/*
+Getting the apikey from the content of a key file or a helper output.
+The content is either the plain apikey in one line
+or a JSON document as downloaded from the IBM Cloud console.
+*/
+func ParseApikey(content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if strings.HasPrefix(content, "{") {
+		var keyFile JsonKeyFile
+		if err := json.Unmarshal([]byte(content), &keyFile); err != nil {
+			return "", err
+		}
+		if keyFile.Apikey == "" {
+			return "", errors.New("JSON document does not contain an apikey")
+		}
+		return keyFile.Apikey, nil
+	}
+
+	if content == "" || strings.Contains(content, "\n") {
+		return "", errors.New("apikey must be specified in exactly one line")
+	}
+	return content, nil
+}
+
+/*
+Reading a key from a file containing exactly one line
+*/
+func ReadKeyFromFile(keypath string) (string, error) {
+	keyFile, err := os.Open(keypath)
 	if err != nil {
 		return "", err
 	}
 
 	defer func() {
-		_ = authFile.Close()
+		_ = keyFile.Close()
 	}()
 
-	scanner := bufio.NewScanner(authFile)
+	scanner := bufio.NewScanner(keyFile)
 	var fileContent []string
 	for scanner.Scan() {
 		fileContent = append(fileContent, scanner.Text())
@@ -82,3 +116,65 @@ func ReadApikeyFromFile(authKeypath string) (string, error) {
 
 	return fileContent[0], nil
 }
+
+/*
+Reading the HMAC access key id and secret access key from a given file
+*/
+func ReadHmacKeysFromFile(keypath string) (string, string, error) {
+	content, err := os.ReadFile(keypath)
+	if err != nil {
+		return "", "", err
+	}
+	return ParseHmacKeys(string(content))
+}
+
+/*
+Getting the HMAC access key id and secret access key
+from the content of a key file.
+The content is either a JSON document containing "cos_hmac_keys"
+as downloaded from the IBM Cloud console, or one "key = value" pair per line.
+The keys access_key_id and secret_access_key may be prefixed with "aws_".
+Empty lines, comments and section headers are ignored.
+*/
+func ParseHmacKeys(content string) (string, string, error) {
+	var accessKeyId string
+	var secretAccessKey string
+
+	if strings.HasPrefix(strings.TrimSpace(content), "{") {
+		var keyFile JsonKeyFile
+		if err := json.Unmarshal([]byte(content), &keyFile); err != nil {
+			return "", "", err
+		}
+		accessKeyId = keyFile.HmacKeys.AccessKeyId
+		secretAccessKey = keyFile.HmacKeys.SecretAccessKey
+	} else {
+		for _, line := range strings.Split(content, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" ||
+				strings.HasPrefix(line, "#") ||
+				strings.HasPrefix(line, ";") ||
+				strings.HasPrefix(line, "[") {
+				continue
+			}
+			key, value, found := strings.Cut(line, "=")
+			if !found {
+				continue
+			}
+			key = strings.TrimPrefix(strings.TrimSpace(key), "aws_")
+			value = strings.Trim(strings.TrimSpace(value), "\"'")
+			switch key {
+			case "access_key_id":
+				accessKeyId = value
+			case "secret_access_key":
+				secretAccessKey = value
+			}
+		}
+	}
+
+	if accessKeyId == "" || secretAccessKey == "" {
+		return "", "", errors.New(
+			"file does not contain access_key_id and secret_access_key",
+		)
+	}
+	return accessKeyId, secretAccessKey, nil
+}
@@ -0,0 +1,190 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package global
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseApikey(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "plain apikey",
+			content: "abcDEF123_-xyz",
+			want:    "abcDEF123_-xyz",
+		},
+		{
+			name:    "plain apikey with trailing newline",
+			content: "  abcDEF123_-xyz\n",
+			want:    "abcDEF123_-xyz",
+		},
+		{
+			name: "JSON key file",
+			content: `{
+				"name": "backint",
+				"description": "",
+				"createdAt": "2026-01-01T00:00+0000",
+				"apikey": "abcDEF123_-xyz"
+			}`,
+			want: "abcDEF123_-xyz",
+		},
+		{
+			name:    "JSON without apikey",
+			content: `{"name": "backint"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON",
+			content: `{"apikey": `,
+			wantErr: true,
+		},
+		{
+			name:    "empty content",
+			content: " \n",
+			wantErr: true,
+		},
+		{
+			name:    "several lines",
+			content: "abcDEF123\nxyz",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseApikey(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseApikey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseApikey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHmacKeys(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantKeyId  string
+		wantSecret string
+		wantErr    bool
+	}{
+		{
+			name: "JSON service credentials",
+			content: `{
+				"apikey": "abc",
+				"cos_hmac_keys": {
+					"access_key_id": "key-id",
+					"secret_access_key": "secret"
+				}
+			}`,
+			wantKeyId:  "key-id",
+			wantSecret: "secret",
+		},
+		{
+			name:       "key value pairs",
+			content:    "access_key_id = key-id\nsecret_access_key = secret\n",
+			wantKeyId:  "key-id",
+			wantSecret: "secret",
+		},
+		{
+			name: "AWS credentials file",
+			content: "# HANA backups\n[default]\n" +
+				"aws_access_key_id=key-id\n" +
+				"; rotated yearly\n" +
+				"aws_secret_access_key=secret\n",
+			wantKeyId:  "key-id",
+			wantSecret: "secret",
+		},
+		{
+			name:       "quoted values",
+			content:    "access_key_id = \"key-id\"\nsecret_access_key = 'secret'",
+			wantKeyId:  "key-id",
+			wantSecret: "secret",
+		},
+		{
+			name:       "secret containing equal signs",
+			content:    "access_key_id=key-id\nsecret_access_key=abc=def==",
+			wantKeyId:  "key-id",
+			wantSecret: "abc=def==",
+		},
+		{
+			name:    "missing secret",
+			content: "access_key_id = key-id",
+			wantErr: true,
+		},
+		{
+			name:    "JSON without HMAC keys",
+			content: `{"apikey": "abc"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON",
+			content: `{"cos_hmac_keys": `,
+			wantErr: true,
+		},
+		{
+			name:    "empty content",
+			content: "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyId, secret, err := ParseHmacKeys(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHmacKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if keyId != tt.wantKeyId || secret != tt.wantSecret {
+				t.Errorf("ParseHmacKeys() = %q, %q, want %q, %q", keyId, secret, tt.wantKeyId, tt.wantSecret)
+			}
+		})
+	}
+}
+
+func TestReadKeysFromFile(t *testing.T) {
+	dir := t.TempDir()
+	apikeyPath := filepath.Join(dir, "apikey.json")
+	hmacPath := filepath.Join(dir, "hmac")
+	if err := os.WriteFile(apikeyPath, []byte(`{"apikey": "abc"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(hmacPath, []byte("access_key_id=id\nsecret_access_key=secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if apikey, err := ReadApikeyFromFile(apikeyPath); err != nil || apikey != "abc" {
+		t.Errorf("ReadApikeyFromFile() = %q, %v, want %q", apikey, err, "abc")
+	}
+	if keyId, secret, err := ReadHmacKeysFromFile(hmacPath); err != nil || keyId != "id" || secret != "secret" {
+		t.Errorf("ReadHmacKeysFromFile() = %q, %q, %v", keyId, secret, err)
+	}
+	if _, err := ReadApikeyFromFile(filepath.Join(dir, "missing")); err == nil {
+		t.Error("ReadApikeyFromFile() succeeded for a missing file")
+	}
+	if _, _, err := ReadHmacKeysFromFile(filepath.Join(dir, "missing")); err == nil {
+		t.Error("ReadHmacKeysFromFile() succeeded for a missing file")
+	}
+}
@@ -29,6 +29,7 @@ type CommandLineArguments struct {
 
 	// Arguments used in case hdbbackint is called by snappy agent
 	AuthKeypath  string
+	AuthMode     string
 	AuthEndpoint string
 	Region       string
 	EndpointUrl  string
@@ -43,3 +44,12 @@ type InputFileContentT struct {
 	Keyword   string
 	Parameter string
 }
+
+// Datatype representing a JSON key file as downloaded from the IBM Cloud console
+type JsonKeyFile struct {
+	Apikey   string `json:"apikey"`
+	HmacKeys struct {
+		AccessKeyId     string `json:"access_key_id"`
+		SecretAccessKey string `json:"secret_access_key"`
+	} `json:"cos_hmac_keys"`
+}
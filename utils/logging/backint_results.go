@@ -86,9 +86,15 @@ func (b *BackintResultMessages) AddBackupMetrics(
 	targetSize int64,
 	duration float64,
 ) {
-	comment := fmt.Sprintf("metrics: source: %d, destination: %d, seconds: %f",
+	ratio := float64(0)
+	if targetSize > 0 {
+		ratio = float64(sourceSize) / float64(targetSize)
+	}
+	comment := fmt.Sprintf(
+		"metrics: source: %d, destination: %d, ratio: %.2f, seconds: %f",
 		sourceSize,
 		targetSize,
+		ratio,
 		duration,
 	)
 	b.addComments([]string{comment})
@@ -106,6 +112,20 @@ func (b *BackintResultMessages) AddErrorMessage(
 	b.AddKeyword(keyword, parms)
 }
 
+/*
+Adding the error message for DELETE.
+Backint 1.04 answers a DELETE with the EBID and the path only,
+the reason is written to the log file.
+*/
+func (b *BackintResultMessages) AddDeleteErrorMessage(
+	ETag string,
+	sourcePath string,
+) {
+	keyword := "ERROR"
+	parms := []string{ETag, sourcePath}
+	b.AddKeyword(keyword, parms)
+}
+
 /*
 Adding the success message for RESTORE
 */
@@ -118,6 +138,27 @@ func (b *BackintResultMessages) AddRestoreSuccessMessage(
 	b.AddKeyword(keyword, parms)
 }
 
+/*
+Adding the success message for VERIFY
+*/
+func (b *BackintResultMessages) AddVerifySuccessMessage(
+	ETag string,
+	sourcePath string,
+	size int64,
+	duration float64,
+	throughput float64,
+) {
+	keyword := "VERIFIED"
+	parms := []string{
+		ETag,
+		sourcePath,
+		global.ToString(size),
+		fmt.Sprintf("%.2f", duration),
+		fmt.Sprintf("%.2f", throughput),
+	}
+	b.AddKeyword(keyword, parms)
+}
+
 /*
 Adding the OBJECT NOT FOUND message
 */
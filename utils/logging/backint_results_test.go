@@ -0,0 +1,39 @@
+// Copyright 2026 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package logging
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ibm-cloud/ibm-sap-hana-backint-cos/utils/version"
+)
+
+func TestAddDeleteErrorMessage(t *testing.T) {
+	var messages BackintResultMessages
+	messages.AddDeleteErrorMessage("etag-1", "/usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_0_1")
+
+	// Backint 1.04 answers a DELETE with the EBID and the path only
+	want := `#ERROR "etag-1" "/usr/sap/HDB/SYS/global/hdb/backint/DB_TEN/databackup_0_1" `
+	if len(messages) != 1 || messages[0] != want {
+		t.Errorf("messages = %q, want %q", messages, want)
+	}
+	if version.BACKINT_VERSION != "backint 1.04" {
+		t.Errorf("Backint version %q, check the fields of #ERROR for DELETE", version.BACKINT_VERSION)
+	}
+	if fields := strings.Count(messages[0], `"`) / 2; fields != 2 {
+		t.Errorf("#ERROR with %d fields, want 2", fields)
+	}
+}
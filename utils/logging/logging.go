@@ -154,8 +154,8 @@ func writeBackintConfiguration(logger *logrus.Logger) {
 			// Don't print the timeout to log file
 			continue
 		}
-		if key == "apikey" {
-			// Don't print the apikey to log file
+		if config.SensitiveKeys[key] {
+			// Don't print the apikeys, HMAC secrets or the encryption key to log file
 			logger.Info(key + " = ****")
 			continue
 		}